@@ -0,0 +1,636 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"go-DBmodeler/internal/config"
+	"go-DBmodeler/internal/db/connector"
+	"go-DBmodeler/internal/db/dialect"
+	"go-DBmodeler/internal/db/metadata"
+	"go-DBmodeler/internal/generator"
+	"go-DBmodeler/internal/plugin"
+	"go-DBmodeler/pkg/logger"
+	"golang.org/x/term"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// masterPasswordEnv 是提供保险库主密码的环境变量，适用于非交互环境
+const masterPasswordEnv = "GODBMODELER_MASTER_PASSWORD"
+
+// paramFlags 收集可重复的 -param key=value 参数
+type paramFlags map[string]string
+
+// String 实现flag.Value接口
+func (f paramFlags) String() string {
+	parts := make([]string, 0, len(f))
+	for key, value := range f {
+		parts = append(parts, key+"="+value)
+	}
+	return strings.Join(parts, ",")
+}
+
+// Set 实现flag.Value接口
+func (f paramFlags) Set(value string) error {
+	key, val, ok := strings.Cut(value, "=")
+	if !ok || key == "" {
+		return fmt.Errorf("参数格式应为 key=value: %s", value)
+	}
+	f[key] = val
+	return nil
+}
+
+func main() {
+	params := paramFlags{}
+	list := flag.Bool("list", false, "列出可用的连接、脚本、模板、查询和插件")
+	connName := flag.String("conn", "", "连接名称（在图形界面中配置）")
+	database := flag.String("db", "", "数据库名称，未指定时使用连接的默认数据库")
+	tables := flag.String("tables", "", "要生成的表，多个表用逗号分隔，默认为全部表")
+	generatorName := flag.String("generator", "builtin", "生成器：builtin、script:<脚本名称> 或 plugin:<插件名称>")
+	templateName := flag.String("template", "default", "内置生成器使用的模板名称")
+	routines := flag.String("routines", "", "改为生成存储过程和函数的调用函数，多个名称用逗号分隔，* 表示全部")
+	routineTemplate := flag.String("routine-template", "routine", "生成存储过程和函数使用的模板名称")
+	queryName := flag.String("query", "", "改为为已保存的查询生成结果接口和参数类型")
+	queryTemplate := flag.String("query-template", "query", "生成查询使用的模板名称")
+	outDir := flag.String("out", ".", "输出目录")
+	timeout := flag.Duration("timeout", plugin.DefaultTimeout, "插件运行超时时间")
+	wasmMemory := flag.Uint("wasm-memory", plugin.DefaultWasmMemoryPages/16, "WASM插件可使用的最大内存（MiB）")
+	connectTimeout := flag.Duration("connect-timeout", 0, "连接超时时间，默认使用连接的设置")
+	queryTimeout := flag.Duration("query-timeout", 0, "每次查询的超时时间，默认使用连接的设置")
+	yes := flag.Bool("yes", false, "对标记为生产环境的连接不再确认")
+	wasmMaxCalls := flag.Uint64("wasm-max-calls", plugin.DefaultWasmMaxCalls, "WASM插件每次调用允许的最大函数调用次数，0表示不限制（函数内的循环只受 -timeout 限制）")
+	flag.Var(params, "param", "传递给脚本或插件的参数 key=value，可重复")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "用法: %s -conn <连接> -db <数据库> [-tables a,b] [-generator builtin|script:<名称>|plugin:<名称>] [-routines a,b | -query 名称] [-out 目录]\n\n", filepath.Base(os.Args[0]))
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	log := logger.New()
+
+	storage, err := config.NewStorage()
+	if err != nil {
+		exitf("创建配置存储失败: %v", err)
+	}
+	for _, issue := range storage.ValidationIssues() {
+		fmt.Fprintf(os.Stderr, "警告: 配置文件检查: %s\n", issue)
+	}
+
+	pluginManager := plugin.NewManager(log, storage.GetPluginDir())
+	pluginManager.SetTimeout(*timeout)
+	pluginManager.SetWasmLimits(plugin.WasmLimits{MemoryPages: uint32(*wasmMemory) * 16, MaxCalls: *wasmMaxCalls})
+
+	if *list {
+		printAvailable(storage, pluginManager)
+		return
+	}
+
+	if *connName == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	// 连接数据库
+	connConfig, err := findConnection(storage, *connName)
+	if err != nil {
+		exitf("%v", err)
+	}
+
+	// 生产环境连接需要确认
+	if connConfig.IsProduction() && !*yes {
+		if err := confirmProduction(connConfig.Name); err != nil {
+			exitf("%v", err)
+		}
+	}
+
+	// 命令行没有指定的选项使用连接的生成器默认设置
+	if defaults := connConfig.GeneratorDefaults; defaults != nil {
+		explicit := make(map[string]bool)
+		flag.Visit(func(f *flag.Flag) { explicit[f.Name] = true })
+		if !explicit["db"] && defaults.Database != "" {
+			*database = defaults.Database
+		}
+		if !explicit["generator"] && defaults.Generator != "" {
+			*generatorName = defaults.Generator
+		}
+		if !explicit["template"] && defaults.Template != "" {
+			*templateName = defaults.Template
+		}
+		if !explicit["out"] && defaults.OutputDir != "" {
+			*outDir = defaults.OutputDir
+		}
+		for key, value := range defaults.Params {
+			if _, ok := params[key]; !ok {
+				params[key] = value
+			}
+		}
+	}
+	// 查询记录了编写时使用的数据库，没有指定数据库时使用它
+	var savedQuery config.SavedQuery
+	if *queryName != "" {
+		var ok bool
+		if savedQuery, ok = storage.FindQuery(*queryName); !ok {
+			exitf("查询 '%s' 不存在", *queryName)
+		}
+		if *database == "" {
+			*database = savedQuery.Database
+		}
+	}
+	if *database == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	// 按 Ctrl+C 时取消正在进行的连接和查询
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	dbConfig := connectorConfig(connConfig)
+	if *connectTimeout > 0 {
+		dbConfig.ConnectTimeout = *connectTimeout
+	}
+	if *queryTimeout > 0 {
+		dbConfig.QueryTimeout = *queryTimeout
+	}
+	d, err := dialect.Lookup(connConfig.Type)
+	if err != nil {
+		exitf("%v", err)
+	}
+	conn := d.NewConnector(dbConfig)
+	if _, err := conn.Connect(ctx); err != nil {
+		exitf("连接数据库失败: %v", err)
+	}
+	processor := metadata.NewProcessor(conn)
+	defer processor.Close()
+
+	// 指定了 -query 时只生成查询，可以使用脚本或插件处理
+	if *queryName != "" {
+		var files []plugin.File
+		var err error
+		switch {
+		case strings.HasPrefix(*generatorName, "plugin:"):
+			files, err = generateQueryWithPlugin(ctx, pluginManager, strings.TrimPrefix(*generatorName, "plugin:"),
+				processor, d, *database, savedQuery, params)
+		case strings.HasPrefix(*generatorName, "script:"):
+			files, err = generateQuery(ctx, storage, log, strings.TrimPrefix(*generatorName, "script:"), *queryTemplate,
+				processor, d, *database, savedQuery, params)
+		case *generatorName == "builtin":
+			files, err = generateQuery(ctx, storage, log, "", *queryTemplate, processor, d, *database, savedQuery, params)
+		default:
+			err = fmt.Errorf("未知的生成器: %s", *generatorName)
+		}
+		if err != nil {
+			exitf("%v", err)
+		}
+		writeFiles(*outDir, files)
+		return
+	}
+
+	// 指定了 -routines 时只生成存储过程和函数，可以使用插件生成
+	if *routines != "" {
+		var files []plugin.File
+		var err error
+		switch {
+		case strings.HasPrefix(*generatorName, "plugin:"):
+			files, err = generateRoutinesWithPlugin(ctx, pluginManager, strings.TrimPrefix(*generatorName, "plugin:"),
+				processor, d, *database, *routines, params)
+		case *generatorName == "builtin":
+			files, err = generateRoutines(ctx, storage, log, *routineTemplate, processor, d, *database, *routines)
+		default:
+			err = fmt.Errorf("存储过程和函数只能使用 builtin 或 plugin:<插件名称> 生成器")
+		}
+		if err != nil {
+			exitf("%v", err)
+		}
+		writeFiles(*outDir, files)
+		return
+	}
+
+	tableInfos, err := processor.GetTables(ctx, *database)
+	if err != nil {
+		exitf("获取表列表失败: %v", err)
+	}
+	allTables := connector.TableNames(tableInfos)
+	selected := allTables
+	if *tables != "" {
+		selected = strings.Split(*tables, ",")
+	}
+
+	var files []plugin.File
+	switch {
+	case strings.HasPrefix(*generatorName, "plugin:"):
+		files, err = generateWithPlugin(ctx, pluginManager, strings.TrimPrefix(*generatorName, "plugin:"),
+			processor, d, *database, allTables, selected, params)
+	case *generatorName == "builtin":
+		files, err = generateBuiltin(ctx, storage, log, "", *templateName,
+			processor, d, connConfig.Name, *database, selected, params)
+	case strings.HasPrefix(*generatorName, "script:"):
+		files, err = generateBuiltin(ctx, storage, log, strings.TrimPrefix(*generatorName, "script:"), *templateName,
+			processor, d, connConfig.Name, *database, selected, params)
+	default:
+		err = fmt.Errorf("未知的生成器: %s", *generatorName)
+	}
+	if err != nil {
+		exitf("%v", err)
+	}
+
+	writeFiles(*outDir, files)
+}
+
+// writeFiles 将生成的文件写入输出目录并输出文件路径
+func writeFiles(outDir string, files []plugin.File) {
+	paths, err := plugin.WriteFiles(outDir, files)
+	if err != nil {
+		exitf("%v", err)
+	}
+	for _, path := range paths {
+		fmt.Println(path)
+	}
+}
+
+// findConnection 查找连接配置，解密密码并解析间接引用，保险库未解锁时先解锁
+func findConnection(storage *config.Storage, name string) (config.ConnectionConfig, error) {
+	for _, conn := range storage.GetConnections() {
+		if conn.Name != name {
+			continue
+		}
+		decrypted, err := storage.DecryptConnectionPassword(conn)
+		if errors.Is(err, config.ErrVaultLocked) {
+			if err := unlockVault(storage); err != nil {
+				return conn, err
+			}
+			decrypted, err = storage.DecryptConnectionPassword(conn)
+		}
+		if err != nil {
+			return conn, err
+		}
+		return decrypted.Resolve()
+	}
+	return config.ConnectionConfig{}, fmt.Errorf("连接 '%s' 不存在", name)
+}
+
+// connectorConfig 返回创建数据库连接器使用的配置
+func connectorConfig(conn config.ConnectionConfig) *connector.ConnectionConfig {
+	connConfig := &connector.ConnectionConfig{
+		Type:     conn.Type,
+		Host:     conn.Host,
+		Port:     conn.Port,
+		Username: conn.Username,
+		Password: conn.Password,
+		Database: conn.Database,
+
+		ConnectTimeout: time.Duration(conn.ConnectTimeout) * time.Second,
+		QueryTimeout:   time.Duration(conn.QueryTimeout) * time.Second,
+	}
+	if tunnel := conn.SSH; tunnel != nil {
+		connConfig.SSH = &connector.SSHConfig{
+			Host:                  tunnel.Host,
+			Port:                  tunnel.Port,
+			User:                  tunnel.User,
+			Auth:                  tunnel.Auth,
+			KeyFile:               tunnel.KeyFile,
+			Password:              tunnel.Password,
+			KnownHostsFile:        tunnel.KnownHostsFile,
+			InsecureIgnoreHostKey: tunnel.InsecureIgnoreHostKey,
+		}
+	}
+	if options := conn.TLS; options != nil {
+		connConfig.TLS = &connector.TLSConfig{
+			Mode:       options.Mode,
+			CACert:     options.CACert,
+			ClientCert: options.ClientCert,
+			ClientKey:  options.ClientKey,
+			ServerName: options.ServerName,
+		}
+	}
+	return connConfig
+}
+
+// unlockVault 使用环境变量或终端输入的主密码解锁保险库
+func unlockVault(storage *config.Storage) error {
+	password, ok := os.LookupEnv(masterPasswordEnv)
+	if !ok {
+		if !term.IsTerminal(int(os.Stdin.Fd())) {
+			return fmt.Errorf("保险库已锁定，请通过环境变量 %s 提供主密码", masterPasswordEnv)
+		}
+		fmt.Fprint(os.Stderr, "主密码: ")
+		input, err := term.ReadPassword(int(os.Stdin.Fd()))
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return fmt.Errorf("读取主密码失败: %v", err)
+		}
+		password = string(input)
+	}
+	return storage.UnlockVault(password)
+}
+
+// confirmProduction 在终端中确认对生产环境连接的操作，非交互环境需要使用 -yes
+func confirmProduction(name string) error {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return fmt.Errorf("连接 '%s' 标记为生产环境，非交互环境中请使用 -yes 确认", name)
+	}
+	fmt.Fprintf(os.Stderr, "连接 '%s' 标记为生产环境，确定继续吗？[y/N] ", name)
+	var answer string
+	fmt.Scanln(&answer)
+	if answer = strings.ToLower(strings.TrimSpace(answer)); answer != "y" && answer != "yes" {
+		return errors.New("已取消")
+	}
+	return nil
+}
+
+// generateBuiltin 使用模板和可选的脚本为每个表生成一个 .ts 文件
+// 在图形界面中为JSON列保存的结构会用作字段类型
+func generateBuiltin(ctx context.Context, storage *config.Storage, log *logger.Logger, scriptName, templateName string,
+	processor *metadata.Processor, d *dialect.Dialect, connName, database string, tables []string, params map[string]string) ([]plugin.File, error) {
+	templateStr, err := storage.GetTemplate(templateName)
+	if err != nil {
+		return nil, err
+	}
+	gen, err := generator.NewGenerator(d.NewTypeMapper(), templateStr, log)
+	if err != nil {
+		return nil, fmt.Errorf("创建生成器失败: %v", err)
+	}
+	if scriptName != "" {
+		script, err := storage.GetScript(scriptName)
+		if err != nil {
+			return nil, err
+		}
+		gen.SetScript(script)
+		gen.SetScriptParams(params)
+	}
+
+	files := make([]plugin.File, 0, len(tables))
+	for _, table := range tables {
+		meta, err := processor.GetTableMetadata(ctx, database, table)
+		if err != nil {
+			return nil, fmt.Errorf("获取表 %s 的元数据失败: %v", table, err)
+		}
+		gen.SetJSONShapes(storage.GetJSONShapes(connName, database, table))
+		code, err := gen.Generate(meta)
+		for _, entry := range gen.ConsoleLogs() {
+			fmt.Fprintf(os.Stderr, "[%s] %s\n", strings.ToUpper(entry.Level), entry.Message)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("生成表 %s 失败: %v", table, err)
+		}
+		files = append(files, plugin.File{Name: table + ".ts", Content: code})
+	}
+	return files, nil
+}
+
+// generateQuery 分析已保存的查询并使用查询模板和可选的脚本生成一个 .ts 文件
+func generateQuery(ctx context.Context, storage *config.Storage, log *logger.Logger, scriptName, templateName string,
+	processor *metadata.Processor, d *dialect.Dialect, database string, query config.SavedQuery, params map[string]string) ([]plugin.File, error) {
+	if !processor.SupportsQueries() {
+		return nil, fmt.Errorf("%s 不支持分析查询", d.Name)
+	}
+	templateStr, err := storage.GetTemplate(templateName)
+	if err != nil {
+		return nil, err
+	}
+	gen, err := generator.NewGenerator(d.NewTypeMapper(), templateStr, log)
+	if err != nil {
+		return nil, fmt.Errorf("创建生成器失败: %v", err)
+	}
+	if scriptName != "" {
+		script, err := storage.GetScript(scriptName)
+		if err != nil {
+			return nil, err
+		}
+		gen.SetScript(script)
+		gen.SetScriptParams(params)
+	}
+
+	meta, err := processor.DescribeQuery(ctx, database, query.Name, query.SQL)
+	if err != nil {
+		return nil, fmt.Errorf("分析查询 %s 失败: %v", query.Name, err)
+	}
+	code, err := gen.Generate(meta)
+	for _, entry := range gen.ConsoleLogs() {
+		fmt.Fprintf(os.Stderr, "[%s] %s\n", strings.ToUpper(entry.Level), entry.Message)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("生成查询 %s 失败: %v", query.Name, err)
+	}
+	return []plugin.File{{Name: query.Name + ".ts", Content: code}}, nil
+}
+
+// generateRoutines 使用例程模板为每个存储过程或函数生成一个 .ts 文件
+// names 中的名称可以是例程名称或签名，* 表示全部；重载的例程按出现顺序生成到 名称_2.ts 等文件中
+func generateRoutines(ctx context.Context, storage *config.Storage, log *logger.Logger, templateName string,
+	processor *metadata.Processor, d *dialect.Dialect, database, names string) ([]plugin.File, error) {
+	if !processor.SupportsRoutines() {
+		return nil, fmt.Errorf("%s 不支持读取存储过程和函数", d.Name)
+	}
+	templateStr, err := storage.GetTemplate(templateName)
+	if err != nil {
+		return nil, err
+	}
+	gen, err := generator.NewGenerator(d.NewTypeMapper(), templateStr, log)
+	if err != nil {
+		return nil, fmt.Errorf("创建生成器失败: %v", err)
+	}
+
+	routines, err := selectRoutines(ctx, processor, database, names)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []plugin.File
+	counts := make(map[string]int)
+	for i := range routines {
+		routine := &routines[i]
+		code, err := gen.GenerateRoutine(routine)
+		if err != nil {
+			return nil, fmt.Errorf("生成 %s 失败: %v", routine.Signature, err)
+		}
+		fileName := routine.Name
+		if counts[routine.Name]++; counts[routine.Name] > 1 {
+			fileName += "_" + strconv.Itoa(counts[routine.Name])
+		}
+		files = append(files, plugin.File{Name: fileName + ".ts", Content: code})
+	}
+	return files, nil
+}
+
+// selectRoutines 返回名称或签名在 names 中的存储过程和函数，* 表示全部
+func selectRoutines(ctx context.Context, processor *metadata.Processor, database, names string) ([]connector.Routine, error) {
+	routines, err := processor.GetRoutines(ctx, database)
+	if err != nil {
+		return nil, fmt.Errorf("获取存储过程和函数失败: %v", err)
+	}
+	wanted := make(map[string]bool)
+	for _, name := range strings.Split(names, ",") {
+		wanted[strings.TrimSpace(name)] = true
+	}
+
+	var selected []connector.Routine
+	for _, routine := range routines {
+		if wanted["*"] || wanted[routine.Name] || wanted[routine.Signature] {
+			selected = append(selected, routine)
+		}
+	}
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("没有找到匹配的存储过程或函数: %s", names)
+	}
+	return selected, nil
+}
+
+// generateWithPlugin 使用插件生成代码，请求中包含数据库中所有表的结构
+func generateWithPlugin(ctx context.Context, manager *plugin.Manager, name string, processor *metadata.Processor,
+	d *dialect.Dialect, database string, allTables, selected []string, params map[string]string) ([]plugin.File, error) {
+	p, err := manager.Find(name)
+	if err != nil {
+		return nil, err
+	}
+
+	mapper := d.NewTypeMapper()
+	request := &plugin.Request{
+		Kind:         plugin.InputTable,
+		DatabaseType: d.Name,
+		Database:     database,
+		Tables:       make([]plugin.Table, 0, len(allTables)),
+		Generate:     selected,
+		Params:       params,
+	}
+	for _, table := range allTables {
+		meta, err := processor.GetTableMetadata(ctx, database, table)
+		if err != nil {
+			return nil, fmt.Errorf("获取表 %s 的元数据失败: %v", table, err)
+		}
+		request.Tables = append(request.Tables, plugin.NewTable(meta, mapper))
+	}
+
+	return runPlugin(manager, p, request)
+}
+
+// generateQueryWithPlugin 分析已保存的查询并使用插件生成代码，请求中只有查询的结果结构和参数
+func generateQueryWithPlugin(ctx context.Context, manager *plugin.Manager, name string, processor *metadata.Processor,
+	d *dialect.Dialect, database string, query config.SavedQuery, params map[string]string) ([]plugin.File, error) {
+	if !processor.SupportsQueries() {
+		return nil, fmt.Errorf("%s 不支持分析查询", d.Name)
+	}
+	p, err := manager.Find(name)
+	if err != nil {
+		return nil, err
+	}
+
+	meta, err := processor.DescribeQuery(ctx, database, query.Name, query.SQL)
+	if err != nil {
+		return nil, fmt.Errorf("分析查询 %s 失败: %v", query.Name, err)
+	}
+	return runPlugin(manager, p, &plugin.Request{
+		Kind:         plugin.InputQuery,
+		DatabaseType: d.Name,
+		Database:     database,
+		Tables:       []plugin.Table{plugin.NewTable(meta, d.NewTypeMapper())},
+		Generate:     []string{query.Name},
+		Params:       params,
+	})
+}
+
+// generateRoutinesWithPlugin 使用插件为选中的存储过程和函数生成代码
+func generateRoutinesWithPlugin(ctx context.Context, manager *plugin.Manager, name string, processor *metadata.Processor,
+	d *dialect.Dialect, database, names string, params map[string]string) ([]plugin.File, error) {
+	if !processor.SupportsRoutines() {
+		return nil, fmt.Errorf("%s 不支持读取存储过程和函数", d.Name)
+	}
+	p, err := manager.Find(name)
+	if err != nil {
+		return nil, err
+	}
+
+	routines, err := selectRoutines(ctx, processor, database, names)
+	if err != nil {
+		return nil, err
+	}
+	mapper := d.NewTypeMapper()
+	request := &plugin.Request{
+		Kind:         plugin.InputRoutine,
+		DatabaseType: d.Name,
+		Database:     database,
+		Routines:     make([]plugin.Routine, 0, len(routines)),
+		Generate:     make([]string, 0, len(routines)),
+		Params:       params,
+	}
+	for i := range routines {
+		request.Routines = append(request.Routines, plugin.NewRoutine(&routines[i], mapper))
+		request.Generate = append(request.Generate, routines[i].Signature)
+	}
+	return runPlugin(manager, p, request)
+}
+
+// runPlugin 运行插件，输出插件的标准错误和耗时
+func runPlugin(manager *plugin.Manager, p *plugin.Plugin, request *plugin.Request) ([]plugin.File, error) {
+	started := time.Now()
+	result, err := manager.Generate(p, request)
+	if err != nil {
+		return nil, err
+	}
+	if result.Stderr != "" {
+		fmt.Fprint(os.Stderr, result.Stderr)
+	}
+	fmt.Fprintf(os.Stderr, "插件 %s 生成了 %d 个文件，耗时 %s\n", p.Name, len(result.Files), time.Since(started).Round(time.Millisecond))
+	return result.Files, nil
+}
+
+// printAvailable 列出可用的连接、脚本、模板、查询和插件
+func printAvailable(storage *config.Storage, manager *plugin.Manager) {
+	fmt.Println("连接:")
+	for _, conn := range storage.GetConnections() {
+		fmt.Printf("  %s (%s)", conn.Name, conn.Type)
+		if conn.Group != "" {
+			fmt.Printf(" 分组: %s", conn.Group)
+		}
+		if conn.Environment != "" {
+			fmt.Printf(" [%s]", conn.Environment)
+		}
+		if len(conn.Tags) > 0 {
+			fmt.Printf(" #%s", strings.Join(conn.Tags, " #"))
+		}
+		fmt.Println()
+	}
+
+	for _, kind := range []config.AssetKind{config.AssetTemplate, config.AssetScript} {
+		metas, _ := storage.Library().List(kind)
+		if kind == config.AssetTemplate {
+			fmt.Println("模板:")
+		} else {
+			fmt.Println("脚本（-generator script:<名称>）:")
+		}
+		for _, meta := range metas {
+			fmt.Printf("  %s\t%s\n", meta.Name, meta.Description)
+		}
+	}
+
+	fmt.Println("查询（-query <名称>）:")
+	for _, query := range storage.GetQueries() {
+		fmt.Printf("  %s", query.Name)
+		if query.Database != "" {
+			fmt.Printf("\t数据库: %s", query.Database)
+		}
+		fmt.Println()
+	}
+
+	fmt.Printf("数据库类型: %s\n", strings.Join(dialect.Names(), ", "))
+
+	fmt.Printf("插件（-generator plugin:<名称>，目录 %s）:\n", manager.Dir())
+	plugins, err := manager.Discover()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "  %v\n", err)
+	}
+	for _, p := range plugins {
+		fmt.Printf("  %s\t%s %s\t%s\n", p.Name, p.DisplayName, p.Version, p.Description)
+	}
+}
+
+// exitf 输出错误信息并退出
+func exitf(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+	os.Exit(1)
+}
@@ -4,13 +4,16 @@ import (
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/app"
 	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
 	"go-DBmodeler/internal/config"
 	"go-DBmodeler/internal/generator"
 	"go-DBmodeler/internal/ui/pages"
 	apptheme "go-DBmodeler/internal/ui/theme"
 	"go-DBmodeler/pkg/logger"
 	"path/filepath"
+	"strings"
 )
 
 // Application 表示GoDBModeler应用
@@ -52,8 +55,13 @@ func (a *Application) Run() {
 	a.mainWindow = a.fyneApp.NewWindow("GoDBModeler - 数据库建模工具")
 	a.mainWindow.Resize(fyne.NewSize(1024, 768))
 
-	// 创建主界面
-	a.setupUI()
+	// 解锁凭据保险库后创建主界面
+	if a.storage != nil {
+		a.mainWindow.SetContent(pages.NewVaultPage(a.log, a.storage, a.setupUI))
+		a.showValidationIssues()
+	} else {
+		a.setupUI()
+	}
 
 	// 显示窗口并运行应用
 	a.mainWindow.ShowAndRun()
@@ -70,12 +78,16 @@ func (a *Application) initConfig() {
 
 	a.storage = storage
 
+	// 报告配置文件中的问题
+	for _, issue := range storage.ValidationIssues() {
+		a.log.Warnf("配置文件检查: %s", issue)
+	}
+
 	// 加载连接配置
 	a.connections = storage.GetConnections()
 
 	// 创建模板管理器
-	templateDir := filepath.Join(".", "templates", "imported")
-	a.templateManager = generator.NewTemplateManager(a.log, templateDir)
+	a.templateManager = generator.NewTemplateManager(a.log, storage.Library())
 
 	// 初始化默认模板
 	if err := a.templateManager.InitializeDefaultTemplates(); err != nil {
@@ -83,6 +95,20 @@ func (a *Application) initConfig() {
 	}
 }
 
+// showValidationIssues 提示配置文件中的未知字段和无效条目
+func (a *Application) showValidationIssues() {
+	issues := a.storage.ValidationIssues()
+	if len(issues) == 0 {
+		return
+	}
+
+	message := widget.NewLabel("配置文件 " + filepath.Join(a.storage.GetConfigDir(), "config.json") + " 中存在以下问题：\n\n- " + strings.Join(issues, "\n- "))
+	message.Wrapping = fyne.TextWrapWord
+	issuesDialog := dialog.NewCustom("配置文件检查", "确定", container.NewVScroll(message), a.mainWindow)
+	issuesDialog.Resize(fyne.NewSize(500, 300))
+	issuesDialog.Show()
+}
+
 // setTheme 设置主题
 func (a *Application) setTheme() {
 	// 始终使用暗色主题
@@ -95,7 +121,7 @@ func (a *Application) setupUI() {
 	connectionPage, connectionContainer := pages.NewConnectionPage(a.log, a.storage)
 
 	// 创建生成器页面
-	generatorPage := pages.NewGeneratorPage(a.log, toConnectionConfigArray(a.connections), a.templateManager, a.storage)
+	generatorPage := pages.NewGeneratorPage(a.log, a.toConnectionConfigArray(a.connections), a.templateManager, a.storage)
 
 	// 创建脚本管理页面
 	scriptManagerPage := pages.NewScriptManagerPage(a.log, a.storage)
@@ -105,7 +131,7 @@ func (a *Application) setupUI() {
 		// 重新加载连接配置
 		a.connections = a.storage.GetConnections()
 		// 重新创建生成器页面以更新连接列表
-		generatorPage = pages.NewGeneratorPage(a.log, toConnectionConfigArray(a.connections), a.templateManager, a.storage)
+		generatorPage = pages.NewGeneratorPage(a.log, a.toConnectionConfigArray(a.connections), a.templateManager, a.storage)
 
 		// 更新标签页内容
 		if tabs := a.mainWindow.Content().(*container.AppTabs); tabs != nil {
@@ -126,7 +152,7 @@ func (a *Application) setupUI() {
 		// 当切换到TS模型生成页面时，更新模板列表
 		if tab.Text == "TS模型生成" {
 			// 重新创建生成器页面以更新模板列表
-			tab.Content = pages.NewGeneratorPage(a.log, toConnectionConfigArray(a.connections), a.templateManager, a.storage)
+			tab.Content = pages.NewGeneratorPage(a.log, a.toConnectionConfigArray(a.connections), a.templateManager, a.storage)
 			tabs.Refresh()
 		}
 	}
@@ -138,25 +164,22 @@ func (a *Application) setupUI() {
 }
 
 // toConnectionConfigArray 将config.ConnectionConfig数组转换为pages.ConnectionConfig数组
-func toConnectionConfigArray(configs []config.ConnectionConfig) []*pages.ConnectionConfig {
+func (a *Application) toConnectionConfigArray(configs []config.ConnectionConfig) []*pages.ConnectionConfig {
 	result := make([]*pages.ConnectionConfig, 0, len(configs))
 
 	for _, cfg := range configs {
 		// 解密密码
-		decryptedConfig, err := config.DecryptConnectionPassword(cfg)
+		decryptedConfig, err := a.storage.DecryptConnectionPassword(cfg)
 		if err != nil {
 			continue
 		}
 
-		result = append(result, &pages.ConnectionConfig{
-			Name:     decryptedConfig.Name,
-			Type:     decryptedConfig.Type,
-			Host:     decryptedConfig.Host,
-			Port:     decryptedConfig.Port,
-			Username: decryptedConfig.Username,
-			Password: decryptedConfig.Password,
-			Database: decryptedConfig.Database,
-		})
+		// 解析间接引用，解析失败时保留连接，在连接时提示错误
+		conn := pages.NewConnectionConfig(decryptedConfig)
+		if conn.ResolveError != nil {
+			a.log.Warnf("%v", conn.ResolveError)
+		}
+		result = append(result, conn)
 	}
 
 	return result
@@ -8,53 +8,71 @@ import (
 	"errors"
 	"golang.org/x/crypto/scrypt"
 	"io"
+	"strings"
 )
 
-// 加密密钥和盐值（在实际应用中应该从环境变量或配置文件中获取）
+// 旧版本编译在程序中的加密密钥和盐值
+// 仅用于将旧配置中的密码迁移到保险库，不再用于加密新密码
 var (
-	encryptionKey = []byte("godbmodeler-encryption-key-12345")
-	salt          = []byte("godbmodeler-salt-12345")
+	legacyEncryptionKey = []byte("godbmodeler-encryption-key-12345")
+	legacySalt          = []byte("godbmodeler-salt-12345")
 )
 
-// EncryptPassword 加密密码
-func EncryptPassword(password string) (string, error) {
-	if password == "" {
-		return "", nil
+// sealAESGCM 使用AES-GCM加密数据，返回 nonce + 密文
+func sealAESGCM(key, plaintext, additionalData []byte) ([]byte, error) {
+	// 创建加密块
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
 	}
 
-	// 使用scrypt生成密钥
-	key, err := scrypt.Key(encryptionKey, salt, 32768, 8, 1, 32)
+	// 创建GCM模式
+	gcm, err := cipher.NewGCM(block)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
+	// 创建随机数
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	// 加密
+	return gcm.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+// openAESGCM 解密 sealAESGCM 生成的数据
+func openAESGCM(key, ciphertext, additionalData []byte) ([]byte, error) {
 	// 创建加密块
 	block, err := aes.NewCipher(key)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	// 创建GCM模式
 	gcm, err := cipher.NewGCM(block)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	// 创建随机数
-	nonce := make([]byte, gcm.NonceSize())
-	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
-		return "", err
+	// 检查长度
+	if len(ciphertext) < gcm.NonceSize() {
+		return nil, errors.New("密文太短")
 	}
 
-	// 加密
-	ciphertext := gcm.Seal(nonce, nonce, []byte(password), nil)
+	// 提取nonce并解密
+	nonce, ciphertext := ciphertext[:gcm.NonceSize()], ciphertext[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, ciphertext, additionalData)
+}
 
-	// 编码为Base64
-	return base64.StdEncoding.EncodeToString(ciphertext), nil
+// isLegacyEncrypted 判断密码是否由旧版本的固定密钥加密
+func isLegacyEncrypted(encrypted string) bool {
+	return encrypted != "" && !strings.HasPrefix(encrypted, vaultCipherPrefix) && !IsReference(encrypted)
 }
 
-// DecryptPassword 解密密码
-func DecryptPassword(encrypted string) (string, error) {
+// decryptLegacyPassword 解密旧版本使用固定密钥加密的密码
+func decryptLegacyPassword(encrypted string) (string, error) {
 	if encrypted == "" {
 		return "", nil
 	}
@@ -66,33 +84,12 @@ func DecryptPassword(encrypted string) (string, error) {
 	}
 
 	// 使用scrypt生成密钥
-	key, err := scrypt.Key(encryptionKey, salt, 32768, 8, 1, 32)
-	if err != nil {
-		return "", err
-	}
-
-	// 创建加密块
-	block, err := aes.NewCipher(key)
-	if err != nil {
-		return "", err
-	}
-
-	// 创建GCM模式
-	gcm, err := cipher.NewGCM(block)
+	key, err := scrypt.Key(legacyEncryptionKey, legacySalt, 32768, 8, 1, 32)
 	if err != nil {
 		return "", err
 	}
 
-	// 检查长度
-	if len(ciphertext) < gcm.NonceSize() {
-		return "", errors.New("密文太短")
-	}
-
-	// 提取nonce
-	nonce, ciphertext := ciphertext[:gcm.NonceSize()], ciphertext[gcm.NonceSize():]
-
-	// 解密
-	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	plaintext, err := openAESGCM(key, ciphertext, nil)
 	if err != nil {
 		return "", err
 	}
@@ -100,14 +97,15 @@ func DecryptPassword(encrypted string) (string, error) {
 	return string(plaintext), nil
 }
 
-// EncryptConnectionPassword 加密连接配置中的密码
-func EncryptConnectionPassword(config ConnectionConfig) (ConnectionConfig, error) {
-	if config.Password == "" {
-		return config, nil
+// EncryptConnectionPassword 使用保险库加密连接配置中的密码
+// 间接引用不是密码本身，按原样保存
+func (s *Storage) EncryptConnectionPassword(config ConnectionConfig) (ConnectionConfig, error) {
+	if config.Password == "" || IsReference(config.Password) {
+		return config, s.encryptSSHPassword(&config)
 	}
 
 	// 加密密码
-	encrypted, err := EncryptPassword(config.Password)
+	encrypted, err := s.vault.Encrypt(config.Password)
 	if err != nil {
 		return config, err
 	}
@@ -115,17 +113,39 @@ func EncryptConnectionPassword(config ConnectionConfig) (ConnectionConfig, error
 	// 更新配置
 	config.Password = encrypted
 
-	return config, nil
+	return config, s.encryptSSHPassword(&config)
+}
+
+// encryptSSHPassword 使用保险库加密SSH隧道的密码，隧道配置会被复制，不修改原配置
+func (s *Storage) encryptSSHPassword(config *ConnectionConfig) error {
+	if config.SSH == nil || config.SSH.Password == "" || IsReference(config.SSH.Password) {
+		return nil
+	}
+	tunnel := *config.SSH
+	encrypted, err := s.vault.Encrypt(tunnel.Password)
+	if err != nil {
+		return err
+	}
+	tunnel.Password = encrypted
+	config.SSH = &tunnel
+	return nil
 }
 
-// DecryptConnectionPassword 解密连接配置中的密码
-func DecryptConnectionPassword(config ConnectionConfig) (ConnectionConfig, error) {
-	if config.Password == "" {
-		return config, nil
+// DecryptConnectionPassword 使用保险库解密连接配置中的密码
+// 尚未迁移的旧版本密码使用旧密钥解密
+func (s *Storage) DecryptConnectionPassword(config ConnectionConfig) (ConnectionConfig, error) {
+	if config.Password == "" || IsReference(config.Password) {
+		return config, s.decryptSSHPassword(&config)
 	}
 
 	// 解密密码
-	decrypted, err := DecryptPassword(config.Password)
+	var decrypted string
+	var err error
+	if isLegacyEncrypted(config.Password) {
+		decrypted, err = decryptLegacyPassword(config.Password)
+	} else {
+		decrypted, err = s.vault.Decrypt(config.Password)
+	}
 	if err != nil {
 		return config, err
 	}
@@ -133,5 +153,20 @@ func DecryptConnectionPassword(config ConnectionConfig) (ConnectionConfig, error
 	// 更新配置
 	config.Password = decrypted
 
-	return config, nil
+	return config, s.decryptSSHPassword(&config)
+}
+
+// decryptSSHPassword 使用保险库解密SSH隧道的密码，隧道配置会被复制，不修改原配置
+func (s *Storage) decryptSSHPassword(config *ConnectionConfig) error {
+	if config.SSH == nil || config.SSH.Password == "" || IsReference(config.SSH.Password) {
+		return nil
+	}
+	tunnel := *config.SSH
+	decrypted, err := s.vault.Decrypt(tunnel.Password)
+	if err != nil {
+		return err
+	}
+	tunnel.Password = decrypted
+	config.SSH = &tunnel
+	return nil
 }
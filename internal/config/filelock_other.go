@@ -0,0 +1,14 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd || windows)
+
+package config
+
+import "os"
+
+// tryLockFile is a no-op on platforms without file locking; only the
+// in-process mutex protects the configuration there
+func tryLockFile(f *os.File) (bool, error) {
+	return true, nil
+}
+
+// unlockFile is a no-op on platforms without file locking
+func unlockFile(f *os.File) {}
@@ -0,0 +1,403 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// AssetKind 表示资源库中的资源类型
+type AssetKind string
+
+const (
+	AssetScript   AssetKind = "script"   // JavaScript脚本
+	AssetTemplate AssetKind = "template" // 代码模板
+)
+
+const (
+	// assetMetaFile 是每个资源目录中保存元数据的文件名
+	assetMetaFile = "asset.json"
+	// libraryLockFile 是资源库目录中用于跨进程锁的文件名
+	libraryLockFile = ".lock"
+)
+
+// AssetMeta 表示资源的元数据
+type AssetMeta struct {
+	Name        string     `json:"name"`
+	Kind        AssetKind  `json:"kind"`
+	Description string     `json:"description,omitempty"`
+	Author      string     `json:"author,omitempty"`
+	Language    string     `json:"language,omitempty"` // 目标语言，例如 TypeScript
+	Version     string     `json:"version,omitempty"`  // 资源版本号，由作者维护
+	UpdatedAt   time.Time  `json:"updatedAt"`
+	Revisions   []Revision `json:"revisions"`
+}
+
+// Revision 表示资源的一个历史版本
+type Revision struct {
+	Number    int       `json:"number"`
+	CreatedAt time.Time `json:"createdAt"`
+	Version   string    `json:"version,omitempty"`
+	Note      string    `json:"note,omitempty"`
+}
+
+// Asset 表示资源库中的资源，Content 为最新版本的内容
+type Asset struct {
+	AssetMeta
+	Content string `json:"-"`
+}
+
+// CurrentRevision 返回最新版本号，没有版本时返回0
+func (m *AssetMeta) CurrentRevision() int {
+	if len(m.Revisions) == 0 {
+		return 0
+	}
+	return m.Revisions[len(m.Revisions)-1].Number
+}
+
+// Library 表示脚本和模板的统一资源库
+//
+// 目录结构：
+//
+//	library/
+//	├── scripts/<名称>/asset.json      # 元数据和版本记录
+//	├── scripts/<名称>/rev-0001.js     # 各版本内容
+//	└── templates/<名称>/rev-0001.tpl
+type Library struct {
+	dir string
+}
+
+// NewLibrary 创建一个新的资源库
+func NewLibrary(dir string) *Library {
+	return &Library{dir: dir}
+}
+
+// Dir 返回资源库目录
+func (l *Library) Dir() string {
+	return l.dir
+}
+
+// kindDir 返回指定类型资源所在的目录
+func (l *Library) kindDir(kind AssetKind) string {
+	return filepath.Join(l.dir, string(kind)+"s")
+}
+
+// assetDir 返回资源目录，名称无效时返回错误
+func (l *Library) assetDir(kind AssetKind, name string) (string, error) {
+	if err := ValidateAssetName(name); err != nil {
+		return "", err
+	}
+	return filepath.Join(l.kindDir(kind), name), nil
+}
+
+// revisionFile 返回资源指定版本的内容文件路径
+func revisionFile(dir string, kind AssetKind, number int) string {
+	ext := ".js"
+	if kind == AssetTemplate {
+		ext = ".tpl"
+	}
+	return filepath.Join(dir, fmt.Sprintf("rev-%04d%s", number, ext))
+}
+
+// ValidateAssetName 验证资源名称，防止目录遍历
+func ValidateAssetName(name string) error {
+	if strings.TrimSpace(name) == "" {
+		return fmt.Errorf("资源名称不能为空")
+	}
+	if name == "." || name == ".." || strings.ContainsAny(name, `/\:*?"<>|`) {
+		return fmt.Errorf("资源名称包含非法字符: %s", name)
+	}
+	return nil
+}
+
+// List 列出指定类型的所有资源元数据，按名称排序
+func (l *Library) List(kind AssetKind) ([]AssetMeta, error) {
+	entries, err := os.ReadDir(l.kindDir(kind))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var metas []AssetMeta
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		meta, err := l.readMeta(filepath.Join(l.kindDir(kind), entry.Name()))
+		if err != nil {
+			continue
+		}
+		metas = append(metas, *meta)
+	}
+
+	sort.Slice(metas, func(i, j int) bool { return metas[i].Name < metas[j].Name })
+	return metas, nil
+}
+
+// Exists 判断资源是否存在
+func (l *Library) Exists(kind AssetKind, name string) bool {
+	dir, err := l.assetDir(kind, name)
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(filepath.Join(dir, assetMetaFile))
+	return err == nil
+}
+
+// Get 获取资源的元数据和最新内容
+func (l *Library) Get(kind AssetKind, name string) (*Asset, error) {
+	dir, err := l.assetDir(kind, name)
+	if err != nil {
+		return nil, err
+	}
+
+	meta, err := l.readMeta(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("资源 '%s' 不存在", name)
+		}
+		return nil, err
+	}
+
+	content, err := os.ReadFile(revisionFile(dir, kind, meta.CurrentRevision()))
+	if err != nil {
+		return nil, fmt.Errorf("读取资源内容失败: %v", err)
+	}
+
+	return &Asset{AssetMeta: *meta, Content: string(content)}, nil
+}
+
+// Save 保存资源，内容变化时创建新版本，仅元数据变化时只更新元数据
+// 在跨进程锁中先写入版本文件、最后写入元数据，中断时元数据不会指向不存在的版本
+func (l *Library) Save(asset Asset, note string) error {
+	dir, err := l.assetDir(asset.Kind, asset.Name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("创建资源目录失败: %v", err)
+	}
+
+	unlock, err := l.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	meta, err := l.readMeta(dir)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return err
+		}
+		meta = &AssetMeta{Name: asset.Name, Kind: asset.Kind}
+	}
+
+	// 比较最新版本的内容，相同则不创建新版本
+	changed := true
+	if current := meta.CurrentRevision(); current > 0 {
+		if existing, err := os.ReadFile(revisionFile(dir, asset.Kind, current)); err == nil {
+			changed = string(existing) != asset.Content
+		}
+	}
+
+	meta.Description = asset.Description
+	meta.Author = asset.Author
+	meta.Language = asset.Language
+	meta.Version = asset.Version
+	meta.UpdatedAt = time.Now()
+
+	if changed {
+		revision := Revision{
+			Number:    meta.CurrentRevision() + 1,
+			CreatedAt: meta.UpdatedAt,
+			Version:   asset.Version,
+			Note:      note,
+		}
+		if err := writeFileAtomic(revisionFile(dir, asset.Kind, revision.Number), []byte(asset.Content), 0644); err != nil {
+			return fmt.Errorf("保存资源内容失败: %v", err)
+		}
+		meta.Revisions = append(meta.Revisions, revision)
+	}
+
+	return l.writeMeta(dir, meta)
+}
+
+// Delete 删除资源及其全部历史版本
+func (l *Library) Delete(kind AssetKind, name string) error {
+	dir, err := l.assetDir(kind, name)
+	if err != nil {
+		return err
+	}
+	unlock, err := l.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if !l.Exists(kind, name) {
+		return fmt.Errorf("资源 '%s' 不存在", name)
+	}
+	return os.RemoveAll(dir)
+}
+
+// History 返回资源的版本记录，最新版本在最后
+func (l *Library) History(kind AssetKind, name string) ([]Revision, error) {
+	dir, err := l.assetDir(kind, name)
+	if err != nil {
+		return nil, err
+	}
+	meta, err := l.readMeta(dir)
+	if err != nil {
+		return nil, err
+	}
+	return meta.Revisions, nil
+}
+
+// RevisionContent 返回资源指定版本的内容
+func (l *Library) RevisionContent(kind AssetKind, name string, number int) (string, error) {
+	dir, err := l.assetDir(kind, name)
+	if err != nil {
+		return "", err
+	}
+	content, err := os.ReadFile(revisionFile(dir, kind, number))
+	if err != nil {
+		return "", fmt.Errorf("版本 %d 不存在: %v", number, err)
+	}
+	return string(content), nil
+}
+
+// Restore 将资源恢复到指定版本，恢复操作会创建一个新版本，历史记录不会丢失
+func (l *Library) Restore(kind AssetKind, name string, number int) error {
+	asset, err := l.Get(kind, name)
+	if err != nil {
+		return err
+	}
+	content, err := l.RevisionContent(kind, name, number)
+	if err != nil {
+		return err
+	}
+
+	asset.Content = content
+	return l.Save(*asset, fmt.Sprintf("恢复自版本 %d", number))
+}
+
+// Contents 返回指定类型所有资源的最新内容
+func (l *Library) Contents(kind AssetKind) map[string]string {
+	contents := make(map[string]string)
+	metas, err := l.List(kind)
+	if err != nil {
+		return contents
+	}
+	for _, meta := range metas {
+		if asset, err := l.Get(kind, meta.Name); err == nil {
+			contents[meta.Name] = asset.Content
+		}
+	}
+	return contents
+}
+
+// readMeta 读取资源元数据
+func (l *Library) readMeta(dir string) (*AssetMeta, error) {
+	data, err := os.ReadFile(filepath.Join(dir, assetMetaFile))
+	if err != nil {
+		return nil, err
+	}
+	var meta AssetMeta
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return nil, fmt.Errorf("解析资源元数据失败: %v", err)
+	}
+	return &meta, nil
+}
+
+// writeMeta 写入资源元数据
+func (l *Library) writeMeta(dir string, meta *AssetMeta) error {
+	data, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(filepath.Join(dir, assetMetaFile), data, 0644)
+}
+
+// lock 获取资源库的跨进程锁，与配置文件一样防止多个实例同时修改同一个资源
+func (l *Library) lock() (func(), error) {
+	if err := os.MkdirAll(l.dir, 0755); err != nil {
+		return nil, fmt.Errorf("创建资源库目录失败: %v", err)
+	}
+	unlock, err := lockFile(filepath.Join(l.dir, libraryLockFile))
+	if err != nil {
+		return nil, fmt.Errorf("锁定资源库失败: %v", err)
+	}
+	return unlock, nil
+}
+
+// legacyAssetSource 表示迁移前的一个资源来源
+type legacyAssetSource struct {
+	kind     AssetKind
+	dir      string            // 文件目录，为空时使用 contents
+	ext      string            // 文件扩展名
+	contents map[string]string // 配置文件中的内容
+	origin   string            // 来源说明，记录在版本备注中
+}
+
+// importLegacy 将旧位置的资源导入资源库
+// 同名资源内容相同时跳过，不同时作为新版本保存，确保任何内容都不会丢失
+func (l *Library) importLegacy(source legacyAssetSource) (int, error) {
+	contents := source.contents
+	if source.dir != "" {
+		contents = make(map[string]string)
+		entries, err := os.ReadDir(source.dir)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return 0, nil
+			}
+			return 0, err
+		}
+		for _, entry := range entries {
+			if entry.IsDir() || filepath.Ext(entry.Name()) != source.ext {
+				continue
+			}
+			data, err := os.ReadFile(filepath.Join(source.dir, entry.Name()))
+			if err != nil {
+				return 0, err
+			}
+			contents[strings.TrimSuffix(entry.Name(), source.ext)] = string(data)
+		}
+	}
+
+	names := make([]string, 0, len(contents))
+	for name := range contents {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	imported := 0
+	for _, name := range names {
+		if ValidateAssetName(name) != nil {
+			continue
+		}
+
+		asset := Asset{
+			AssetMeta: AssetMeta{Name: name, Kind: source.kind, Language: "TypeScript"},
+			Content:   contents[name],
+		}
+		if existing, err := l.Get(source.kind, name); err == nil {
+			if existing.Content == asset.Content {
+				continue
+			}
+			asset.AssetMeta = existing.AssetMeta
+		}
+
+		if err := l.Save(asset, "迁移自 "+source.origin); err != nil {
+			return imported, err
+		}
+		imported++
+	}
+
+	return imported, nil
+}
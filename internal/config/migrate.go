@@ -0,0 +1,359 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"go-DBmodeler/internal/db/dialect"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CurrentConfigVersion 是当前配置文件格式的版本
+const CurrentConfigVersion = 8
+
+// configMigration 表示配置文件从 from 版本升级到 from+1 版本的一个步骤
+type configMigration struct {
+	from        int
+	description string
+	migrate     func(doc map[string]any) error
+}
+
+// configMigrations 是按版本排列的迁移链，修改配置结构时在末尾追加新的步骤并增加 CurrentConfigVersion
+var configMigrations = []configMigration{
+	{
+		from:        0,
+		description: "为配置文件添加版本号，规范化连接列表和数据库类型名称",
+		migrate: func(doc map[string]any) error {
+			connections, _ := doc["connections"].([]any)
+			if connections == nil {
+				connections = []any{}
+			}
+			for _, item := range connections {
+				conn, ok := item.(map[string]any)
+				if !ok {
+					continue
+				}
+				if dbType, ok := conn["type"].(string); ok {
+					conn["type"] = normalizeV1DatabaseType(dbType)
+				}
+			}
+			doc["connections"] = connections
+			return nil
+		},
+	},
+	{
+		from:        1,
+		description: "连接支持分组和生成器默认设置",
+		migrate: func(doc map[string]any) error {
+			// 新字段都是可选的，旧连接不需要修改；升级版本号使旧版本程序不会丢弃这些字段
+			return nil
+		},
+	},
+	{
+		from:        2,
+		description: "连接支持标签和环境标记",
+		migrate: func(doc map[string]any) error {
+			// 新字段都是可选的，与 1→2 相同
+			return nil
+		},
+	},
+	{
+		from:        3,
+		description: "连接支持SSH隧道",
+		migrate: func(doc map[string]any) error {
+			// SSH隧道是可选的，没有隧道的连接不需要修改
+			return nil
+		},
+	},
+	{
+		from:        4,
+		description: "连接支持TLS设置",
+		migrate: func(doc map[string]any) error {
+			// 没有TLS设置的连接与之前一样不使用TLS
+			return nil
+		},
+	},
+	{
+		from:        5,
+		description: "连接支持连接超时和查询超时",
+		migrate: func(doc map[string]any) error {
+			// 没有设置超时的连接使用默认超时
+			return nil
+		},
+	},
+	{
+		from:        6,
+		description: "保存作为生成来源的查询",
+		migrate: func(doc map[string]any) error {
+			// 查询列表是可选的；升级版本号使旧版本程序拒绝读取，而不是保存时丢弃查询
+			return nil
+		},
+	},
+	{
+		from:        7,
+		description: "保存JSON列推断的结构",
+		migrate: func(doc map[string]any) error {
+			// JSON结构列表是可选的，没有保存结构的配置不需要修改
+			return nil
+		},
+	},
+}
+
+// v1DatabaseTypes 是版本1支持的数据库类型名称
+// 迁移步骤的结果不能随当前支持的数据库类型变化，因此这里保留版本1时的列表
+var v1DatabaseTypes = []string{"MySQL", "PostgreSQL", "SQLite"}
+
+// normalizeV1DatabaseType 将大小写不同的数据库类型名称转换为版本1的标准名称，无法识别时原样返回
+func normalizeV1DatabaseType(dbType string) string {
+	for _, known := range v1DatabaseTypes {
+		if strings.EqualFold(dbType, known) {
+			return known
+		}
+	}
+	return dbType
+}
+
+// configDocument 表示解析和升级后的配置文件
+type configDocument struct {
+	config      AppConfig
+	fromVersion int      // 文件原来的版本
+	issues      []string // 校验发现的问题
+}
+
+// decodeConfig 解析配置文件，按需逐步升级到当前版本并校验内容
+func decodeConfig(data []byte) (*configDocument, error) {
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if doc == nil {
+		doc = make(map[string]any)
+	}
+
+	version := 0
+	if raw, ok := doc["version"]; ok {
+		number, ok := raw.(float64)
+		if !ok || number != float64(int(number)) || number < 0 {
+			return nil, fmt.Errorf("配置文件版本号无效: %v", raw)
+		}
+		version = int(number)
+	}
+	if version > CurrentConfigVersion {
+		return nil, fmt.Errorf("配置文件版本 %d 高于当前程序支持的版本 %d，请升级程序", version, CurrentConfigVersion)
+	}
+
+	// 逐步执行迁移
+	for _, migration := range configMigrations {
+		if migration.from < version {
+			continue
+		}
+		if err := migration.migrate(doc); err != nil {
+			return nil, fmt.Errorf("配置文件从版本 %d 升级失败（%s）: %v", migration.from, migration.description, err)
+		}
+		doc["version"] = migration.from + 1
+	}
+
+	upgraded, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	var cfg AppConfig
+	if err := json.Unmarshal(upgraded, &cfg); err != nil {
+		return nil, err
+	}
+	if cfg.Connections == nil {
+		cfg.Connections = make([]ConnectionConfig, 0)
+	}
+
+	return &configDocument{
+		config:      cfg,
+		fromVersion: version,
+		issues:      validateConfig(doc, cfg),
+	}, nil
+}
+
+// backupConfigFile 在升级前备份配置文件，返回备份文件路径
+func backupConfigFile(path string, data []byte, version int) (string, error) {
+	backup := fmt.Sprintf("%s.v%d-%s.bak", path, version, time.Now().Format("20060102-150405"))
+	if err := os.WriteFile(backup, data, 0600); err != nil {
+		return "", err
+	}
+	return backup, nil
+}
+
+// validateConfig 检查配置中未知的字段和无效的条目
+// 未知字段不会被保存，因此需要提示用户而不是静默忽略
+func validateConfig(doc map[string]any, cfg AppConfig) []string {
+	var issues []string
+
+	for _, key := range unknownKeys(doc, reflect.TypeOf(AppConfig{})) {
+		issues = append(issues, fmt.Sprintf("未知的配置项 \"%s\"，保存时将被丢弃", key))
+	}
+
+	rawConnections, _ := doc["connections"].([]any)
+	names := make(map[string]bool)
+	for i, conn := range cfg.Connections {
+		label := fmt.Sprintf("第 %d 个连接", i+1)
+		if conn.Name != "" {
+			label = fmt.Sprintf("连接 '%s'", conn.Name)
+		}
+
+		if i < len(rawConnections) {
+			if raw, ok := rawConnections[i].(map[string]any); ok {
+				for _, key := range unknownKeys(raw, reflect.TypeOf(ConnectionConfig{})) {
+					issues = append(issues, fmt.Sprintf("%s 包含未知字段 \"%s\"，保存时将被丢弃", label, key))
+				}
+				if rawSSH, ok := raw["ssh"].(map[string]any); ok {
+					for _, key := range unknownKeys(rawSSH, reflect.TypeOf(SSHTunnel{})) {
+						issues = append(issues, fmt.Sprintf("%s 的SSH隧道包含未知字段 \"%s\"，保存时将被丢弃", label, key))
+					}
+				}
+				if rawTLS, ok := raw["tls"].(map[string]any); ok {
+					for _, key := range unknownKeys(rawTLS, reflect.TypeOf(TLSOptions{})) {
+						issues = append(issues, fmt.Sprintf("%s 的TLS设置包含未知字段 \"%s\"，保存时将被丢弃", label, key))
+					}
+				}
+			}
+		}
+
+		if strings.TrimSpace(conn.Name) == "" {
+			issues = append(issues, label+" 缺少名称")
+		} else if names[conn.Name] {
+			issues = append(issues, label+" 名称重复")
+		}
+		names[conn.Name] = true
+
+		d, err := dialect.Lookup(conn.Type)
+		if err != nil {
+			issues = append(issues, fmt.Sprintf("%s 的数据库类型 \"%s\" 不受支持", label, conn.Type))
+		} else {
+			for _, issue := range d.Check(connectionValues(conn)) {
+				issues = append(issues, label+" "+issue)
+			}
+			if conn.SSH != nil && !d.SSH {
+				issues = append(issues, fmt.Sprintf("%s 是%s连接，SSH隧道不会生效", label, d.Name))
+			}
+			if conn.TLS != nil && !d.TLS {
+				issues = append(issues, fmt.Sprintf("%s 是%s连接，TLS设置不会生效", label, d.Name))
+			}
+		}
+		if conn.Environment != "" && !isKnownEnvironment(conn.Environment) {
+			issues = append(issues, fmt.Sprintf("%s 的环境标记 \"%s\" 无效，应为 %s 之一", label, conn.Environment, strings.Join(Environments, "、")))
+		}
+		if conn.Port != "" && !isValidPort(conn.Port) {
+			issues = append(issues, fmt.Sprintf("%s 的端口 \"%s\" 无效", label, conn.Port))
+		}
+		if conn.ConnectTimeout < 0 || conn.QueryTimeout < 0 {
+			issues = append(issues, label+" 的超时时间不能为负数")
+		}
+		if tunnel := conn.SSH; tunnel != nil {
+			if tunnel.Host == "" || tunnel.User == "" {
+				issues = append(issues, label+" 的SSH隧道缺少跳板机主机或用户名")
+			}
+			if tunnel.Port != "" && !isValidPort(tunnel.Port) {
+				issues = append(issues, fmt.Sprintf("%s 的SSH隧道端口 \"%s\" 无效", label, tunnel.Port))
+			}
+			switch tunnel.Auth {
+			case SSHAuthKey, SSHAuthAgent, SSHAuthPassword:
+			default:
+				issues = append(issues, fmt.Sprintf("%s 的SSH认证方式 \"%s\" 无效，应为 %s、%s 或 %s", label, tunnel.Auth, SSHAuthKey, SSHAuthAgent, SSHAuthPassword))
+			}
+		}
+		if options := conn.TLS; options != nil {
+			if !isKnownTLSMode(options.Mode) {
+				issues = append(issues, fmt.Sprintf("%s 的TLS模式 \"%s\" 无效，应为 %s 之一", label, options.Mode, strings.Join(TLSModes, "、")))
+			}
+			if (options.ClientCert == "") != (options.ClientKey == "") {
+				issues = append(issues, label+" 的客户端证书和私钥需要同时设置")
+			}
+		}
+	}
+
+	queryNames := make(map[string]bool)
+	for i, query := range cfg.Queries {
+		switch {
+		case strings.TrimSpace(query.Name) == "":
+			issues = append(issues, fmt.Sprintf("第 %d 个查询缺少名称", i+1))
+		case queryNames[query.Name]:
+			issues = append(issues, fmt.Sprintf("查询 '%s' 名称重复", query.Name))
+		}
+		queryNames[query.Name] = true
+	}
+
+	for i, shape := range cfg.JSONShapes {
+		if shape.Table == "" || shape.Column == "" || strings.TrimSpace(shape.TypeName) == "" {
+			issues = append(issues, fmt.Sprintf("第 %d 个JSON结构缺少表名、列名或类型名称", i+1))
+		}
+	}
+
+	return issues
+}
+
+// connectionValues 返回数据库类型检查使用的连接表单字段
+func connectionValues(conn ConnectionConfig) map[string]string {
+	return map[string]string{
+		dialect.FieldHost:     conn.Host,
+		dialect.FieldPort:     conn.Port,
+		dialect.FieldUsername: conn.Username,
+		dialect.FieldPassword: conn.Password,
+		dialect.FieldDatabase: conn.Database,
+	}
+}
+
+// isValidPort 判断端口是否有效，间接引用在连接时才能检查
+func isValidPort(value string) bool {
+	if IsReference(value) {
+		return true
+	}
+	port, err := strconv.Atoi(value)
+	return err == nil && port > 0 && port <= 65535
+}
+
+// isKnownTLSMode 判断TLS模式是否有效
+func isKnownTLSMode(mode string) bool {
+	for _, known := range TLSModes {
+		if mode == known {
+			return true
+		}
+	}
+	return false
+}
+
+// isKnownEnvironment 判断环境标记是否有效
+func isKnownEnvironment(environment string) bool {
+	for _, known := range Environments {
+		if environment == known {
+			return true
+		}
+	}
+	return false
+}
+
+// unknownKeys 返回对象中结构体没有对应 json 字段的键，按名称排序
+// 与 encoding/json 一致，字段名匹配不区分大小写
+func unknownKeys(object map[string]any, t reflect.Type) []string {
+	known := make(map[string]bool)
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = t.Field(i).Name
+		}
+		known[strings.ToLower(name)] = true
+	}
+
+	var unknown []string
+	for key := range object {
+		if !known[strings.ToLower(key)] {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+	return unknown
+}
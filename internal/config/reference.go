@@ -0,0 +1,108 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// referencePattern 匹配连接配置中的间接引用，例如 ${env:PROD_DB_PASSWORD} 或 ${file:/run/secrets/db}
+var referencePattern = regexp.MustCompile(`\$\{(env|file):([^}]+)\}`)
+
+// IsReference 判断值中是否包含间接引用
+func IsReference(value string) bool {
+	return referencePattern.MatchString(value)
+}
+
+// ResolveReferences 解析值中的所有间接引用
+// env 引用读取环境变量，file 引用读取文件内容并去掉末尾的换行
+func ResolveReferences(value string) (string, error) {
+	var resolveErr error
+	resolved := referencePattern.ReplaceAllStringFunc(value, func(ref string) string {
+		if resolveErr != nil {
+			return ""
+		}
+		match := referencePattern.FindStringSubmatch(ref)
+		kind, target := match[1], strings.TrimSpace(match[2])
+
+		switch kind {
+		case "env":
+			envValue, ok := os.LookupEnv(target)
+			if !ok {
+				resolveErr = fmt.Errorf("环境变量 %s 未设置", target)
+				return ""
+			}
+			return envValue
+		default:
+			data, err := os.ReadFile(target)
+			if err != nil {
+				resolveErr = fmt.Errorf("读取文件 %s 失败: %v", target, err)
+				return ""
+			}
+			return strings.TrimRight(string(data), "\r\n")
+		}
+	})
+	if resolveErr != nil {
+		return "", resolveErr
+	}
+	return resolved, nil
+}
+
+// IndirectFields 返回包含间接引用的字段，键为字段名，值为原始引用
+func (c ConnectionConfig) IndirectFields() map[string]string {
+	fields := make(map[string]string)
+	for name, value := range c.referenceFields() {
+		if IsReference(*value) {
+			fields[name] = *value
+		}
+	}
+	return fields
+}
+
+// Resolve 返回解析了所有间接引用的连接配置
+// 解析结果只在内存中使用，不会写回配置文件
+func (c ConnectionConfig) Resolve() (ConnectionConfig, error) {
+	// 复制隧道和TLS配置，避免修改原配置
+	if c.SSH != nil {
+		tunnel := *c.SSH
+		c.SSH = &tunnel
+	}
+	if c.TLS != nil {
+		options := *c.TLS
+		c.TLS = &options
+	}
+	for name, value := range c.referenceFields() {
+		resolved, err := ResolveReferences(*value)
+		if err != nil {
+			return c, fmt.Errorf("解析连接 '%s' 的 %s 失败: %v", c.Name, name, err)
+		}
+		*value = resolved
+	}
+	return c, nil
+}
+
+// referenceFields 返回允许使用间接引用的字段
+func (c *ConnectionConfig) referenceFields() map[string]*string {
+	fields := map[string]*string{
+		"host":     &c.Host,
+		"port":     &c.Port,
+		"username": &c.Username,
+		"password": &c.Password,
+		"database": &c.Database,
+	}
+	if c.SSH != nil {
+		fields["ssh.host"] = &c.SSH.Host
+		fields["ssh.port"] = &c.SSH.Port
+		fields["ssh.user"] = &c.SSH.User
+		fields["ssh.keyFile"] = &c.SSH.KeyFile
+		fields["ssh.password"] = &c.SSH.Password
+	}
+	if c.TLS != nil {
+		fields["tls.caCert"] = &c.TLS.CACert
+		fields["tls.clientCert"] = &c.TLS.ClientCert
+		fields["tls.clientKey"] = &c.TLS.ClientKey
+		fields["tls.serverName"] = &c.TLS.ServerName
+	}
+	return fields
+}
@@ -0,0 +1,192 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"go-DBmodeler/internal/db/dialect"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// sharedConnectionsKind 标识团队共享的连接文件
+const sharedConnectionsKind = "godbmodeler-connections"
+
+// sharedConnectionsVersion 是共享连接文件的格式版本
+const sharedConnectionsVersion = 1
+
+// SharedConnections 表示可以提交到代码仓库的连接文件，其中不包含密码
+type SharedConnections struct {
+	Kind        string             `json:"kind"`
+	Version     int                `json:"version"`
+	Connections []ConnectionConfig `json:"connections"`
+}
+
+// PasswordExportMode 表示导出时如何处理密码
+type PasswordExportMode int
+
+const (
+	PasswordStrip       PasswordExportMode = iota // 移除密码
+	PasswordPlaceholder                           // 替换为 ${env:...} 占位符
+)
+
+// ConflictResolution 表示导入时如何处理同名连接
+type ConflictResolution int
+
+const (
+	ConflictRename    ConflictResolution = iota // 以新名称导入
+	ConflictOverwrite                           // 覆盖现有连接，保留本地保存的密码
+	ConflictSkip                                // 跳过
+)
+
+// envNamePattern 匹配环境变量名中不允许的字符
+var envNamePattern = regexp.MustCompile(`[^A-Z0-9]+`)
+
+// PasswordEnvName 返回连接密码占位符使用的环境变量名，例如 prod-db 对应 PROD_DB_PASSWORD
+func PasswordEnvName(connectionName string) string {
+	name := strings.Trim(envNamePattern.ReplaceAllString(strings.ToUpper(connectionName), "_"), "_")
+	if name == "" {
+		name = "DB"
+	}
+	return name + "_PASSWORD"
+}
+
+// SSHPasswordEnvName 返回SSH隧道密码占位符使用的环境变量名，例如 prod-db 对应 PROD_DB_SSH_PASSWORD
+func SSHPasswordEnvName(connectionName string) string {
+	return strings.TrimSuffix(PasswordEnvName(connectionName), "_PASSWORD") + "_SSH_PASSWORD"
+}
+
+// ExportConnections 导出指定的连接，names 为空时导出全部连接
+// 密码按 mode 移除或替换为环境变量占位符；已经是间接引用的密码不是机密，原样导出
+func (s *Storage) ExportConnections(names []string, mode PasswordExportMode) ([]byte, error) {
+	selected := make(map[string]bool, len(names))
+	for _, name := range names {
+		selected[name] = true
+	}
+
+	shared := SharedConnections{Kind: sharedConnectionsKind, Version: sharedConnectionsVersion}
+	for _, conn := range s.GetConnections() {
+		if len(names) > 0 && !selected[conn.Name] {
+			continue
+		}
+		if conn.Password != "" && !IsReference(conn.Password) {
+			conn.Password = ""
+			if mode == PasswordPlaceholder {
+				conn.Password = "${env:" + PasswordEnvName(conn.Name) + "}"
+			}
+		}
+		if conn.SSH != nil && conn.SSH.Password != "" && !IsReference(conn.SSH.Password) {
+			tunnel := *conn.SSH
+			tunnel.Password = ""
+			if mode == PasswordPlaceholder {
+				tunnel.Password = "${env:" + SSHPasswordEnvName(conn.Name) + "}"
+			}
+			conn.SSH = &tunnel
+		}
+		shared.Connections = append(shared.Connections, conn)
+	}
+	if len(shared.Connections) == 0 {
+		return nil, fmt.Errorf("没有要导出的连接")
+	}
+
+	return json.MarshalIndent(shared, "", "  ")
+}
+
+// ParseSharedConnections 解析共享连接文件，文件不是共享连接格式时 ok 为 false
+func ParseSharedConnections(data []byte) (connections []ConnectionConfig, ok bool, err error) {
+	var shared SharedConnections
+	if err := json.Unmarshal(data, &shared); err != nil || shared.Kind != sharedConnectionsKind {
+		return nil, false, nil
+	}
+	if shared.Version > sharedConnectionsVersion {
+		return nil, true, fmt.Errorf("共享连接文件版本 %d 高于当前程序支持的版本 %d，请升级程序", shared.Version, sharedConnectionsVersion)
+	}
+	for i := range shared.Connections {
+		shared.Connections[i].Type = dialect.Normalize(shared.Connections[i].Type)
+		// 共享文件中只允许出现间接引用形式的密码
+		if !IsReference(shared.Connections[i].Password) {
+			shared.Connections[i].Password = ""
+		}
+		if tunnel := shared.Connections[i].SSH; tunnel != nil && !IsReference(tunnel.Password) {
+			tunnel.Password = ""
+		}
+	}
+	return shared.Connections, true, nil
+}
+
+// SameConnection 判断两个连接除密码外的设置是否相同
+func SameConnection(a, b ConnectionConfig) bool {
+	a.Password, b.Password = "", ""
+	for _, conn := range []*ConnectionConfig{&a, &b} {
+		if conn.SSH != nil {
+			tunnel := *conn.SSH
+			tunnel.Password = ""
+			conn.SSH = &tunnel
+		}
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// HasSameConnection 判断是否已有同名且设置和密码都相同的连接，导入的连接没有密码时只比较设置
+// 保存的密码是加密的，需要解密后再比较
+func (s *Storage) HasSameConnection(config ConnectionConfig) bool {
+	existing, ok := s.FindConnection(config.Name)
+	if !ok || !SameConnection(existing, config) {
+		return false
+	}
+	if config.Password == "" {
+		return true
+	}
+	decrypted, err := s.DecryptConnectionPassword(existing)
+	return err == nil && decrypted.Password == config.Password
+}
+
+// MergeConnection 导入一个连接，按 resolution 处理同名连接
+// 返回导入后的连接名称，跳过时返回空字符串
+func (s *Storage) MergeConnection(config ConnectionConfig, resolution ConflictResolution) (string, error) {
+	encryptedConfig, err := s.EncryptConnectionPassword(config)
+	if err != nil {
+		return "", err
+	}
+
+	err = s.update(func(cfg *AppConfig) error {
+		for i, existing := range cfg.Connections {
+			if existing.Name != config.Name {
+				continue
+			}
+
+			switch resolution {
+			case ConflictSkip:
+				encryptedConfig.Name = ""
+			case ConflictOverwrite:
+				// 共享文件不包含密码，只有空密码或占位符，覆盖时保留本地保存的密码
+				if existing.Password != "" && !IsReference(existing.Password) &&
+					(encryptedConfig.Password == "" || IsReference(encryptedConfig.Password)) {
+					encryptedConfig.Password = existing.Password
+				}
+				if existing.SSH != nil && existing.SSH.Password != "" && !IsReference(existing.SSH.Password) &&
+					encryptedConfig.SSH != nil && (encryptedConfig.SSH.Password == "" || IsReference(encryptedConfig.SSH.Password)) {
+					tunnel := *encryptedConfig.SSH
+					tunnel.Password = existing.SSH.Password
+					encryptedConfig.SSH = &tunnel
+				}
+				// 导入的连接没有生成器默认设置时保留本地设置
+				if encryptedConfig.GeneratorDefaults == nil {
+					encryptedConfig.GeneratorDefaults = existing.GeneratorDefaults
+				}
+				cfg.Connections[i] = encryptedConfig
+			default:
+				encryptedConfig.Name = uniqueConnectionName(cfg.Connections, config.Name)
+				cfg.Connections = append(cfg.Connections, encryptedConfig)
+			}
+			return nil
+		}
+
+		cfg.Connections = append(cfg.Connections, encryptedConfig)
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return encryptedConfig.Name, nil
+}
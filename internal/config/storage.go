@@ -1,10 +1,13 @@
 package config
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 )
 
 // ConnectionConfig represents database connection configuration
@@ -16,20 +19,154 @@ type ConnectionConfig struct {
 	Username string `json:"username"`
 	Password string `json:"password,omitempty"`
 	Database string `json:"database"`
+
+	// Group organizes connections into folders, nested with "/", e.g. "payments/eu"
+	Group string `json:"group,omitempty"`
+	// Tags are free-form labels used for searching
+	Tags []string `json:"tags,omitempty"`
+	// Environment is one of the Environments, or empty when not labelled
+	Environment string `json:"environment,omitempty"`
+	// GeneratorDefaults are applied when the connection is used for code generation
+	GeneratorDefaults *GeneratorDefaults `json:"generatorDefaults,omitempty"`
+	// SSH, when set, connects to the database through an SSH bastion host
+	SSH *SSHTunnel `json:"ssh,omitempty"`
+	// TLS configures an encrypted connection; nil means TLS is disabled
+	TLS *TLSOptions `json:"tls,omitempty"`
+
+	// ConnectTimeout and QueryTimeout are in seconds; 0 uses the connector defaults
+	ConnectTimeout int `json:"connectTimeout,omitempty"`
+	QueryTimeout   int `json:"queryTimeout,omitempty"`
+}
+
+// SSH authentication methods
+const (
+	SSHAuthKey      = "key"
+	SSHAuthAgent    = "agent"
+	SSHAuthPassword = "password"
+)
+
+// SSHTunnel holds the settings of an SSH bastion host used to reach the database.
+// The database host is resolved on the bastion.
+type SSHTunnel struct {
+	Host     string `json:"host"`
+	Port     string `json:"port,omitempty"` // defaults to 22
+	User     string `json:"user"`
+	Auth     string `json:"auth"` // SSHAuthKey, SSHAuthAgent or SSHAuthPassword
+	KeyFile  string `json:"keyFile,omitempty"`
+	Password string `json:"password,omitempty"` // login password, or the passphrase of an encrypted key; encrypted like the database password
+	// KnownHostsFile defaults to ~/.ssh/known_hosts
+	KnownHostsFile        string `json:"knownHostsFile,omitempty"`
+	InsecureIgnoreHostKey bool   `json:"insecureIgnoreHostKey,omitempty"`
+}
+
+// Environment labels for connections
+const (
+	EnvironmentDev  = "dev"
+	EnvironmentTest = "test"
+	EnvironmentProd = "prod"
+)
+
+// Environments lists the supported environment labels
+var Environments = []string{EnvironmentDev, EnvironmentTest, EnvironmentProd}
+
+// IsProduction reports whether the connection is labelled as production
+func (c ConnectionConfig) IsProduction() bool {
+	return c.Environment == EnvironmentProd
+}
+
+// TLS modes, named after the PostgreSQL sslmode values
+const (
+	TLSModeDisable    = "disable"
+	TLSModeRequire    = "require"
+	TLSModeVerifyCA   = "verify-ca"
+	TLSModeVerifyFull = "verify-full"
+)
+
+// TLSModes lists the supported TLS modes
+var TLSModes = []string{TLSModeDisable, TLSModeRequire, TLSModeVerifyCA, TLSModeVerifyFull}
+
+// TLSOptions holds the TLS settings of a connection. Certificates and keys are file paths.
+type TLSOptions struct {
+	Mode       string `json:"mode"`
+	CACert     string `json:"caCert,omitempty"` // defaults to the system roots
+	ClientCert string `json:"clientCert,omitempty"`
+	ClientKey  string `json:"clientKey,omitempty"`
+	ServerName string `json:"serverName,omitempty"` // name to verify instead of the host
+}
+
+// GeneratorDefaults holds the generator settings remembered for a connection
+type GeneratorDefaults struct {
+	Generator string            `json:"generator,omitempty"` // builtin, script:<name> or plugin:<name>
+	Template  string            `json:"template,omitempty"`
+	Database  string            `json:"database,omitempty"`
+	OutputDir string            `json:"outputDir,omitempty"`
+	Params    map[string]string `json:"params,omitempty"`
+}
+
+// SavedQuery is a SELECT statement used as a generation source instead of a table
+type SavedQuery struct {
+	Name string `json:"name"` // also the name of the generated result interface
+	SQL  string `json:"sql"`
+	// Connection and Database record where the query was written; empty means any
+	Connection string `json:"connection,omitempty"`
+	Database   string `json:"database,omitempty"`
+}
+
+// JSONShape is the edited TypeScript type inferred for a JSON column from sample rows.
+// It is keyed by connection, database, table and column so that regenerating
+// the table reuses it instead of sampling again.
+type JSONShape struct {
+	Connection   string `json:"connection"`
+	Database     string `json:"database,omitempty"`
+	Table        string `json:"table"`
+	Column       string `json:"column"`
+	TypeName     string `json:"typeName"`     // type used for the column's field
+	Declarations string `json:"declarations"` // TypeScript declarations of TypeName and its nested types
+	SampleSize   int    `json:"sampleSize,omitempty"`
+}
+
+// matches reports whether the shape belongs to the given table
+func (j JSONShape) matches(connection, database, table string) bool {
+	return j.Connection == connection && j.Database == database && j.Table == table
 }
 
 // AppConfig represents application configuration
 type AppConfig struct {
-	Connections []ConnectionConfig `json:"connections"`
-	Templates   map[string]string  `json:"templates"`
-	Scripts     map[string]string  `json:"scripts"`
+	Version      int                          `json:"version"`
+	Connections  []ConnectionConfig           `json:"connections"`
+	Queries      []SavedQuery                 `json:"queries,omitempty"`
+	JSONShapes   []JSONShape                  `json:"jsonShapes,omitempty"`
+	ScriptParams map[string]map[string]string `json:"scriptParams,omitempty"`
+
+	// Templates and Scripts are only read to migrate older configurations
+	// into the asset library; they are cleared once the migration is done.
+	Templates map[string]string `json:"templates,omitempty"`
+	Scripts   map[string]string `json:"scripts,omitempty"`
 }
 
-// Storage represents configuration storage
+// defaultScriptNames lists the built-in scripts that cannot be deleted
+var defaultScriptNames = []string{"camelCase", "addHeader", "formatCode", "addImports"}
+
+// libraryMigratedMarker is created in the library directory after the legacy migration
+const libraryMigratedMarker = ".migrated"
+
+// Storage represents configuration storage.
+//
+// All access to the configuration goes through mu. Changes are applied with
+// update, which holds a cross-process lock on the config file and re-reads it
+// first, so that several running instances merge their changes instead of
+// overwriting each other.
 type Storage struct {
+	mu         sync.Mutex
 	config     AppConfig
+	loaded     []byte   // config file contents as last read or written, used to detect external changes
+	issues     []string // problems found by the last validation of the config file
+	upgraded   int      // version the config file was upgraded from, -1 if it was current
 	configDir  string
 	configFile string
+	lockFile   string
+	library    *Library
+	vault      *Vault
 }
 
 // NewStorage creates a new configuration storage
@@ -49,18 +186,15 @@ func NewStorage() (*Storage, error) {
 	storage := &Storage{
 		configDir:  configDir,
 		configFile: configFile,
+		lockFile:   configFile + ".lock",
+		upgraded:   -1,
+		library:    NewLibrary(filepath.Join(configDir, "library")),
+		vault:      NewVault(filepath.Join(configDir, "vault.json")),
 		config: AppConfig{
 			Connections: make([]ConnectionConfig, 0),
-			Templates:   map[string]string{"default": DefaultTemplate()},
-			Scripts:     map[string]string{},
 		},
 	}
 
-	storage.config.Scripts["camelCase"] = DefaultCamelCaseScript()
-	storage.config.Scripts["addHeader"] = DefaultHeaderScript()
-	storage.config.Scripts["formatCode"] = DefaultFormatScript()
-	storage.config.Scripts["addImports"] = DefaultImportScript()
-
 	if err := storage.Load(); err != nil {
 		if os.IsNotExist(err) {
 			if err := storage.Save(); err != nil {
@@ -71,106 +205,553 @@ func NewStorage() (*Storage, error) {
 		}
 	}
 
+	if err := storage.finishUpgrade(); err != nil {
+		return nil, fmt.Errorf("failed to upgrade config file: %v", err)
+	}
+
+	if err := storage.migrateLegacyAssets(); err != nil {
+		return nil, fmt.Errorf("failed to migrate scripts and templates: %v", err)
+	}
+
+	if err := storage.seedDefaultAssets(); err != nil {
+		return nil, err
+	}
+
 	return storage, nil
 }
 
 // Load loads configuration from file
 func (s *Storage) Load() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	data, err := os.ReadFile(s.configFile)
 	if err != nil {
 		return err
 	}
+	return s.apply(data)
+}
+
+// Save saves configuration to file, keeping changes made by other instances
+func (s *Storage) Save() error {
+	return s.update(func(*AppConfig) error { return nil })
+}
+
+// update applies fn to the latest configuration and saves the result atomically.
+// The config file is re-read under the cross-process lock first, so fn always
+// works on top of changes that other instances have saved in the meantime.
+// If fn fails, neither the file nor the in-memory configuration is changed.
+func (s *Storage) update(fn func(cfg *AppConfig) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	unlock, err := lockFile(s.lockFile)
+	if err != nil {
+		return fmt.Errorf("failed to lock config file: %v", err)
+	}
+	defer unlock()
 
-	if err := json.Unmarshal(data, &s.config); err != nil {
+	if err := s.reloadIfChanged(); err != nil {
 		return err
 	}
 
+	cfg, err := s.config.clone()
+	if err != nil {
+		return err
+	}
+	if err := fn(&cfg); err != nil {
+		return err
+	}
+	cfg.Version = CurrentConfigVersion
+
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(s.configFile, data, 0600); err != nil {
+		return err
+	}
+
+	s.config = cfg
+	s.loaded = data
 	return nil
 }
 
-// Save saves configuration to file
-func (s *Storage) Save() error {
-	data, err := json.MarshalIndent(s.config, "", "  ")
+// reloadIfChanged re-reads the config file when another process has changed it.
+// The caller must hold mu.
+func (s *Storage) reloadIfChanged() error {
+	data, err := os.ReadFile(s.configFile)
 	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
 		return err
 	}
+	if bytes.Equal(data, s.loaded) {
+		return nil
+	}
+	return s.apply(data)
+}
 
-	if err := os.WriteFile(s.configFile, data, 0644); err != nil {
+// apply replaces the in-memory configuration with the parsed file contents,
+// upgrading older formats and validating the result. The caller must hold mu.
+func (s *Storage) apply(data []byte) error {
+	doc, err := decodeConfig(data)
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %v", s.configFile, err)
+	}
+	if doc.fromVersion < CurrentConfigVersion {
+		s.upgraded = doc.fromVersion
+	}
+	s.config = doc.config
+	s.issues = doc.issues
+	s.loaded = data
+	return nil
+}
+
+// finishUpgrade backs up a config file that was loaded in an older format and
+// saves it in the current format
+func (s *Storage) finishUpgrade() error {
+	s.mu.Lock()
+	version, original := s.upgraded, s.loaded
+	s.mu.Unlock()
+	if version < 0 || original == nil {
+		return nil
+	}
+
+	if _, err := backupConfigFile(s.configFile, original, version); err != nil {
+		return err
+	}
+	if err := s.Save(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.upgraded = -1
+	s.mu.Unlock()
+	return nil
+}
+
+// ValidationIssues returns the problems found in the config file, such as
+// unknown fields or invalid connections
+func (s *Storage) ValidationIssues() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.issues...)
+}
+
+// snapshot returns the current configuration, picking up external changes.
+// A copy is returned so callers can't modify the shared state.
+func (s *Storage) snapshot() AppConfig {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// A file that can't be read or parsed right now is left for update to report;
+	// readers keep using the last good configuration.
+	_ = s.reloadIfChanged()
+
+	cfg, err := s.config.clone()
+	if err != nil {
+		return s.config
+	}
+	return cfg
+}
+
+// clone returns a deep copy of the configuration
+func (c AppConfig) clone() (AppConfig, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return AppConfig{}, err
+	}
+	var cfg AppConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return AppConfig{}, err
+	}
+	if cfg.Connections == nil {
+		cfg.Connections = make([]ConnectionConfig, 0)
+	}
+	return cfg, nil
+}
+
+// migrateLegacyAssets moves scripts and templates from their old locations into the library.
+// Old locations: the config file, ~/.godbmodeler/scripts/imported and the
+// scripts/imported and templates/imported directories relative to the working directory.
+// The old files are left in place; the config file entries are removed.
+func (s *Storage) migrateLegacyAssets() error {
+	marker := filepath.Join(s.library.Dir(), libraryMigratedMarker)
+	if _, err := os.Stat(marker); err == nil {
+		return nil
+	}
+
+	cfg := s.snapshot()
+	sources := []legacyAssetSource{
+		{kind: AssetScript, contents: cfg.Scripts, origin: s.configFile},
+		{kind: AssetTemplate, contents: cfg.Templates, origin: s.configFile},
+		{kind: AssetScript, dir: filepath.Join(s.configDir, "scripts", "imported"), ext: ".js"},
+		{kind: AssetScript, dir: filepath.Join("scripts", "imported"), ext: ".js"},
+		{kind: AssetTemplate, dir: filepath.Join("templates", "imported"), ext: ".tpl"},
+	}
+	for _, source := range sources {
+		if source.dir != "" {
+			if abs, err := filepath.Abs(source.dir); err == nil {
+				source.dir = abs
+			}
+			source.origin = source.dir
+		}
+		if _, err := s.library.importLegacy(source); err != nil {
+			return err
+		}
+	}
+
+	if cfg.Scripts != nil || cfg.Templates != nil {
+		err := s.update(func(cfg *AppConfig) error {
+			cfg.Scripts = nil
+			cfg.Templates = nil
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := os.MkdirAll(s.library.Dir(), 0755); err != nil {
 		return err
 	}
+	return os.WriteFile(marker, []byte("legacy scripts and templates migrated\n"), 0644)
+}
+
+// seedDefaultAssets adds the built-in scripts and templates to the library when missing
+func (s *Storage) seedDefaultAssets() error {
+	return s.library.SeedDefaults()
+}
+
+// DefaultAssets returns the built-in scripts and templates.
+// It is the only definition of their content; the generator reads it from here.
+func DefaultAssets() []Asset {
+	defaults := []Asset{
+		{AssetMeta: AssetMeta{Name: "default", Kind: AssetTemplate, Description: "Default TypeScript interface template"}, Content: DefaultTemplate()},
+		{AssetMeta: AssetMeta{Name: "query", Kind: AssetTemplate, Description: "Result interface and parameter tuple for a saved query"}, Content: DefaultQueryTemplate()},
+		{AssetMeta: AssetMeta{Name: "routine", Kind: AssetTemplate, Description: "Typed wrapper for a stored procedure or function"}, Content: DefaultRoutineTemplate()},
+		{AssetMeta: AssetMeta{Name: "camelCase", Kind: AssetScript, Description: "Convert field names to camelCase"}, Content: DefaultCamelCaseScript()},
+		{AssetMeta: AssetMeta{Name: "addHeader", Kind: AssetScript, Description: "Add a header comment"}, Content: DefaultHeaderScript()},
+		{AssetMeta: AssetMeta{Name: "formatCode", Kind: AssetScript, Description: "Normalize indentation and blank lines"}, Content: DefaultFormatScript()},
+		{AssetMeta: AssetMeta{Name: "addImports", Kind: AssetScript, Description: "Add import statements for used types"}, Content: DefaultImportScript()},
+	}
+	for i := range defaults {
+		defaults[i].Author = "GoDBModeler"
+		defaults[i].Language = "TypeScript"
+		defaults[i].Version = "1.0.0"
+	}
+	return defaults
+}
+
+// SeedDefaults adds the built-in scripts and templates that are missing from the library
+func (l *Library) SeedDefaults() error {
+	for _, asset := range DefaultAssets() {
+		if l.Exists(asset.Kind, asset.Name) {
+			// Upgrade the default template only when the user never edited it
+			existing, err := l.Get(asset.Kind, asset.Name)
+			if err == nil && asset.Kind == AssetTemplate && existing.Content == previousDefaultTemplate {
+				existing.Content = asset.Content
+				if err := l.Save(*existing, "built-in: readonly fields for views"); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if err := l.Save(asset, "built-in"); err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
 
+// Library returns the script and template library
+func (s *Storage) Library() *Library {
+	return s.library
+}
+
+// Vault returns the credential vault
+func (s *Storage) Vault() *Vault {
+	return s.vault
+}
+
+// HasLegacyPasswords reports whether any connection password is still
+// encrypted with the key that older versions compiled into the binary
+func (s *Storage) HasLegacyPasswords() bool {
+	for _, conn := range s.snapshot().Connections {
+		if isLegacyEncrypted(conn.Password) {
+			return true
+		}
+	}
+	return false
+}
+
+// InitializeVault sets the master password for the first time and migrates existing passwords
+func (s *Storage) InitializeVault(masterPassword string) error {
+	if err := s.vault.Initialize(masterPassword); err != nil {
+		return err
+	}
+	return s.migrateLegacyPasswords()
+}
+
+// UnlockVault unlocks the vault for this session and migrates any remaining legacy passwords
+func (s *Storage) UnlockVault(masterPassword string) error {
+	if err := s.vault.Unlock(masterPassword); err != nil {
+		return err
+	}
+	return s.migrateLegacyPasswords()
+}
+
+// ChangeMasterPassword changes the master password of the vault.
+// Connection passwords do not need to be re-encrypted.
+func (s *Storage) ChangeMasterPassword(oldPassword, newPassword string) error {
+	return s.vault.ChangePassword(oldPassword, newPassword)
+}
+
+// migrateLegacyPasswords re-encrypts passwords from the legacy key with the vault key
+func (s *Storage) migrateLegacyPasswords() error {
+	if !s.HasLegacyPasswords() {
+		return nil
+	}
+
+	return s.update(func(cfg *AppConfig) error {
+		for i, conn := range cfg.Connections {
+			if !isLegacyEncrypted(conn.Password) {
+				continue
+			}
+
+			decrypted, err := decryptLegacyPassword(conn.Password)
+			if err != nil {
+				return fmt.Errorf("failed to migrate password of connection '%s': %v", conn.Name, err)
+			}
+			encrypted, err := s.vault.Encrypt(decrypted)
+			if err != nil {
+				return err
+			}
+			cfg.Connections[i].Password = encrypted
+		}
+		return nil
+	})
+}
+
 // GetConnections gets all connection configurations
 func (s *Storage) GetConnections() []ConnectionConfig {
-	return s.config.Connections
+	return s.snapshot().Connections
 }
 
-// AddConnection adds a connection configuration
-func (s *Storage) AddConnection(config ConnectionConfig) error {
-	for _, conn := range s.config.Connections {
-		if conn.Name == config.Name {
-			return fmt.Errorf("connection name '%s' already exists", config.Name)
+// FindConnection returns the stored connection configuration with the given name
+func (s *Storage) FindConnection(name string) (ConnectionConfig, bool) {
+	for _, conn := range s.GetConnections() {
+		if conn.Name == name {
+			return conn, true
 		}
 	}
+	return ConnectionConfig{}, false
+}
 
-	encryptedConfig, err := EncryptConnectionPassword(config)
+// AddConnection adds a connection configuration
+func (s *Storage) AddConnection(config ConnectionConfig) error {
+	encryptedConfig, err := s.EncryptConnectionPassword(config)
 	if err != nil {
 		return err
 	}
 
-	s.config.Connections = append(s.config.Connections, encryptedConfig)
-	return s.Save()
+	return s.update(func(cfg *AppConfig) error {
+		for _, conn := range cfg.Connections {
+			if conn.Name == config.Name {
+				return fmt.Errorf("connection name '%s' already exists", config.Name)
+			}
+		}
+
+		cfg.Connections = append(cfg.Connections, encryptedConfig)
+		return nil
+	})
+}
+
+// SetGeneratorDefaults remembers the generator settings for a connection, nil clears them
+func (s *Storage) SetGeneratorDefaults(name string, defaults *GeneratorDefaults) error {
+	return s.update(func(cfg *AppConfig) error {
+		for i, conn := range cfg.Connections {
+			if conn.Name == name {
+				cfg.Connections[i].GeneratorDefaults = defaults
+				return nil
+			}
+		}
+
+		return fmt.Errorf("connection '%s' does not exist", name)
+	})
+}
+
+// AvailableConnectionName returns the name MergeConnection uses for name with ConflictRename
+func (s *Storage) AvailableConnectionName(name string) string {
+	return uniqueConnectionName(s.snapshot().Connections, name)
+}
+
+// uniqueConnectionName returns name, or name with the lowest free numeric suffix
+func uniqueConnectionName(connections []ConnectionConfig, name string) string {
+	taken := make(map[string]bool, len(connections))
+	for _, conn := range connections {
+		taken[conn.Name] = true
+	}
+
+	candidate := name
+	for i := 2; taken[candidate]; i++ {
+		candidate = fmt.Sprintf("%s (%d)", name, i)
+	}
+	return candidate
 }
 
 // UpdateConnection updates a connection configuration
 func (s *Storage) UpdateConnection(config ConnectionConfig) error {
-	for i, conn := range s.config.Connections {
-		if conn.Name == config.Name {
-			encryptedConfig, err := EncryptConnectionPassword(config)
-			if err != nil {
-				return err
-			}
+	encryptedConfig, err := s.EncryptConnectionPassword(config)
+	if err != nil {
+		return err
+	}
 
-			s.config.Connections[i] = encryptedConfig
-			return s.Save()
+	return s.update(func(cfg *AppConfig) error {
+		for i, conn := range cfg.Connections {
+			if conn.Name == config.Name {
+				cfg.Connections[i] = encryptedConfig
+				return nil
+			}
 		}
-	}
 
-	return fmt.Errorf("connection '%s' does not exist", config.Name)
+		return fmt.Errorf("connection '%s' does not exist", config.Name)
+	})
 }
 
 // DeleteConnection deletes a connection configuration
 func (s *Storage) DeleteConnection(name string) error {
-	for i, conn := range s.config.Connections {
-		if conn.Name == name {
-			s.config.Connections = append(s.config.Connections[:i], s.config.Connections[i+1:]...)
-			return s.Save()
+	return s.update(func(cfg *AppConfig) error {
+		for i, conn := range cfg.Connections {
+			if conn.Name == name {
+				cfg.Connections = append(cfg.Connections[:i], cfg.Connections[i+1:]...)
+				return nil
+			}
+		}
+
+		return fmt.Errorf("connection '%s' does not exist", name)
+	})
+}
+
+// GetQueries gets all saved queries
+func (s *Storage) GetQueries() []SavedQuery {
+	return s.snapshot().Queries
+}
+
+// FindQuery returns the saved query with the given name
+func (s *Storage) FindQuery(name string) (SavedQuery, bool) {
+	for _, query := range s.GetQueries() {
+		if query.Name == name {
+			return query, true
 		}
 	}
+	return SavedQuery{}, false
+}
+
+// SaveQuery adds a saved query, or replaces the one with the same name
+func (s *Storage) SaveQuery(query SavedQuery) error {
+	if strings.TrimSpace(query.Name) == "" {
+		return fmt.Errorf("query name is required")
+	}
+	if strings.TrimSpace(query.SQL) == "" {
+		return fmt.Errorf("query '%s' has no SQL", query.Name)
+	}
+
+	return s.update(func(cfg *AppConfig) error {
+		for i, existing := range cfg.Queries {
+			if existing.Name == query.Name {
+				cfg.Queries[i] = query
+				return nil
+			}
+		}
 
-	return fmt.Errorf("connection '%s' does not exist", name)
+		cfg.Queries = append(cfg.Queries, query)
+		return nil
+	})
+}
+
+// DeleteQuery deletes a saved query
+func (s *Storage) DeleteQuery(name string) error {
+	return s.update(func(cfg *AppConfig) error {
+		for i, query := range cfg.Queries {
+			if query.Name == name {
+				cfg.Queries = append(cfg.Queries[:i], cfg.Queries[i+1:]...)
+				return nil
+			}
+		}
+
+		return fmt.Errorf("query '%s' does not exist", name)
+	})
+}
+
+// GetJSONShapes returns the saved JSON shapes of a table, keyed by column
+func (s *Storage) GetJSONShapes(connection, database, table string) map[string]JSONShape {
+	shapes := make(map[string]JSONShape)
+	for _, shape := range s.snapshot().JSONShapes {
+		if shape.matches(connection, database, table) {
+			shapes[shape.Column] = shape
+		}
+	}
+	return shapes
+}
+
+// SaveJSONShape adds a JSON shape, or replaces the one saved for the same column
+func (s *Storage) SaveJSONShape(shape JSONShape) error {
+	if shape.Table == "" || shape.Column == "" {
+		return fmt.Errorf("table and column are required")
+	}
+	if strings.TrimSpace(shape.TypeName) == "" {
+		return fmt.Errorf("type name of column '%s' is required", shape.Column)
+	}
+
+	return s.update(func(cfg *AppConfig) error {
+		for i, existing := range cfg.JSONShapes {
+			if existing.matches(shape.Connection, shape.Database, shape.Table) && existing.Column == shape.Column {
+				cfg.JSONShapes[i] = shape
+				return nil
+			}
+		}
+
+		cfg.JSONShapes = append(cfg.JSONShapes, shape)
+		return nil
+	})
+}
+
+// DeleteJSONShape deletes the JSON shape saved for a column
+func (s *Storage) DeleteJSONShape(connection, database, table, column string) error {
+	return s.update(func(cfg *AppConfig) error {
+		for i, shape := range cfg.JSONShapes {
+			if shape.matches(connection, database, table) && shape.Column == column {
+				cfg.JSONShapes = append(cfg.JSONShapes[:i], cfg.JSONShapes[i+1:]...)
+				return nil
+			}
+		}
+
+		return fmt.Errorf("no JSON shape is saved for column '%s'", column)
+	})
 }
 
 // GetTemplates gets all templates
 func (s *Storage) GetTemplates() map[string]string {
-	return s.config.Templates
+	return s.library.Contents(AssetTemplate)
 }
 
 // GetTemplate gets a template
 func (s *Storage) GetTemplate(name string) (string, error) {
-	if template, ok := s.config.Templates[name]; ok {
-		return template, nil
+	asset, err := s.library.Get(AssetTemplate, name)
+	if err != nil {
+		return "", fmt.Errorf("template '%s' does not exist", name)
 	}
 
-	return "", fmt.Errorf("template '%s' does not exist", name)
+	return asset.Content, nil
 }
 
-// SetTemplate sets a template
+// SetTemplate sets a template, creating a new revision when the content changed
 func (s *Storage) SetTemplate(name, template string) error {
-	s.config.Templates[name] = template
-	return s.Save()
+	return s.saveAssetContent(AssetTemplate, name, template)
 }
 
 // DeleteTemplate deletes a template
@@ -179,93 +760,76 @@ func (s *Storage) DeleteTemplate(name string) error {
 		return fmt.Errorf("cannot delete default template")
 	}
 
-	if _, ok := s.config.Templates[name]; !ok {
+	if !s.library.Exists(AssetTemplate, name) {
 		return fmt.Errorf("template '%s' does not exist", name)
 	}
 
-	delete(s.config.Templates, name)
-	return s.Save()
+	return s.library.Delete(AssetTemplate, name)
 }
 
 // GetScripts gets all scripts
 func (s *Storage) GetScripts() map[string]string {
-	// 合并存储的脚本和导入的脚本
-	allScripts := make(map[string]string)
-
-	// 添加存储的脚本
-	for name, script := range s.config.Scripts {
-		allScripts[name] = script
-	}
-
-	// 添加导入的脚本
-	importedScripts := s.GetImportedScripts()
-	for name, script := range importedScripts {
-		allScripts[name] = script
-	}
-
-	return allScripts
-}
-
-// GetImportedScripts gets scripts from imported directory
-func (s *Storage) GetImportedScripts() map[string]string {
-	importedScripts := make(map[string]string)
-
-	// 创建导入目录
-	importDir := filepath.Join(s.configDir, "scripts", "imported")
-	if err := os.MkdirAll(importDir, 0755); err != nil {
-		return importedScripts
-	}
-
-	// 读取所有 .js 文件
-	files, err := os.ReadDir(importDir)
-	if err != nil {
-		return importedScripts
-	}
-
-	for _, file := range files {
-		if !file.IsDir() && filepath.Ext(file.Name()) == ".js" {
-			filePath := filepath.Join(importDir, file.Name())
-			content, err := os.ReadFile(filePath)
-			if err == nil {
-				scriptName := file.Name()[:len(file.Name())-3] // 移除 .js 扩展名
-				importedScripts[scriptName] = string(content)
-			}
-		}
-	}
-
-	return importedScripts
+	return s.library.Contents(AssetScript)
 }
 
 // GetScript gets a script
 func (s *Storage) GetScript(name string) (string, error) {
-	if script, ok := s.config.Scripts[name]; ok {
-		return script, nil
+	asset, err := s.library.Get(AssetScript, name)
+	if err != nil {
+		return "", fmt.Errorf("script '%s' does not exist", name)
 	}
 
-	return "", fmt.Errorf("script '%s' does not exist", name)
+	return asset.Content, nil
 }
 
-// SetScript sets a script
+// SetScript sets a script, creating a new revision when the content changed
 func (s *Storage) SetScript(name, script string) error {
-	s.config.Scripts[name] = script
-	return s.Save()
+	return s.saveAssetContent(AssetScript, name, script)
 }
 
 // DeleteScript deletes a script
 func (s *Storage) DeleteScript(name string) error {
-	defaultScripts := []string{"camelCase", "addHeader", "formatCode", "addImports"}
-	for _, defaultName := range defaultScripts {
+	for _, defaultName := range defaultScriptNames {
 		if name == defaultName {
 			return fmt.Errorf("cannot delete default script")
 		}
 	}
 
-	if _, ok := s.config.Scripts[name]; !ok {
+	if !s.library.Exists(AssetScript, name) {
 		return fmt.Errorf("script '%s' does not exist", name)
 	}
 
-	delete(s.config.Scripts, name)
-	return s.Save()
+	return s.library.Delete(AssetScript, name)
+}
+
+// saveAssetContent saves new content for an asset, keeping its existing metadata
+func (s *Storage) saveAssetContent(kind AssetKind, name, content string) error {
+	asset := Asset{AssetMeta: AssetMeta{Name: name, Kind: kind, Language: "TypeScript"}}
+	if existing, err := s.library.Get(kind, name); err == nil {
+		asset = *existing
+	}
+	asset.Content = content
+	return s.library.Save(asset, "")
+}
+
+// GetScriptParamValues gets the last parameter values used for a script
+func (s *Storage) GetScriptParamValues(name string) map[string]string {
+	values := make(map[string]string)
+	for key, value := range s.snapshot().ScriptParams[name] {
+		values[key] = value
+	}
+	return values
+}
+
+// SetScriptParamValues remembers the parameter values used for a script
+func (s *Storage) SetScriptParamValues(name string, values map[string]string) error {
+	return s.update(func(cfg *AppConfig) error {
+		if cfg.ScriptParams == nil {
+			cfg.ScriptParams = make(map[string]map[string]string)
+		}
+		cfg.ScriptParams[name] = values
+		return nil
+	})
 }
 
 // GetConfigDir returns the configuration directory path
@@ -273,9 +837,65 @@ func (s *Storage) GetConfigDir() string {
 	return s.configDir
 }
 
+// GetPluginDir returns the directory that holds generator plugins
+func (s *Storage) GetPluginDir() string {
+	return filepath.Join(s.configDir, "plugins")
+}
+
+// previousDefaultTemplate is the default template before view fields became readonly
+const previousDefaultTemplate = "export interface {{.TableName}} {\n{{range .Fields}}  /** {{.Comment}} */\n  {{.Name}}: {{.TsType}};\n{{end}}\n}\n"
+
 // DefaultTemplate returns the default TypeScript template
 func DefaultTemplate() string {
-	return "export interface {{.TableName}} {\n{{range .Fields}}  /** {{.Comment}} */\n  {{.Name}}: {{.TsType}};\n{{end}}\n}\n"
+	return "export interface {{.TableName}} {\n{{range .Fields}}  /** {{.Comment}} */\n  {{if $.ReadOnly}}readonly {{end}}{{.Name}}: {{.TsType}};\n{{end}}\n}\n"
+}
+
+// DefaultQueryTemplate returns the default template for saved queries
+func DefaultQueryTemplate() string {
+	return `export interface {{.TableName}} {
+{{range .Fields}}  {{.Name}}: {{.TsType}}{{if .Nullable}} | null{{end}};
+{{end}}}
+
+export type {{.TableName}}Params = [{{range $i, $param := .Params}}{{if $i}}, {{end}}{{$param.Name}}: {{$param.TsType}}{{end}}];
+
+export const {{.TableName}}SQL = {{printf "%q" .Definition}};
+`
+}
+
+// DefaultRoutineTemplate returns the default template for stored procedure and function wrappers
+func DefaultRoutineTemplate() string {
+	return `{{if .Comment}}/** {{.Comment}} */
+{{end}}export interface {{.TypeName}}Params {
+{{range .Params}}  {{.Name}}: {{.TsType}};
+{{end}}}
+{{if .Results}}
+export interface {{.TypeName}}Result {
+{{range .Results}}  {{.Name}}: {{.TsType}};
+{{end}}}
+{{end}}
+/** Database client that runs SQL with positional values and resolves to the result rows */
+export interface Queryable {
+  query<T = any>(sql: string, values?: unknown[]): Promise<T[]>;
+}
+
+/** Calls {{.Signature}} */
+export async function {{.FunctionName}}(db: Queryable, params: {{.TypeName}}Params): Promise<{{.ResultType}}> {
+{{- if eq .Returns "void"}}
+  await db.query({{printf "%q" .CallSQL}}, [{{range $i, $arg := .CallArgs}}{{if $i}}, {{end}}params.{{$arg}}{{end}}]);
+{{- else}}
+  const rows = await db.query<{{.RowType}}>({{printf "%q" .CallSQL}}, [{{range $i, $arg := .CallArgs}}{{if $i}}, {{end}}params.{{$arg}}{{end}}]);
+{{- if eq .Returns "rows"}}
+  return rows;
+{{- else if eq .Returns "row"}}
+  return rows[0];
+{{- else if eq .Returns "values"}}
+  return rows.map((row) => row.result);
+{{- else}}
+  return rows[0].result;
+{{- end}}
+{{- end}}
+}
+`
 }
 
 // DefaultCamelCaseScript returns the default camel case conversion script
@@ -310,14 +930,17 @@ output = result;`
 // DefaultHeaderScript returns the default header comment script
 func DefaultHeaderScript() string {
 	return "// Header comment script\n" +
+		"// @param note string \"Do not edit this file manually\" Header note\n" +
+		"// @param showDate bool true Show generation time\n" +
 		"// input is already parsed JSON object\n\n" +
 		"// Generate header comment\n" +
+		"const dateLine = params.showDate ? ` * Generated at: ${new Date().toLocaleString()}\\n` : '';\n" +
 		"const header = `/**\n" +
 		" * ${input.tableName} model\n" +
-		" * Generated at: ${new Date().toLocaleString()}\n" +
+		"${dateLine}" +
 		" * Table: ${input.tableName}\n" +
 		" * Field count: ${input.fields.length}\n" +
-		" * Do not edit this file manually\n" +
+		" * ${params.note}\n" +
 		" */\n\n`;\n\n" +
 		"// Get current generated code\n" +
 		"let result = tsCode;\n\n" +
@@ -357,6 +980,8 @@ output = result;`
 // DefaultImportScript returns the default type import script
 func DefaultImportScript() string {
 	return "// Type import script\n" +
+		"// @param importPath string ./types Module to import types from\n" +
+		"// @param commented bool true Emit imports as comments\n" +
 		"// input is already parsed JSON object\n\n" +
 		"// Get current generated code\n" +
 		"let result = tsCode;\n\n" +
@@ -371,9 +996,10 @@ func DefaultImportScript() string {
 		"}\n\n" +
 		"// If there are types to import, add import statements\n" +
 		"if (importTypes.size > 0) {\n" +
+		"    const prefix = params.commented ? '// ' : '';\n" +
 		"    let imports = '// Auto-generated import statements\\n';\n" +
 		"    for (const type of importTypes) {\n" +
-		"        imports += '// import { ' + type + ' } from \\'./types\\'\\n';\n" +
+		"        imports += prefix + 'import { ' + type + ' } from \\'' + params.importPath + '\\'\\n';\n" +
 		"    }\n" +
 		"    imports += '\\n';\n\n" +
 		"    // Add import statements at the beginning of the code\n" +
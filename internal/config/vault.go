@@ -0,0 +1,320 @@
+package config
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"golang.org/x/crypto/scrypt"
+	"os"
+	"strings"
+	"sync"
+)
+
+const (
+	// vaultVersion 是保险库文件格式版本
+	vaultVersion = 1
+	// vaultCipherPrefix 是保险库加密的密码前缀，用于区分旧版本加密的密码
+	vaultCipherPrefix = "vault:v1:"
+	// vaultKeyAAD 是加密数据密钥时使用的附加数据
+	vaultKeyAAD = "godbmodeler-vault-key"
+
+	// 新建保险库时使用的scrypt参数
+	defaultScryptN = 1 << 15
+	defaultScryptR = 8
+	defaultScryptP = 1
+
+	// 读取保险库文件时接受的scrypt参数范围，避免损坏或伪造的文件使解锁时占用大量内存或长时间计算
+	minScryptN      = 1 << 14
+	maxScryptN      = 1 << 20
+	maxScryptR      = 16
+	maxScryptP      = 16
+	maxScryptMemory = 1 << 30 // scrypt 需要 128*N*R 字节内存
+)
+
+var (
+	// ErrVaultLocked 表示保险库尚未解锁
+	ErrVaultLocked = errors.New("保险库未解锁，请先输入主密码")
+	// ErrVaultNotInitialized 表示尚未设置主密码
+	ErrVaultNotInitialized = errors.New("尚未设置主密码")
+	// ErrWrongMasterPassword 表示主密码错误
+	ErrWrongMasterPassword = errors.New("主密码错误")
+)
+
+// vaultFile 表示保存在磁盘上的保险库文件
+// 连接密码使用随机生成的数据密钥加密，数据密钥再由主密码派生的密钥加密，
+// 因此修改主密码时只需要重新加密数据密钥
+type vaultFile struct {
+	Version    int    `json:"version"`
+	KDF        string `json:"kdf"`
+	Salt       string `json:"salt"`
+	N          int    `json:"n"`
+	R          int    `json:"r"`
+	P          int    `json:"p"`
+	WrappedKey string `json:"wrappedKey"`
+}
+
+// Vault 表示由主密码保护的凭据保险库
+// 每个会话解锁一次，解锁后数据密钥只保存在内存中
+type Vault struct {
+	mu      sync.Mutex
+	path    string
+	dataKey []byte
+}
+
+// NewVault 创建一个新的保险库
+func NewVault(path string) *Vault {
+	return &Vault{path: path}
+}
+
+// IsInitialized 判断是否已经设置了主密码
+func (v *Vault) IsInitialized() bool {
+	_, err := os.Stat(v.path)
+	return err == nil
+}
+
+// IsUnlocked 判断保险库是否已解锁
+func (v *Vault) IsUnlocked() bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.dataKey != nil
+}
+
+// Initialize 设置主密码并创建保险库，保险库会保持解锁状态
+func (v *Vault) Initialize(masterPassword string) error {
+	if v.IsInitialized() {
+		return errors.New("主密码已设置")
+	}
+	if masterPassword == "" {
+		return errors.New("主密码不能为空")
+	}
+
+	dataKey := make([]byte, 32)
+	if _, err := rand.Read(dataKey); err != nil {
+		return err
+	}
+
+	if err := v.writeFile(masterPassword, dataKey); err != nil {
+		return err
+	}
+
+	v.mu.Lock()
+	v.dataKey = dataKey
+	v.mu.Unlock()
+	return nil
+}
+
+// Unlock 使用主密码解锁保险库
+func (v *Vault) Unlock(masterPassword string) error {
+	file, err := v.readFile()
+	if err != nil {
+		return err
+	}
+
+	dataKey, err := file.unwrap(masterPassword)
+	if err != nil {
+		return err
+	}
+
+	v.mu.Lock()
+	v.dataKey = dataKey
+	v.mu.Unlock()
+	return nil
+}
+
+// Lock 锁定保险库，清除内存中的数据密钥
+func (v *Vault) Lock() {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	for i := range v.dataKey {
+		v.dataKey[i] = 0
+	}
+	v.dataKey = nil
+}
+
+// ChangePassword 修改主密码，已加密的连接密码不需要重新加密
+func (v *Vault) ChangePassword(oldPassword, newPassword string) error {
+	if newPassword == "" {
+		return errors.New("主密码不能为空")
+	}
+
+	file, err := v.readFile()
+	if err != nil {
+		return err
+	}
+	dataKey, err := file.unwrap(oldPassword)
+	if err != nil {
+		return err
+	}
+
+	// 使用新的盐值重新加密数据密钥
+	if err := v.writeFile(newPassword, dataKey); err != nil {
+		return err
+	}
+
+	v.mu.Lock()
+	v.dataKey = dataKey
+	v.mu.Unlock()
+	return nil
+}
+
+// Encrypt 加密连接密码
+func (v *Vault) Encrypt(plaintext string) (string, error) {
+	if plaintext == "" {
+		return "", nil
+	}
+
+	key, err := v.key()
+	if err != nil {
+		return "", err
+	}
+
+	ciphertext, err := sealAESGCM(key, []byte(plaintext), nil)
+	if err != nil {
+		return "", err
+	}
+
+	return vaultCipherPrefix + base64.StdEncoding.EncodeToString(ciphertext), nil
+}
+
+// Decrypt 解密连接密码
+func (v *Vault) Decrypt(encrypted string) (string, error) {
+	if encrypted == "" {
+		return "", nil
+	}
+	if !strings.HasPrefix(encrypted, vaultCipherPrefix) {
+		return "", errors.New("密码不是由保险库加密的")
+	}
+
+	key, err := v.key()
+	if err != nil {
+		return "", err
+	}
+
+	ciphertext, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(encrypted, vaultCipherPrefix))
+	if err != nil {
+		return "", err
+	}
+
+	plaintext, err := openAESGCM(key, ciphertext, nil)
+	if err != nil {
+		return "", fmt.Errorf("解密密码失败: %v", err)
+	}
+
+	return string(plaintext), nil
+}
+
+// key 返回数据密钥，保险库未解锁时返回错误
+func (v *Vault) key() ([]byte, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.dataKey == nil {
+		if !v.IsInitialized() {
+			return nil, ErrVaultNotInitialized
+		}
+		return nil, ErrVaultLocked
+	}
+	return v.dataKey, nil
+}
+
+// readFile 读取保险库文件
+func (v *Vault) readFile() (*vaultFile, error) {
+	data, err := os.ReadFile(v.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrVaultNotInitialized
+		}
+		return nil, err
+	}
+
+	var file vaultFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("解析保险库文件失败: %v", err)
+	}
+	if file.Version != vaultVersion || file.KDF != "scrypt" {
+		return nil, fmt.Errorf("不支持的保险库格式: 版本 %d, %s", file.Version, file.KDF)
+	}
+	if err := file.checkParams(); err != nil {
+		return nil, err
+	}
+	return &file, nil
+}
+
+// checkParams 检查文件中的scrypt参数，N 必须是范围内的2的幂
+func (f *vaultFile) checkParams() error {
+	switch {
+	case f.N < minScryptN || f.N > maxScryptN || f.N&(f.N-1) != 0:
+		return fmt.Errorf("保险库参数无效: N=%d，应为 %d 到 %d 之间的2的幂", f.N, minScryptN, maxScryptN)
+	case f.R < 1 || f.R > maxScryptR:
+		return fmt.Errorf("保险库参数无效: r=%d，应为 1 到 %d", f.R, maxScryptR)
+	case f.P < 1 || f.P > maxScryptP:
+		return fmt.Errorf("保险库参数无效: p=%d，应为 1 到 %d", f.P, maxScryptP)
+	case 128*int64(f.N)*int64(f.R) > maxScryptMemory:
+		return fmt.Errorf("保险库参数无效: N=%d, r=%d 需要的内存超过 %d MiB", f.N, f.R, maxScryptMemory>>20)
+	}
+	return nil
+}
+
+// writeFile 使用主密码派生的密钥加密数据密钥并写入保险库文件
+func (v *Vault) writeFile(masterPassword string, dataKey []byte) error {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+
+	file := &vaultFile{
+		Version: vaultVersion,
+		KDF:     "scrypt",
+		Salt:    base64.StdEncoding.EncodeToString(salt),
+		N:       defaultScryptN,
+		R:       defaultScryptR,
+		P:       defaultScryptP,
+	}
+
+	kek, err := file.deriveKey(masterPassword)
+	if err != nil {
+		return err
+	}
+	wrapped, err := sealAESGCM(kek, dataKey, []byte(vaultKeyAAD))
+	if err != nil {
+		return err
+	}
+	file.WrappedKey = base64.StdEncoding.EncodeToString(wrapped)
+
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// 先写入临时文件再重命名，避免写入中断导致保险库损坏
+	return writeFileAtomic(v.path, data, 0600)
+}
+
+// deriveKey 使用文件中保存的scrypt参数从主密码派生密钥
+func (f *vaultFile) deriveKey(masterPassword string) ([]byte, error) {
+	salt, err := base64.StdEncoding.DecodeString(f.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("保险库盐值无效: %v", err)
+	}
+	return scrypt.Key([]byte(masterPassword), salt, f.N, f.R, f.P, 32)
+}
+
+// unwrap 使用主密码解密数据密钥
+func (f *vaultFile) unwrap(masterPassword string) ([]byte, error) {
+	kek, err := f.deriveKey(masterPassword)
+	if err != nil {
+		return nil, err
+	}
+
+	wrapped, err := base64.StdEncoding.DecodeString(f.WrappedKey)
+	if err != nil {
+		return nil, fmt.Errorf("保险库密钥无效: %v", err)
+	}
+
+	dataKey, err := openAESGCM(kek, wrapped, []byte(vaultKeyAAD))
+	if err != nil {
+		return nil, ErrWrongMasterPassword
+	}
+	return dataKey, nil
+}
@@ -1,22 +1,33 @@
 package connector
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
+	"time"
+)
+
+// 默认超时时间，ConnectionConfig 中没有设置时使用
+const (
+	DefaultConnectTimeout = 15 * time.Second
+	DefaultQueryTimeout   = 60 * time.Second
 )
 
 // Connector 定义数据库连接器接口
+// 所有方法都接受 ctx，取消 ctx 会中止正在进行的连接或查询；
+// 此外每次调用还受 ConnectionConfig 中的连接或查询超时限制
 type Connector interface {
 	// Connect 连接到数据库
-	Connect() (*sql.DB, error)
+	Connect(ctx context.Context) (*sql.DB, error)
 
 	// GetDatabases 获取所有数据库
-	GetDatabases() ([]string, error)
+	GetDatabases(ctx context.Context) ([]string, error)
 
-	// GetTables 获取指定数据库中的所有表
-	GetTables(database string) ([]string, error)
+	// GetTables 获取指定数据库中的所有表和视图，以及它们的对象类型
+	GetTables(ctx context.Context, database string) ([]TableInfo, error)
 
 	// GetTableMetadata 获取表的元数据信息
-	GetTableMetadata(database, table string) (*TableMetadata, error)
+	GetTableMetadata(ctx context.Context, database, table string) (*TableMetadata, error)
 
 	// Close 关闭数据库连接
 	Close() error
@@ -24,19 +35,70 @@ type Connector interface {
 
 // ConnectionConfig 表示数据库连接配置
 type ConnectionConfig struct {
-	Type     string // 数据库类型：MySQL, PostgreSQL, SQLite
+	Type     string // 数据库类型，对应 dialect 包中注册的名称
 	Host     string // 主机名或IP地址
 	Port     string // 端口号
 	Username string // 用户名
 	Password string // 密码
 	Database string // 数据库名（可选）
+
+	// SSH 不为空时通过SSH跳板机连接数据库，仅支持MySQL和PostgreSQL
+	SSH *SSHConfig
+	// TLS 为空时不使用TLS
+	TLS *TLSConfig
+
+	ConnectTimeout time.Duration // 连接超时，为0时使用 DefaultConnectTimeout
+	QueryTimeout   time.Duration // 每次查询的超时，为0时使用 DefaultQueryTimeout
+}
+
+// ObjectKind 表示表列表中对象的类型
+type ObjectKind string
+
+// 表列表中的对象类型，不是所有数据库都支持所有类型
+const (
+	KindTable            ObjectKind = "table"             // 普通表
+	KindView             ObjectKind = "view"              // 视图
+	KindMaterializedView ObjectKind = "materialized view" // 物化视图，SQL Server 中为索引视图
+	KindForeignTable     ObjectKind = "foreign table"     // 外部表
+	KindPartitionedTable ObjectKind = "partitioned table" // 分区表
+
+	// KindQuery 是 DescribeQuery 分析的查询结果，不出现在表列表中
+	KindQuery ObjectKind = "query"
+)
+
+// ObjectKinds 是表列表中所有的对象类型，按在界面中显示的顺序排列
+var ObjectKinds = []ObjectKind{KindTable, KindView, KindMaterializedView, KindForeignTable, KindPartitionedTable}
+
+// ReadOnly 判断该类型的对象是否只读，视图和物化视图的结构只能用于读取数据
+func (k ObjectKind) ReadOnly() bool {
+	return k == KindView || k == KindMaterializedView
+}
+
+// TableInfo 表示表列表中的一个表或视图
+type TableInfo struct {
+	Name string     // 名称，与 GetTableMetadata 使用的名称一致
+	Kind ObjectKind // 对象类型
+}
+
+// TableNames 返回表列表中的名称
+func TableNames(tables []TableInfo) []string {
+	names := make([]string, 0, len(tables))
+	for _, table := range tables {
+		names = append(names, table.Name)
+	}
+	return names
 }
 
 // TableMetadata 表示表的元数据
 type TableMetadata struct {
-	Name    string      // 表名
-	Fields  []FieldInfo // 字段信息
-	Indexes []IndexInfo // 索引信息
+	Name        string           // 表名
+	Schema      string           // 表所在的架构，数据库没有架构时为空
+	Kind        ObjectKind       // 对象类型
+	Definition  string           // 视图的定义或分析的查询语句；不同数据库的视图定义可能是查询语句或完整的 CREATE 语句
+	Fields      []FieldInfo      // 字段信息
+	Params      []FieldInfo      // 查询的参数，按位置排列，只有查询提供；Type 为空表示数据库无法确定参数类型
+	Indexes     []IndexInfo      // 索引信息
+	ForeignKeys []ForeignKeyInfo // 外键信息，不是所有数据库都提供
 }
 
 // FieldInfo 表示字段信息
@@ -47,6 +109,7 @@ type FieldInfo struct {
 	IsNullable bool   // 是否可为空
 	IsPrimary  bool   // 是否为主键
 	IsUnique   bool   // 是否唯一
+	IsIdentity bool   // 是否为自增或标识列，不是所有数据库都提供
 	Default    string // 默认值
 	Comment    string // 注释
 }
@@ -58,16 +121,73 @@ type IndexInfo struct {
 	Columns []string // 包含的列
 }
 
-// NewConnector 根据配置创建对应的数据库连接器
-func NewConnector(config *ConnectionConfig) (Connector, error) {
-	switch config.Type {
-	case "MySQL":
-		return NewMySQLConnector(config), nil
-	case "PostgreSQL":
-		return NewPostgreSQLConnector(config), nil
-	case "SQLite":
-		return NewSQLiteConnector(config), nil
-	default:
-		return nil, nil
+// ForeignKeyInfo 表示外键信息
+type ForeignKeyInfo struct {
+	Name       string   // 外键名
+	Columns    []string // 本表的列
+	RefTable   string   // 引用的表，名称与 GetTables 返回的一致
+	RefColumns []string // 引用表中对应的列
+}
+
+// connectTimeout 返回连接超时时间
+func (c *ConnectionConfig) connectTimeout() time.Duration {
+	if c.ConnectTimeout <= 0 {
+		return DefaultConnectTimeout
+	}
+	return c.ConnectTimeout
+}
+
+// queryTimeout 返回查询超时时间
+func (c *ConnectionConfig) queryTimeout() time.Duration {
+	if c.QueryTimeout <= 0 {
+		return DefaultQueryTimeout
+	}
+	return c.QueryTimeout
+}
+
+// connectContext 返回带有连接超时的上下文
+// 返回的函数需要在方法返回前调用，它会取消上下文，并在超时或取消导致失败时将 *err 替换为说明原因的错误
+func (c *ConnectionConfig) connectContext(ctx context.Context, err *error) (context.Context, func()) {
+	return withTimeout(ctx, c.connectTimeout(), DefaultConnectTimeout, "连接", err)
+}
+
+// queryContext 返回带有查询超时的上下文，返回的函数与 connectContext 相同
+func (c *ConnectionConfig) queryContext(ctx context.Context, err *error) (context.Context, func()) {
+	return withTimeout(ctx, c.queryTimeout(), DefaultQueryTimeout, "查询", err)
+}
+
+// withTimeout 创建带有超时的上下文，timeout 为0时使用 fallback
+func withTimeout(parent context.Context, timeout, fallback time.Duration, action string, err *error) (context.Context, func()) {
+	if timeout <= 0 {
+		timeout = fallback
+	}
+	ctx, cancel := context.WithTimeout(parent, timeout)
+	return ctx, func() {
+		if *err != nil {
+			switch {
+			case parent.Err() != nil:
+				// 调用方取消时保留 context.Canceled，便于界面区分取消和失败
+				*err = parent.Err()
+			case ctx.Err() == context.DeadlineExceeded:
+				*err = fmt.Errorf("%s超时（%s）: %w", action, timeout, context.DeadlineExceeded)
+			}
+		}
+		cancel()
+	}
+}
+
+// pingContext 测试数据库连接，ctx 结束时立即返回
+// 有的驱动在建立连接期间不响应 ctx 取消，此时测试在后台继续，直到驱动自身的超时
+func pingContext(ctx context.Context, db *sql.DB) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- db.PingContext(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
 	}
 }
@@ -0,0 +1,456 @@
+//go:build duckdb && cgo
+
+package connector
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	_ "github.com/marcboeker/go-duckdb"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// duckDBDefaultSchema 是DuckDB的默认架构，其中的表不带架构名显示
+const duckDBDefaultSchema = "main"
+
+// DuckDBConnector 实现DuckDB数据库连接器
+// 与SQLite相同，Host字段是数据库文件路径；其他架构中的表名为 架构.表名
+type DuckDBConnector struct {
+	config *ConnectionConfig
+	db     *sql.DB
+}
+
+// NewDuckDBConnector 创建一个新的DuckDB连接器
+func NewDuckDBConnector(config *ConnectionConfig) *DuckDBConnector {
+	return &DuckDBConnector{
+		config: config,
+	}
+}
+
+// Connect 连接到DuckDB数据库
+func (c *DuckDBConnector) Connect(ctx context.Context) (db *sql.DB, err error) {
+	ctx, done := c.config.connectContext(ctx, &err)
+	defer done()
+
+	// 对于DuckDB，Host字段实际上是文件路径
+	dbPath := c.config.Host
+
+	// 检查文件是否存在，避免DuckDB自动创建空数据库
+	if _, err := os.Stat(dbPath); os.IsNotExist(err) {
+		return nil, fmt.Errorf("DuckDB数据库文件不存在: %s", dbPath)
+	}
+
+	// 以只读方式打开，DuckDB同一时间只允许一个进程写入，只读时可以与其他读取者共享文件
+	dsn, err := duckDBReadOnlyDSN(dbPath)
+	if err != nil {
+		return nil, err
+	}
+	db, err = sql.Open("duckdb", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("连接DuckDB失败: %v", err)
+	}
+
+	// 测试连接
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("DuckDB连接测试失败: %v", err)
+	}
+
+	c.db = db
+	return db, nil
+}
+
+// duckDBReadOnlyDSN 返回以只读模式打开数据库文件的DSN
+// 驱动把第一个 ? 之前的内容原样作为文件路径，只用 url.Parse 读取参数，不会还原转义，因此不能像 SQLite 那样转义路径。
+// 包含 ? 的路径会被截断，包含 # 的路径会丢失只读参数，这些路径直接拒绝
+func duckDBReadOnlyDSN(path string) (string, error) {
+	dsn := path + "?access_mode=read_only"
+	parsed, err := url.Parse(dsn)
+	if strings.Contains(path, "?") || err != nil || parsed.RawQuery != "access_mode=read_only" {
+		return "", fmt.Errorf("DuckDB驱动无法以只读方式打开路径中包含 ?、# 或无效 %% 转义的文件: %s", path)
+	}
+	return dsn, nil
+}
+
+// GetDatabases 获取所有数据库
+// 打开的文件作为一个数据库，名称为文件名（不含扩展名）
+func (c *DuckDBConnector) GetDatabases(ctx context.Context) (databases []string, err error) {
+	ctx, done := c.config.queryContext(ctx, &err)
+	defer done()
+
+	if c.db == nil {
+		return nil, fmt.Errorf("数据库未连接")
+	}
+
+	rows, err := c.db.QueryContext(ctx, "SELECT database_name FROM duckdb_databases() WHERE NOT internal ORDER BY database_name")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var dbName string
+		if err := rows.Scan(&dbName); err != nil {
+			return nil, err
+		}
+		databases = append(databases, dbName)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return databases, nil
+}
+
+// duckDBTableName 返回表在表列表中显示的名称
+func duckDBTableName(schema, table string) string {
+	if schema == duckDBDefaultSchema {
+		return table
+	}
+	return schema + "." + table
+}
+
+// splitDuckDBTableName 将表列表中的名称拆分为架构和表名
+func splitDuckDBTableName(name string) (schema, table string) {
+	if schema, table, ok := strings.Cut(name, "."); ok {
+		return schema, table
+	}
+	return duckDBDefaultSchema, name
+}
+
+// GetTables 获取指定数据库中所有架构的表和视图
+func (c *DuckDBConnector) GetTables(ctx context.Context, database string) (tables []TableInfo, err error) {
+	ctx, done := c.config.queryContext(ctx, &err)
+	defer done()
+
+	if c.db == nil {
+		return nil, fmt.Errorf("数据库未连接")
+	}
+
+	query := `
+		SELECT table_schema, table_name, table_type
+		FROM information_schema.tables
+		WHERE table_catalog = ? AND table_schema NOT IN ('information_schema', 'pg_catalog')
+		ORDER BY table_schema, table_name
+	`
+
+	rows, err := c.db.QueryContext(ctx, query, database)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var schemaName, tableName, tableType string
+		if err := rows.Scan(&schemaName, &tableName, &tableType); err != nil {
+			return nil, err
+		}
+		tables = append(tables, TableInfo{Name: duckDBTableName(schemaName, tableName), Kind: duckDBObjectKind(tableType)})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return tables, nil
+}
+
+// GetTableMetadata 获取表的元数据信息
+// 字段类型保留DuckDB的完整写法，例如 INTEGER[]、STRUCT(a INTEGER, b VARCHAR) 和 MAP(VARCHAR, DOUBLE)
+func (c *DuckDBConnector) GetTableMetadata(ctx context.Context, database, table string) (metadata *TableMetadata, err error) {
+	ctx, done := c.config.queryContext(ctx, &err)
+	defer done()
+
+	if c.db == nil {
+		return nil, fmt.Errorf("数据库未连接")
+	}
+
+	schema, name := splitDuckDBTableName(table)
+	metadata = &TableMetadata{
+		Name:   name,
+		Schema: schema,
+		Fields: make([]FieldInfo, 0),
+	}
+
+	// 获取对象类型，视图同时获取定义（完整的 CREATE VIEW 语句）
+	var tableType string
+	kindQuery := `
+		SELECT t.table_type, COALESCE(v.sql, '')
+		FROM information_schema.tables t
+			LEFT JOIN duckdb_views() v
+				ON v.database_name = t.table_catalog AND v.schema_name = t.table_schema AND v.view_name = t.table_name
+		WHERE t.table_catalog = ? AND t.table_schema = ? AND t.table_name = ?
+	`
+	if err := c.db.QueryRowContext(ctx, kindQuery, database, schema, name).Scan(&tableType, &metadata.Definition); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("表 %s 不存在", table)
+		}
+		return nil, err
+	}
+	metadata.Kind = duckDBObjectKind(tableType)
+
+	// 获取表字段信息，注释来自 COMMENT ON COLUMN
+	query := `
+		SELECT
+			c.column_name,
+			c.data_type,
+			COALESCE(c.character_maximum_length, 0),
+			c.is_nullable,
+			c.column_default,
+			COALESCE(d.comment, '')
+		FROM information_schema.columns c
+			LEFT JOIN duckdb_columns() d
+				ON d.database_name = c.table_catalog AND d.schema_name = c.table_schema
+				AND d.table_name = c.table_name AND d.column_name = c.column_name
+		WHERE c.table_catalog = ? AND c.table_schema = ? AND c.table_name = ?
+		ORDER BY c.ordinal_position
+	`
+
+	rows, err := c.db.QueryContext(ctx, query, database, schema, name)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var field FieldInfo
+		var isNullable string
+		var columnDefault sql.NullString
+
+		if err := rows.Scan(
+			&field.Name,
+			&field.Type,
+			&field.Length,
+			&isNullable,
+			&columnDefault,
+			&field.Comment,
+		); err != nil {
+			return nil, err
+		}
+
+		// 处理是否可为空
+		field.IsNullable = isNullable == "YES"
+
+		// 处理默认值
+		if columnDefault.Valid {
+			field.Default = columnDefault.String
+		}
+
+		metadata.Fields = append(metadata.Fields, field)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(metadata.Fields) == 0 {
+		return nil, fmt.Errorf("表 %s 不存在", table)
+	}
+
+	// 主键和唯一约束作为索引返回，DuckDB的 CREATE INDEX 不提供列信息
+	indexQuery := `
+		SELECT tc.constraint_name, tc.constraint_type, k.column_name
+		FROM information_schema.table_constraints tc
+			JOIN information_schema.key_column_usage k
+				ON k.constraint_catalog = tc.constraint_catalog AND k.constraint_schema = tc.constraint_schema
+				AND k.constraint_name = tc.constraint_name
+		WHERE tc.table_catalog = ? AND tc.table_schema = ? AND tc.table_name = ?
+			AND tc.constraint_type IN ('PRIMARY KEY', 'UNIQUE')
+		ORDER BY tc.constraint_name, k.ordinal_position
+	`
+
+	indexRows, err := c.db.QueryContext(ctx, indexQuery, database, schema, name)
+	if err != nil {
+		return nil, err
+	}
+	defer indexRows.Close()
+
+	indexPositions := make(map[string]int)
+	for indexRows.Next() {
+		var indexName, constraintType, columnName string
+
+		if err := indexRows.Scan(&indexName, &constraintType, &columnName); err != nil {
+			return nil, err
+		}
+
+		// 如果索引不存在，创建它
+		position, exists := indexPositions[indexName]
+		if !exists {
+			indexType := "UNIQUE"
+			if constraintType == "PRIMARY KEY" {
+				indexType = "PRIMARY"
+			}
+			position = len(metadata.Indexes)
+			indexPositions[indexName] = position
+			metadata.Indexes = append(metadata.Indexes, IndexInfo{Name: indexName, Type: indexType})
+		}
+
+		// 添加列到索引
+		metadata.Indexes[position].Columns = append(metadata.Indexes[position].Columns, columnName)
+	}
+	if err := indexRows.Err(); err != nil {
+		return nil, err
+	}
+
+	// 根据索引标记主键列和单列唯一键
+	for _, index := range metadata.Indexes {
+		for i := range metadata.Fields {
+			field := &metadata.Fields[i]
+			switch {
+			case index.Type == "PRIMARY" && containsString(index.Columns, field.Name):
+				field.IsPrimary = true
+			case index.Type == "UNIQUE" && len(index.Columns) == 1 && index.Columns[0] == field.Name:
+				field.IsUnique = true
+			}
+		}
+	}
+
+	// 获取外键信息，引用的列通过被引用的唯一约束查找
+	foreignKeyQuery := `
+		SELECT
+			rc.constraint_name,
+			k.column_name,
+			r.table_schema,
+			r.table_name,
+			r.column_name
+		FROM information_schema.referential_constraints rc
+			JOIN information_schema.key_column_usage k
+				ON k.constraint_catalog = rc.constraint_catalog AND k.constraint_schema = rc.constraint_schema
+				AND k.constraint_name = rc.constraint_name
+			JOIN information_schema.key_column_usage r
+				ON r.constraint_catalog = rc.unique_constraint_catalog AND r.constraint_schema = rc.unique_constraint_schema
+				AND r.constraint_name = rc.unique_constraint_name AND r.ordinal_position = k.position_in_unique_constraint
+		WHERE k.table_catalog = ? AND k.table_schema = ? AND k.table_name = ?
+		ORDER BY rc.constraint_name, k.ordinal_position
+	`
+
+	foreignKeyRows, err := c.db.QueryContext(ctx, foreignKeyQuery, database, schema, name)
+	if err != nil {
+		return nil, err
+	}
+	defer foreignKeyRows.Close()
+
+	foreignKeyPositions := make(map[string]int)
+	for foreignKeyRows.Next() {
+		var keyName, columnName, refSchema, refTable, refColumn string
+
+		if err := foreignKeyRows.Scan(&keyName, &columnName, &refSchema, &refTable, &refColumn); err != nil {
+			return nil, err
+		}
+
+		position, exists := foreignKeyPositions[keyName]
+		if !exists {
+			position = len(metadata.ForeignKeys)
+			foreignKeyPositions[keyName] = position
+			metadata.ForeignKeys = append(metadata.ForeignKeys, ForeignKeyInfo{
+				Name:     keyName,
+				RefTable: duckDBTableName(refSchema, refTable),
+			})
+		}
+
+		key := &metadata.ForeignKeys[position]
+		key.Columns = append(key.Columns, columnName)
+		key.RefColumns = append(key.RefColumns, refColumn)
+	}
+	if err := foreignKeyRows.Err(); err != nil {
+		return nil, err
+	}
+
+	return metadata, nil
+}
+
+// DescribeQuery 分析查询的结果列和参数，查询包装为 LIMIT 0 的子查询执行，参数传入 NULL
+// 列类型保留驱动报告的DuckDB写法，DuckDB无法确定参数类型
+func (c *DuckDBConnector) DescribeQuery(ctx context.Context, database, query string) (metadata *TableMetadata, err error) {
+	ctx, done := c.config.queryContext(ctx, &err)
+	defer done()
+
+	if c.db == nil {
+		return nil, fmt.Errorf("数据库未连接")
+	}
+	if query, err = trimQuery(query); err != nil {
+		return nil, err
+	}
+
+	params := positionalParams(query)
+	rows, err := c.db.QueryContext(ctx, "SELECT * FROM ("+query+"\n) AS godbmodeler_query LIMIT 0", nullArgs(len(params))...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	fields, err := describeColumns(rows, (*sql.ColumnType).DatabaseTypeName)
+	if err != nil {
+		return nil, err
+	}
+	return newQueryMetadata(query, fields, params), nil
+}
+
+// SampleColumn 读取表中最多 limit 个非空的列值，JSON和嵌套类型转换为文本
+func (c *DuckDBConnector) SampleColumn(ctx context.Context, database, table, column string, limit int) (samples []string, err error) {
+	ctx, done := c.config.queryContext(ctx, &err)
+	defer done()
+
+	if c.db == nil {
+		return nil, fmt.Errorf("数据库未连接")
+	}
+
+	schema, name := splitDuckDBTableName(table)
+	query := fmt.Sprintf("SELECT CAST(%[1]s AS VARCHAR) FROM %[2]s.%[3]s.%[4]s WHERE %[1]s IS NOT NULL LIMIT ?",
+		quoteIdentifier(column), quoteIdentifier(database), quoteIdentifier(schema), quoteIdentifier(name))
+	rows, err := c.db.QueryContext(ctx, query, sampleLimit(limit))
+	if err != nil {
+		return nil, err
+	}
+	return scanSamples(rows)
+}
+
+// PreviewRows 在事务中读取一页表数据，事务结束时回滚
+// DuckDB 没有服务器端的语句超时，超时后通过取消上下文中断查询
+func (c *DuckDBConnector) PreviewRows(ctx context.Context, database, table string, request PreviewRequest) (page *PreviewPage, err error) {
+	ctx, done := c.config.queryContext(ctx, &err)
+	defer done()
+
+	if c.db == nil {
+		return nil, fmt.Errorf("数据库未连接")
+	}
+
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	limit := previewLimit(request.Limit)
+	schema, name := splitDuckDBTableName(table)
+	query := fmt.Sprintf("SELECT * FROM %s.%s.%s", quoteIdentifier(database), quoteIdentifier(schema), quoteIdentifier(name))
+	var args []interface{}
+	if request.FilterColumn != "" {
+		query += " WHERE strpos(CAST(" + quoteIdentifier(request.FilterColumn) + " AS VARCHAR), ?) > 0"
+		args = append(args, request.FilterValue)
+	}
+	query += previewOrderBy(request.OrderBy, quoteIdentifier, "") + " LIMIT ? OFFSET ?"
+	args = append(args, limit+1, previewOffset(request.Offset))
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return readPreviewPage(rows, limit)
+}
+
+// duckDBObjectKind 根据 information_schema.tables 的 table_type 确定对象类型
+func duckDBObjectKind(tableType string) ObjectKind {
+	if tableType == "VIEW" {
+		return KindView
+	}
+	return KindTable
+}
+
+// Close 关闭数据库连接
+func (c *DuckDBConnector) Close() error {
+	if c.db != nil {
+		return c.db.Close()
+	}
+	return nil
+}
@@ -0,0 +1,25 @@
+//go:build duckdb && cgo
+
+package connector
+
+import "testing"
+
+func TestDuckDBReadOnlyDSN(t *testing.T) {
+	accepted := map[string]string{
+		"/data/a.duckdb":        "/data/a.duckdb?access_mode=read_only",
+		"/data/space dir/a.db":  "/data/space dir/a.db?access_mode=read_only",
+		"/data/pct%20/a.duckdb": "/data/pct%20/a.duckdb?access_mode=read_only",
+	}
+	for path, want := range accepted {
+		if got, err := duckDBReadOnlyDSN(path); err != nil || got != want {
+			t.Errorf("duckDBReadOnlyDSN(%q) = %q, %v，应为 %q", path, got, err, want)
+		}
+	}
+
+	// 这些路径会被驱动截断或丢失只读参数
+	for _, path := range []string{"/data/we?ird/a.duckdb", "/data/hash#dir/a.duckdb", "/data/bad%zz/a.duckdb"} {
+		if got, err := duckDBReadOnlyDSN(path); err == nil {
+			t.Errorf("duckDBReadOnlyDSN(%q) = %q，应当返回错误", path, got)
+		}
+	}
+}
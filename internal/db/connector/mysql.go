@@ -1,15 +1,27 @@
 package connector
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
-	_ "github.com/go-sql-driver/mysql"
+	"github.com/go-sql-driver/mysql"
+	"net"
+	"strings"
+	"sync/atomic"
 )
 
+// mysqlRegistrationCount 用于为每个使用SSH隧道的连接器注册不同的网络名称
+var mysqlRegistrationCount atomic.Int64
+
 // MySQLConnector 实现MySQL数据库连接器
 type MySQLConnector struct {
 	config *ConnectionConfig
 	db     *sql.DB
+
+	// 驱动只能注册全局的网络，每个连接器注册一次，通过 tunnel 找到当前的隧道，重新连接时复用
+	network string
+	tunnel  atomic.Pointer[SSHTunnel]
 }
 
 // NewMySQLConnector 创建一个新的MySQL连接器
@@ -20,23 +32,52 @@ func NewMySQLConnector(config *ConnectionConfig) *MySQLConnector {
 }
 
 // Connect 连接到MySQL数据库
-func (c *MySQLConnector) Connect() (*sql.DB, error) {
-	// 构建DSN (Data Source Name)
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s",
-		c.config.Username,
-		c.config.Password,
-		c.config.Host,
-		c.config.Port,
-		c.config.Database)
-
-	// 连接数据库
-	db, err := sql.Open("mysql", dsn)
+func (c *MySQLConnector) Connect(ctx context.Context) (db *sql.DB, err error) {
+	ctx, done := c.config.connectContext(ctx, &err)
+	defer done()
+
+	// 配置了SSH隧道时通过跳板机连接，使用连接器注册的网络名称
+	network := "tcp"
+	if c.config.SSH != nil {
+		tunnel, err := NewSSHTunnel(ctx, c.config.SSH)
+		if err != nil {
+			return nil, err
+		}
+		c.tunnel.Store(tunnel)
+		network = c.tunnelNetwork()
+	}
+
+	// 直接设置连接参数而不是拼接DSN，用户名、密码和数据库名中的特殊字符不会被解析为驱动选项
+	mysqlConfig := mysql.NewConfig()
+	mysqlConfig.User = c.config.Username
+	mysqlConfig.Passwd = c.config.Password
+	mysqlConfig.Net = network
+	mysqlConfig.Addr = net.JoinHostPort(c.config.Host, c.config.Port)
+	mysqlConfig.DBName = c.config.Database
+	if c.config.TLS.enabled() {
+		tlsConfig, err := c.config.TLS.clientConfig(c.config.Host)
+		if err != nil {
+			c.Close()
+			return nil, err
+		}
+		mysqlConfig.TLS = tlsConfig
+	}
+
+	// 连接数据库，每个连接的会话都设置为只读
+	mysqlConnector, err := mysql.NewConnector(mysqlConfig)
 	if err != nil {
+		c.Close()
 		return nil, fmt.Errorf("连接MySQL失败: %v", err)
 	}
+	db = sql.OpenDB(&sessionConnector{
+		Connector:  mysqlConnector,
+		statements: []string{"SET SESSION TRANSACTION READ ONLY"},
+	})
 
 	// 测试连接
-	if err := db.Ping(); err != nil {
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		c.Close()
 		return nil, fmt.Errorf("MySQL连接测试失败: %v", err)
 	}
 
@@ -44,20 +85,42 @@ func (c *MySQLConnector) Connect() (*sql.DB, error) {
 	return db, nil
 }
 
+// tunnelNetwork 返回连接器通过SSH隧道连接使用的网络名称，第一次调用时注册
+func (c *MySQLConnector) tunnelNetwork() string {
+	if c.network == "" {
+		c.network = fmt.Sprintf("ssh-tunnel-%d", mysqlRegistrationCount.Add(1))
+		mysql.RegisterDialContext(c.network, func(ctx context.Context, addr string) (net.Conn, error) {
+			tunnel := c.tunnel.Load()
+			if tunnel == nil {
+				return nil, errors.New("SSH隧道已关闭")
+			}
+			return tunnel.DialContext(ctx, "tcp", addr)
+		})
+	}
+	return c.network
+}
+
+// quoteMySQLIdentifier 用反引号引用MySQL标识符
+func quoteMySQLIdentifier(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
 // GetDatabases 获取所有数据库
-func (c *MySQLConnector) GetDatabases() ([]string, error) {
+func (c *MySQLConnector) GetDatabases(ctx context.Context) (databases []string, err error) {
+	ctx, done := c.config.queryContext(ctx, &err)
+	defer done()
+
 	if c.db == nil {
 		return nil, fmt.Errorf("数据库未连接")
 	}
 
 	// 查询所有数据库
-	rows, err := c.db.Query("SHOW DATABASES")
+	rows, err := c.db.QueryContext(ctx, "SHOW DATABASES")
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var databases []string
 	for rows.Next() {
 		var dbName string
 		if err := rows.Scan(&dbName); err != nil {
@@ -68,57 +131,94 @@ func (c *MySQLConnector) GetDatabases() ([]string, error) {
 			databases = append(databases, dbName)
 		}
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
 
 	return databases, nil
 }
 
-// GetTables 获取指定数据库中的所有表
-func (c *MySQLConnector) GetTables(database string) ([]string, error) {
+// GetTables 获取指定数据库中的所有表和视图
+func (c *MySQLConnector) GetTables(ctx context.Context, database string) (tables []TableInfo, err error) {
+	ctx, done := c.config.queryContext(ctx, &err)
+	defer done()
+
 	if c.db == nil {
 		return nil, fmt.Errorf("数据库未连接")
 	}
 
-	// 切换到指定数据库
-	if _, err := c.db.Exec("USE " + database); err != nil {
-		return nil, err
-	}
+	// 查询指定数据库中的所有表，连接池中的连接可能不同，不能依赖 USE 切换数据库
+	query := `
+		SELECT TABLE_NAME, TABLE_TYPE, IFNULL(CREATE_OPTIONS, '')
+		FROM INFORMATION_SCHEMA.TABLES
+		WHERE TABLE_SCHEMA = ?
+		ORDER BY TABLE_NAME
+	`
 
-	// 查询所有表
-	rows, err := c.db.Query("SHOW TABLES")
+	rows, err := c.db.QueryContext(ctx, query, database)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var tables []string
 	for rows.Next() {
-		var tableName string
-		if err := rows.Scan(&tableName); err != nil {
+		var tableName, tableType, createOptions string
+		if err := rows.Scan(&tableName, &tableType, &createOptions); err != nil {
 			return nil, err
 		}
-		tables = append(tables, tableName)
+		tables = append(tables, TableInfo{Name: tableName, Kind: mysqlObjectKind(tableType, createOptions)})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
 
 	return tables, nil
 }
 
+// mysqlObjectKind 根据 TABLE_TYPE 和 CREATE_OPTIONS 确定对象类型，分区表的 CREATE_OPTIONS 包含 partitioned
+func mysqlObjectKind(tableType, createOptions string) ObjectKind {
+	switch {
+	case strings.HasSuffix(tableType, "VIEW"):
+		return KindView
+	case strings.Contains(createOptions, "partitioned"):
+		return KindPartitionedTable
+	default:
+		return KindTable
+	}
+}
+
 // GetTableMetadata 获取表的元数据信息
-func (c *MySQLConnector) GetTableMetadata(database, table string) (*TableMetadata, error) {
+func (c *MySQLConnector) GetTableMetadata(ctx context.Context, database, table string) (metadata *TableMetadata, err error) {
+	ctx, done := c.config.queryContext(ctx, &err)
+	defer done()
+
 	if c.db == nil {
 		return nil, fmt.Errorf("数据库未连接")
 	}
 
-	// 切换到指定数据库
-	if _, err := c.db.Exec("USE " + database); err != nil {
-		return nil, err
-	}
-
-	// 创建表元数据
-	metadata := &TableMetadata{
+	// 创建表元数据，查询都通过 TABLE_SCHEMA 指定数据库
+	metadata = &TableMetadata{
 		Name:   table,
 		Fields: make([]FieldInfo, 0),
 	}
 
+	// 获取对象类型，视图同时获取定义（没有 SHOW VIEW 权限时为空）
+	var tableType, createOptions, definition string
+	kindQuery := `
+		SELECT t.TABLE_TYPE, IFNULL(t.CREATE_OPTIONS, ''), IFNULL(v.VIEW_DEFINITION, '')
+		FROM INFORMATION_SCHEMA.TABLES t
+			LEFT JOIN INFORMATION_SCHEMA.VIEWS v ON v.TABLE_SCHEMA = t.TABLE_SCHEMA AND v.TABLE_NAME = t.TABLE_NAME
+		WHERE t.TABLE_SCHEMA = ? AND t.TABLE_NAME = ?
+	`
+	if err := c.db.QueryRowContext(ctx, kindQuery, database, table).Scan(&tableType, &createOptions, &definition); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("表 %s 不存在", table)
+		}
+		return nil, err
+	}
+	metadata.Kind = mysqlObjectKind(tableType, createOptions)
+	metadata.Definition = definition
+
 	// 获取表字段信息
 	query := `
 		SELECT 
@@ -137,7 +237,7 @@ func (c *MySQLConnector) GetTableMetadata(database, table string) (*TableMetadat
 			ORDINAL_POSITION
 	`
 
-	rows, err := c.db.Query(query, database, table)
+	rows, err := c.db.QueryContext(ctx, query, database, table)
 	if err != nil {
 		return nil, err
 	}
@@ -175,6 +275,9 @@ func (c *MySQLConnector) GetTableMetadata(database, table string) (*TableMetadat
 
 		metadata.Fields = append(metadata.Fields, field)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
 
 	// 获取索引信息
 	indexQuery := `
@@ -190,7 +293,7 @@ func (c *MySQLConnector) GetTableMetadata(database, table string) (*TableMetadat
 			INDEX_NAME, SEQ_IN_INDEX
 	`
 
-	indexRows, err := c.db.Query(indexQuery, database, table)
+	indexRows, err := c.db.QueryContext(ctx, indexQuery, database, table)
 	if err != nil {
 		return nil, err
 	}
@@ -216,6 +319,9 @@ func (c *MySQLConnector) GetTableMetadata(database, table string) (*TableMetadat
 		// 添加列到索引
 		indexMap[indexName].Columns = append(indexMap[indexName].Columns, columnName)
 	}
+	if err := indexRows.Err(); err != nil {
+		return nil, err
+	}
 
 	// 将索引映射转换为切片
 	for _, index := range indexMap {
@@ -225,10 +331,239 @@ func (c *MySQLConnector) GetTableMetadata(database, table string) (*TableMetadat
 	return metadata, nil
 }
 
+// GetRoutines 获取指定数据库中的存储过程和函数
+func (c *MySQLConnector) GetRoutines(ctx context.Context, database string) (routines []Routine, err error) {
+	ctx, done := c.config.queryContext(ctx, &err)
+	defer done()
+
+	if c.db == nil {
+		return nil, fmt.Errorf("数据库未连接")
+	}
+
+	// 函数的返回类型在 DATA_TYPE 中，存储过程为空
+	query := `
+		SELECT SPECIFIC_NAME, ROUTINE_NAME, ROUTINE_TYPE, IFNULL(DATA_TYPE, ''), ROUTINE_COMMENT
+		FROM INFORMATION_SCHEMA.ROUTINES
+		WHERE ROUTINE_SCHEMA = ?
+		ORDER BY ROUTINE_NAME
+	`
+
+	rows, err := c.db.QueryContext(ctx, query, database)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	positions := make(map[string]int)
+	for rows.Next() {
+		var routine Routine
+		var specificName, routineType string
+
+		if err := rows.Scan(&specificName, &routine.Name, &routineType, &routine.ReturnType, &routine.Comment); err != nil {
+			return nil, err
+		}
+
+		routine.Kind = RoutineFunction
+		if routineType == "PROCEDURE" {
+			routine.Kind = RoutineProcedure
+			routine.ReturnType = ""
+		}
+
+		positions[specificName] = len(routines)
+		routines = append(routines, routine)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// 获取参数，函数的返回值是位置为0的参数，已经在 DATA_TYPE 中读取
+	paramQuery := `
+		SELECT SPECIFIC_NAME, ORDINAL_POSITION, IFNULL(PARAMETER_MODE, ''), IFNULL(PARAMETER_NAME, ''), DATA_TYPE
+		FROM INFORMATION_SCHEMA.PARAMETERS
+		WHERE SPECIFIC_SCHEMA = ? AND ORDINAL_POSITION > 0
+		ORDER BY SPECIFIC_NAME, ORDINAL_POSITION
+	`
+
+	paramRows, err := c.db.QueryContext(ctx, paramQuery, database)
+	if err != nil {
+		return nil, err
+	}
+	defer paramRows.Close()
+
+	for paramRows.Next() {
+		var specificName string
+		var position int
+		var param RoutineParam
+
+		if err := paramRows.Scan(&specificName, &position, &param.Mode, &param.Name, &param.Type); err != nil {
+			return nil, err
+		}
+
+		i, exists := positions[specificName]
+		if !exists {
+			continue
+		}
+		param.Name = routineParamName(param.Name, position)
+		if param.Mode == "" {
+			param.Mode = ParamIn
+		}
+		routines[i].Params = append(routines[i].Params, param)
+	}
+	if err := paramRows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range routines {
+		mysqlRoutineCall(database, &routines[i])
+	}
+
+	return routines, nil
+}
+
+// mysqlRoutineCall 生成调用例程的SQL和签名
+// OUT 和 INOUT 参数通过用户变量传递，此时 CallSQL 包含多条语句，需要在连接上启用多语句
+func mysqlRoutineCall(database string, routine *Routine) {
+	types := make([]string, 0, len(routine.Params))
+	var setArgs, callArgs, placeholders, sets, outputs []string
+	for _, param := range routine.Params {
+		types = append(types, param.Type)
+		variable := "@" + quoteMySQLIdentifier(param.Name)
+		switch param.Mode {
+		case ParamOut:
+			placeholders = append(placeholders, variable)
+		case ParamInOut:
+			sets = append(sets, "SET "+variable+" = ?")
+			setArgs = append(setArgs, param.Name)
+			placeholders = append(placeholders, variable)
+		default:
+			placeholders = append(placeholders, "?")
+			callArgs = append(callArgs, param.Name)
+		}
+		if param.IsOutput() {
+			outputs = append(outputs, variable+" AS "+quoteMySQLIdentifier(param.Name))
+		}
+	}
+	routine.Signature = routine.Name + "(" + strings.Join(types, ", ") + ")"
+	routine.CallArgs = append(setArgs, callArgs...)
+
+	call := quoteMySQLIdentifier(database) + "." + quoteMySQLIdentifier(routine.Name) + "(" + strings.Join(placeholders, ", ") + ")"
+	if routine.Kind == RoutineFunction {
+		routine.CallSQL = "SELECT " + call + " AS result"
+		return
+	}
+
+	statements := append(sets, "CALL "+call)
+	if len(outputs) > 0 {
+		statements = append(statements, "SELECT "+strings.Join(outputs, ", "))
+	}
+	routine.CallSQL = strings.Join(statements, "; ")
+}
+
+// DescribeQuery 分析查询的结果列和参数，查询包装为 LIMIT 0 的子查询执行，参数传入 NULL
+// 查询中不带数据库名的表属于 database；MySQL 无法确定参数类型
+func (c *MySQLConnector) DescribeQuery(ctx context.Context, database, query string) (metadata *TableMetadata, err error) {
+	ctx, done := c.config.queryContext(ctx, &err)
+	defer done()
+
+	if c.db == nil {
+		return nil, fmt.Errorf("数据库未连接")
+	}
+	if query, err = trimQuery(query); err != nil {
+		return nil, err
+	}
+
+	// 在同一个连接上切换数据库并执行查询，其他查询都使用带数据库名的名称，不受 USE 影响
+	conn, err := c.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if _, err := conn.ExecContext(ctx, "USE "+quoteMySQLIdentifier(database)); err != nil {
+		return nil, err
+	}
+
+	params := positionalParams(query)
+	rows, err := conn.QueryContext(ctx, "SELECT * FROM ("+query+"\n) AS godbmodeler_query LIMIT 0", nullArgs(len(params))...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	fields, err := describeColumns(rows, mysqlQueryColumnType)
+	if err != nil {
+		return nil, err
+	}
+	return newQueryMetadata(query, fields, params), nil
+}
+
+// SampleColumn 读取表中最多 limit 个非空的列值
+func (c *MySQLConnector) SampleColumn(ctx context.Context, database, table, column string, limit int) (samples []string, err error) {
+	ctx, done := c.config.queryContext(ctx, &err)
+	defer done()
+
+	if c.db == nil {
+		return nil, fmt.Errorf("数据库未连接")
+	}
+
+	query := fmt.Sprintf("SELECT %[1]s FROM %[2]s.%[3]s WHERE %[1]s IS NOT NULL LIMIT ?",
+		quoteMySQLIdentifier(column), quoteMySQLIdentifier(database), quoteMySQLIdentifier(table))
+	rows, err := c.db.QueryContext(ctx, query, sampleLimit(limit))
+	if err != nil {
+		return nil, err
+	}
+	return scanSamples(rows)
+}
+
+// PreviewRows 在只读事务中读取一页表数据，MAX_EXECUTION_TIME 提示使服务器在查询超时后中止语句
+func (c *MySQLConnector) PreviewRows(ctx context.Context, database, table string, request PreviewRequest) (page *PreviewPage, err error) {
+	ctx, done := c.config.queryContext(ctx, &err)
+	defer done()
+
+	if c.db == nil {
+		return nil, fmt.Errorf("数据库未连接")
+	}
+
+	tx, err := c.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	limit := previewLimit(request.Limit)
+	query := fmt.Sprintf("SELECT /*+ MAX_EXECUTION_TIME(%d) */ * FROM %s.%s",
+		timeoutMillis(ctx), quoteMySQLIdentifier(database), quoteMySQLIdentifier(table))
+	var args []interface{}
+	if request.FilterColumn != "" {
+		query += " WHERE INSTR(CAST(" + quoteMySQLIdentifier(request.FilterColumn) + " AS CHAR), ?) > 0"
+		args = append(args, request.FilterValue)
+	}
+	query += previewOrderBy(request.OrderBy, quoteMySQLIdentifier, "") + " LIMIT ? OFFSET ?"
+	args = append(args, limit+1, previewOffset(request.Offset))
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return readPreviewPage(rows, limit)
+}
+
+// mysqlQueryColumnType 将驱动报告的类型名称转换为 COLUMN_TYPE 的写法，例如 UNSIGNED BIGINT 转换为 bigint unsigned
+func mysqlQueryColumnType(columnType *sql.ColumnType) string {
+	typeName := strings.ToLower(columnType.DatabaseTypeName())
+	if base, ok := strings.CutPrefix(typeName, "unsigned "); ok {
+		return base + " unsigned"
+	}
+	return typeName
+}
+
 // Close 关闭数据库连接
 func (c *MySQLConnector) Close() error {
+	var err error
 	if c.db != nil {
-		return c.db.Close()
+		err = c.db.Close()
+	}
+	if tunnel := c.tunnel.Swap(nil); tunnel != nil {
+		tunnel.Close()
 	}
-	return nil
+	return err
 }
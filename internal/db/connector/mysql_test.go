@@ -0,0 +1,22 @@
+package connector
+
+import "testing"
+
+func TestQuoteMySQLIdentifier(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"users", "`users`"},
+		{"back`tick", "`back``tick`"},
+		{"`", "````"},
+		{"x`; DROP TABLE victim; --", "`x``; DROP TABLE victim; --`"},
+		{"``double``", "`````double`````"},
+		{`we"ird'`, "`we\"ird'`"},
+	}
+	for _, test := range tests {
+		if got := quoteMySQLIdentifier(test.name); got != test.want {
+			t.Errorf("quoteMySQLIdentifier(%q) = %s，应为 %s", test.name, got, test.want)
+		}
+	}
+}
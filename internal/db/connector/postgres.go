@@ -1,15 +1,22 @@
 package connector
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
+	"math"
+	"net"
+	"strconv"
+	"strings"
 )
 
 // PostgreSQLConnector 实现PostgreSQL数据库连接器
 type PostgreSQLConnector struct {
 	config *ConnectionConfig
 	db     *sql.DB
+	tunnel *SSHTunnel
 }
 
 // NewPostgreSQLConnector 创建一个新的PostgreSQL连接器
@@ -20,32 +27,63 @@ func NewPostgreSQLConnector(config *ConnectionConfig) *PostgreSQLConnector {
 }
 
 // Connect 连接到PostgreSQL数据库
-func (c *PostgreSQLConnector) Connect() (*sql.DB, error) {
-	// 构建连接字符串
-	connStr := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
-		c.config.Host,
-		c.config.Port,
-		c.config.Username,
-		c.config.Password,
-		c.config.Database)
+func (c *PostgreSQLConnector) Connect(ctx context.Context) (db *sql.DB, err error) {
+	ctx, done := c.config.connectContext(ctx, &err)
+	defer done()
 
 	// 如果未指定数据库，连接到默认的postgres数据库
-	if c.config.Database == "" {
-		connStr = fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=postgres sslmode=disable",
-			c.config.Host,
-			c.config.Port,
-			c.config.Username,
-			c.config.Password)
+	database := c.config.Database
+	if database == "" {
+		database = "postgres"
 	}
 
+	// lib/pq 使用 host 参数校验服务器证书，指定了证书服务器名称时用它代替主机名，实际地址由拨号器连接
+	host := c.config.Host
+	if c.config.TLS.enabled() && c.config.TLS.ServerName != "" {
+		host = c.config.TLS.ServerName
+	}
+
+	// 构建连接字符串，lib/pq 建立连接时只遵守 connect_timeout 而不响应 ctx 取消，
+	// 多留一秒让 ctx 的超时先生效，后台的连接也会在 connect_timeout 后结束
+	// default_transaction_read_only 作为启动参数传给服务器，会话中的所有事务都是只读的
+	// 所有值都经过引用，包含空格或引号的值不会被解析为其他参数
+	connStr := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s connect_timeout=%d default_transaction_read_only=on %s",
+		pqQuote(host),
+		pqQuote(c.config.Port),
+		pqQuote(c.config.Username),
+		pqQuote(c.config.Password),
+		pqQuote(database),
+		int(math.Ceil(c.config.connectTimeout().Seconds()))+1,
+		c.config.TLS.pqParams())
+
 	// 连接数据库
-	db, err := sql.Open("postgres", connStr)
+	pqConnector, err := pq.NewConnector(connStr)
 	if err != nil {
 		return nil, fmt.Errorf("连接PostgreSQL失败: %v", err)
 	}
 
+	// 配置了SSH隧道时通过跳板机连接
+	dialer := addressDialer{
+		address: net.JoinHostPort(c.config.Host, c.config.Port),
+		dial:    (&net.Dialer{}).DialContext,
+	}
+	if c.config.SSH != nil {
+		tunnel, err := NewSSHTunnel(ctx, c.config.SSH)
+		if err != nil {
+			return nil, err
+		}
+		dialer.dial = tunnel.DialContext
+		c.tunnel = tunnel
+	}
+	if c.config.SSH != nil || host != c.config.Host {
+		pqConnector.Dialer(dialer)
+	}
+	db = sql.OpenDB(pqConnector)
+
 	// 测试连接
-	if err := db.Ping(); err != nil {
+	if err := pingContext(ctx, db); err != nil {
+		db.Close()
+		c.Close()
 		return nil, fmt.Errorf("PostgreSQL连接测试失败: %v", err)
 	}
 
@@ -54,7 +92,10 @@ func (c *PostgreSQLConnector) Connect() (*sql.DB, error) {
 }
 
 // GetDatabases 获取所有数据库
-func (c *PostgreSQLConnector) GetDatabases() ([]string, error) {
+func (c *PostgreSQLConnector) GetDatabases(ctx context.Context) (databases []string, err error) {
+	ctx, done := c.config.queryContext(ctx, &err)
+	defer done()
+
 	if c.db == nil {
 		return nil, fmt.Errorf("数据库未连接")
 	}
@@ -67,13 +108,12 @@ func (c *PostgreSQLConnector) GetDatabases() ([]string, error) {
 		ORDER BY datname
 	`
 
-	rows, err := c.db.Query(query)
+	rows, err := c.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var databases []string
 	for rows.Next() {
 		var dbName string
 		if err := rows.Scan(&dbName); err != nil {
@@ -81,88 +121,111 @@ func (c *PostgreSQLConnector) GetDatabases() ([]string, error) {
 		}
 		databases = append(databases, dbName)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
 
 	return databases, nil
 }
 
-// GetTables 获取指定数据库中的所有表
-func (c *PostgreSQLConnector) GetTables(database string) ([]string, error) {
+// useDatabase 连接到指定的数据库，PostgreSQL的一个连接只能访问一个数据库
+func (c *PostgreSQLConnector) useDatabase(ctx context.Context, database string) error {
+	if c.config.Database == database {
+		return nil
+	}
+
+	// 关闭当前连接
+	c.Close()
+
+	// 更新配置
+	newConfig := *c.config
+	newConfig.Database = database
+	c.config = &newConfig
+
+	// 重新连接
+	_, err := c.Connect(ctx)
+	return err
+}
+
+// GetTables 获取指定数据库中的所有表和视图
+func (c *PostgreSQLConnector) GetTables(ctx context.Context, database string) (tables []TableInfo, err error) {
 	if c.db == nil {
 		return nil, fmt.Errorf("数据库未连接")
 	}
 
 	// 在PostgreSQL中，需要重新连接到指定的数据库
-	if c.config.Database != database {
-		// 关闭当前连接
-		c.Close()
-
-		// 更新配置
-		newConfig := *c.config
-		newConfig.Database = database
-		c.config = &newConfig
-
-		// 重新连接
-		_, err := c.Connect()
-		if err != nil {
-			return nil, err
-		}
+	if err := c.useDatabase(ctx, database); err != nil {
+		return nil, err
 	}
 
-	// 查询所有表
+	ctx, done := c.config.queryContext(ctx, &err)
+	defer done()
+
+	// 查询所有表和视图，物化视图不在 information_schema 中，需要查询 pg_class
 	query := `
-		SELECT table_name 
-		FROM information_schema.tables 
-		WHERE table_schema = 'public' 
-		ORDER BY table_name
+		SELECT c.relname, c.relkind
+		FROM pg_catalog.pg_class c
+			JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+		WHERE n.nspname = 'public' AND c.relkind IN ('r', 'v', 'm', 'f', 'p')
+		ORDER BY c.relname
 	`
 
-	rows, err := c.db.Query(query)
+	rows, err := c.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var tables []string
 	for rows.Next() {
-		var tableName string
-		if err := rows.Scan(&tableName); err != nil {
+		var tableName, relkind string
+		if err := rows.Scan(&tableName, &relkind); err != nil {
 			return nil, err
 		}
-		tables = append(tables, tableName)
+		tables = append(tables, TableInfo{Name: tableName, Kind: postgresObjectKind(relkind)})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
 
 	return tables, nil
 }
 
 // GetTableMetadata 获取表的元数据信息
-func (c *PostgreSQLConnector) GetTableMetadata(database, table string) (*TableMetadata, error) {
+func (c *PostgreSQLConnector) GetTableMetadata(ctx context.Context, database, table string) (metadata *TableMetadata, err error) {
 	if c.db == nil {
 		return nil, fmt.Errorf("数据库未连接")
 	}
 
 	// 确保连接到正确的数据库
-	if c.config.Database != database {
-		// 关闭当前连接
-		c.Close()
-
-		// 更新配置
-		newConfig := *c.config
-		newConfig.Database = database
-		c.config = &newConfig
-
-		// 重新连接
-		_, err := c.Connect()
-		if err != nil {
-			return nil, err
-		}
+	if err := c.useDatabase(ctx, database); err != nil {
+		return nil, err
 	}
 
+	ctx, done := c.config.queryContext(ctx, &err)
+	defer done()
+
 	// 创建表元数据
-	metadata := &TableMetadata{
+	metadata = &TableMetadata{
 		Name:   table,
 		Fields: make([]FieldInfo, 0),
 	}
 
+	// 获取对象类型，视图和物化视图同时获取定义
+	var relkind string
+	kindQuery := `
+		SELECT c.relkind, CASE WHEN c.relkind IN ('v', 'm') THEN pg_catalog.pg_get_viewdef(c.oid, true) ELSE '' END
+		FROM pg_catalog.pg_class c
+			JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+		WHERE n.nspname = 'public' AND c.relname = $1 AND c.relkind IN ('r', 'v', 'm', 'f', 'p')
+	`
+	if err := c.db.QueryRowContext(ctx, kindQuery, table).Scan(&relkind, &metadata.Definition); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("表 %s 不存在", table)
+		}
+		return nil, err
+	}
+	metadata.Kind = postgresObjectKind(relkind)
+
 	// 获取表字段信息
 	query := `
 		SELECT 
@@ -207,7 +270,27 @@ func (c *PostgreSQLConnector) GetTableMetadata(database, table string) (*TableMe
 			c.ordinal_position
 	`
 
-	rows, err := c.db.Query(query, table)
+	// 物化视图的字段不在 information_schema.columns 中，从 pg_attribute 读取，列的格式与上面的查询相同
+	if metadata.Kind == KindMaterializedView {
+		query = `
+			SELECT
+				a.attname,
+				pg_catalog.format_type(a.atttypid, NULL),
+				CASE WHEN a.atttypid IN (1042, 1043) AND a.atttypmod > 4 THEN a.atttypmod - 4 ELSE 0 END,
+				CASE WHEN a.attnotnull THEN 'NO' ELSE 'YES' END,
+				NULL,
+				pg_catalog.col_description(a.attrelid, a.attnum),
+				false,
+				false
+			FROM pg_catalog.pg_attribute a
+				JOIN pg_catalog.pg_class c ON c.oid = a.attrelid
+				JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+			WHERE n.nspname = 'public' AND c.relname = $1 AND a.attnum > 0 AND NOT a.attisdropped
+			ORDER BY a.attnum
+		`
+	}
+
+	rows, err := c.db.QueryContext(ctx, query, table)
 	if err != nil {
 		return nil, err
 	}
@@ -250,6 +333,9 @@ func (c *PostgreSQLConnector) GetTableMetadata(database, table string) (*TableMe
 
 		metadata.Fields = append(metadata.Fields, field)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
 
 	// 获取索引信息
 	indexQuery := `
@@ -264,14 +350,14 @@ func (c *PostgreSQLConnector) GetTableMetadata(database, table string) (*TableMe
 			JOIN pg_am am ON i.relam = am.oid
 			JOIN pg_attribute a ON a.attrelid = c.oid AND a.attnum = ANY(x.indkey)
 		WHERE
-			c.relkind = 'r' AND
+			c.relkind IN ('r', 'm', 'p') AND
 			c.relname = $1
 		GROUP BY
 			i.relname,
 			am.amname
 	`
 
-	indexRows, err := c.db.Query(indexQuery, table)
+	indexRows, err := c.db.QueryContext(ctx, indexQuery, table)
 	if err != nil {
 		return nil, err
 	}
@@ -288,14 +374,406 @@ func (c *PostgreSQLConnector) GetTableMetadata(database, table string) (*TableMe
 		index.Columns = columnNames
 		metadata.Indexes = append(metadata.Indexes, index)
 	}
+	if err := indexRows.Err(); err != nil {
+		return nil, err
+	}
 
 	return metadata, nil
 }
 
+// GetRoutines 获取指定数据库 public 架构中的函数和存储过程，不包括聚合函数、触发器函数和扩展创建的函数
+// 需要 PostgreSQL 11 或更高版本
+func (c *PostgreSQLConnector) GetRoutines(ctx context.Context, database string) (routines []Routine, err error) {
+	if c.db == nil {
+		return nil, fmt.Errorf("数据库未连接")
+	}
+
+	// 确保连接到正确的数据库
+	if err := c.useDatabase(ctx, database); err != nil {
+		return nil, err
+	}
+
+	ctx, done := c.config.queryContext(ctx, &err)
+	defer done()
+
+	// 参数类型包括 OUT 参数和 RETURNS TABLE 的列，没有 OUT 参数时 proallargtypes 为空
+	query := `
+		SELECT
+			n.nspname,
+			p.proname,
+			p.prokind,
+			p.proretset,
+			CASE WHEN p.prokind = 'p' THEN '' ELSE pg_catalog.format_type(p.prorettype, NULL) END,
+			COALESCE(t.typrelid, 0),
+			COALESCE(p.proargnames, ARRAY[]::text[]),
+			COALESCE(p.proargmodes::text[], ARRAY[]::text[]),
+			ARRAY(
+				SELECT pg_catalog.format_type(a.type, NULL)
+				FROM unnest(COALESCE(p.proallargtypes, p.proargtypes::oid[])) WITH ORDINALITY AS a(type, position)
+				ORDER BY a.position
+			),
+			pg_catalog.pg_get_function_identity_arguments(p.oid),
+			COALESCE(pg_catalog.obj_description(p.oid, 'pg_proc'), '')
+		FROM pg_catalog.pg_proc p
+			JOIN pg_catalog.pg_namespace n ON n.oid = p.pronamespace
+			LEFT JOIN pg_catalog.pg_type t ON t.oid = p.prorettype
+		WHERE n.nspname = 'public' AND p.prokind IN ('f', 'p')
+			AND p.prorettype NOT IN ('pg_catalog.trigger'::pg_catalog.regtype, 'pg_catalog.event_trigger'::pg_catalog.regtype)
+			AND NOT EXISTS (
+				SELECT 1 FROM pg_catalog.pg_depend d
+				WHERE d.classid = 'pg_catalog.pg_proc'::pg_catalog.regclass AND d.objid = p.oid AND d.deptype = 'e'
+			)
+		ORDER BY p.proname, p.oid
+	`
+
+	rows, err := c.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// 返回复合类型的函数需要在读取完列表后查询类型的字段
+	compositeTypes := make(map[int]int64)
+	for rows.Next() {
+		var routine Routine
+		var prokind, returnType, identityArgs string
+		var typeRelation int64
+		var argNames, argModes, argTypes []string
+
+		if err := rows.Scan(
+			&routine.Schema,
+			&routine.Name,
+			&prokind,
+			&routine.ReturnsSet,
+			&returnType,
+			&typeRelation,
+			pq.Array(&argNames),
+			pq.Array(&argModes),
+			pq.Array(&argTypes),
+			&identityArgs,
+			&routine.Comment,
+		); err != nil {
+			return nil, err
+		}
+
+		routine.Kind = RoutineFunction
+		if prokind == "p" {
+			routine.Kind = RoutineProcedure
+		}
+		routine.Signature = routine.Name + "(" + identityArgs + ")"
+
+		// 参数模式：i 输入，o 输出，b 输入输出，v 可变参数，t RETURNS TABLE 的列；proargmodes 为空时都是输入参数
+		hasOutput := false
+		for i, argType := range argTypes {
+			var name string
+			if i < len(argNames) {
+				name = argNames[i]
+			}
+			param := RoutineParam{Name: routineParamName(name, i+1), Mode: ParamIn, Type: argType}
+			if i < len(argModes) {
+				switch argModes[i] {
+				case "o":
+					param.Mode = ParamOut
+				case "b":
+					param.Mode = ParamInOut
+				case "v":
+					param.Mode = ParamVariadic
+				case "t":
+					param.Mode = ""
+					routine.Columns = append(routine.Columns, param)
+					continue
+				}
+			}
+			hasOutput = hasOutput || param.IsOutput()
+			routine.Params = append(routine.Params, param)
+		}
+
+		// 返回 record 时结果由 OUT 参数或 RETURNS TABLE 的列组成，返回复合类型时读取类型的字段
+		switch {
+		case routine.Kind == RoutineProcedure || hasOutput || len(routine.Columns) > 0 || returnType == "void":
+		case typeRelation != 0:
+			compositeTypes[len(routines)] = typeRelation
+		default:
+			routine.ReturnType = returnType
+		}
+
+		routines = append(routines, routine)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	// 读取返回的复合类型（包括表的行类型）的字段
+	columnQuery := `
+		SELECT a.attname, pg_catalog.format_type(a.atttypid, NULL)
+		FROM pg_catalog.pg_attribute a
+		WHERE a.attrelid = $1 AND a.attnum > 0 AND NOT a.attisdropped
+		ORDER BY a.attnum
+	`
+	for i, relation := range compositeTypes {
+		columnRows, err := c.db.QueryContext(ctx, columnQuery, relation)
+		if err != nil {
+			return nil, err
+		}
+		for columnRows.Next() {
+			var column RoutineParam
+			if err := columnRows.Scan(&column.Name, &column.Type); err != nil {
+				columnRows.Close()
+				return nil, err
+			}
+			routines[i].Columns = append(routines[i].Columns, column)
+		}
+		if err := columnRows.Err(); err != nil {
+			columnRows.Close()
+			return nil, err
+		}
+		columnRows.Close()
+	}
+
+	for i := range routines {
+		postgresRoutineCall(&routines[i])
+	}
+
+	return routines, nil
+}
+
+// postgresRoutineCall 生成调用例程的SQL
+// 存储过程使用 CALL，OUT 参数传入 NULL；返回多列或多行的函数在 FROM 中调用，返回单个值的函数的结果列名为 result
+func postgresRoutineCall(routine *Routine) {
+	args := make([]string, 0, len(routine.Params))
+	hasOutput := false
+	for _, param := range routine.Params {
+		hasOutput = hasOutput || param.IsOutput()
+		switch {
+		case param.IsInput():
+			routine.CallArgs = append(routine.CallArgs, param.Name)
+			placeholder := "$" + strconv.Itoa(len(routine.CallArgs))
+			if param.Mode == ParamVariadic {
+				placeholder = "VARIADIC " + placeholder
+			}
+			args = append(args, placeholder)
+		case routine.Kind == RoutineProcedure:
+			args = append(args, "NULL")
+		}
+	}
+
+	name := pq.QuoteIdentifier(routine.Schema) + "." + pq.QuoteIdentifier(routine.Name)
+	call := name + "(" + strings.Join(args, ", ") + ")"
+	switch {
+	case routine.Kind == RoutineProcedure:
+		routine.CallSQL = "CALL " + call
+	case hasOutput || len(routine.Columns) > 0:
+		routine.CallSQL = "SELECT * FROM " + call
+	case routine.ReturnsSet:
+		routine.CallSQL = "SELECT * FROM " + call + " AS result"
+	default:
+		routine.CallSQL = "SELECT " + call + " AS result"
+	}
+}
+
+// postgresObjectKind 根据 pg_class.relkind 确定对象类型
+func postgresObjectKind(relkind string) ObjectKind {
+	switch relkind {
+	case "v":
+		return KindView
+	case "m":
+		return KindMaterializedView
+	case "f":
+		return KindForeignTable
+	case "p":
+		return KindPartitionedTable
+	default:
+		return KindTable
+	}
+}
+
+// postgresDescribeStatement 是分析查询时使用的预备语句名称
+const postgresDescribeStatement = "godbmodeler_describe"
+
+// postgresDriverTypes 将驱动报告的类型名称转换为 format_type 的写法
+var postgresDriverTypes = map[string]string{
+	"INT2":        "smallint",
+	"INT4":        "integer",
+	"INT8":        "bigint",
+	"FLOAT4":      "real",
+	"FLOAT8":      "double precision",
+	"NUMERIC":     "numeric",
+	"BOOL":        "boolean",
+	"VARCHAR":     "character varying",
+	"BPCHAR":      "character",
+	"NAME":        "text",
+	"TIMESTAMP":   "timestamp without time zone",
+	"TIMESTAMPTZ": "timestamp with time zone",
+	"TIME":        "time without time zone",
+	"TIMETZ":      "time with time zone",
+}
+
+// DescribeQuery 分析查询的结果列和参数类型
+// 查询包装为 LIMIT 0 的子查询后作为预备语句，参数类型来自 pg_prepared_statements，执行时参数传入 NULL
+// 查询只能包含一条语句，并在只读事务中准备和执行
+// 驱动不提供列是否可为空，所有列都按可为空处理
+func (c *PostgreSQLConnector) DescribeQuery(ctx context.Context, database, query string) (metadata *TableMetadata, err error) {
+	if c.db == nil {
+		return nil, fmt.Errorf("数据库未连接")
+	}
+
+	// 确保连接到正确的数据库
+	if err := c.useDatabase(ctx, database); err != nil {
+		return nil, err
+	}
+
+	ctx, done := c.config.queryContext(ctx, &err)
+	defer done()
+
+	if query, err = trimQuery(query); err != nil {
+		return nil, err
+	}
+
+	// 预备语句属于会话，准备、查询参数和执行都在同一个连接的只读事务中进行
+	// 预备语句不随事务回滚，事务结束后在连接上释放
+	conn, err := c.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	defer conn.ExecContext(context.Background(), "DEALLOCATE "+postgresDescribeStatement)
+
+	tx, err := conn.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// 不带参数的 Exec 使用简单查询协议，会执行以分号分隔的多条语句；
+	// 先准备再执行使用扩展查询协议，服务器只接受一条语句
+	prepare, err := tx.PrepareContext(ctx, "PREPARE "+postgresDescribeStatement+" AS SELECT * FROM ("+query+"\n) AS godbmodeler_query LIMIT 0")
+	if err != nil {
+		return nil, err
+	}
+	defer prepare.Close()
+	if _, err := prepare.ExecContext(ctx); err != nil {
+		return nil, err
+	}
+
+	var paramTypes []string
+	typesQuery := "SELECT parameter_types::text[] FROM pg_catalog.pg_prepared_statements WHERE name = $1"
+	if err := tx.QueryRowContext(ctx, typesQuery, postgresDescribeStatement).Scan(pq.Array(&paramTypes)); err != nil {
+		return nil, err
+	}
+	params := make([]FieldInfo, 0, len(paramTypes))
+	nulls := make([]string, 0, len(paramTypes))
+	for i, paramType := range paramTypes {
+		params = append(params, FieldInfo{Name: queryParamName(i + 1), Type: paramType})
+		nulls = append(nulls, "NULL")
+	}
+
+	execute := "EXECUTE " + postgresDescribeStatement
+	if len(nulls) > 0 {
+		execute += "(" + strings.Join(nulls, ", ") + ")"
+	}
+	rows, err := tx.QueryContext(ctx, execute)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	fields, err := describeColumns(rows, postgresQueryColumnType)
+	if err != nil {
+		return nil, err
+	}
+	return newQueryMetadata(query, fields, params), nil
+}
+
+// SampleColumn 读取 public 架构的表中最多 limit 个非空的列值，json 和 jsonb 转换为文本
+func (c *PostgreSQLConnector) SampleColumn(ctx context.Context, database, table, column string, limit int) (samples []string, err error) {
+	if c.db == nil {
+		return nil, fmt.Errorf("数据库未连接")
+	}
+
+	// 确保连接到正确的数据库
+	if err := c.useDatabase(ctx, database); err != nil {
+		return nil, err
+	}
+
+	ctx, done := c.config.queryContext(ctx, &err)
+	defer done()
+
+	query := fmt.Sprintf("SELECT %[1]s::text FROM public.%[2]s WHERE %[1]s IS NOT NULL LIMIT $1",
+		pq.QuoteIdentifier(column), pq.QuoteIdentifier(table))
+	rows, err := c.db.QueryContext(ctx, query, sampleLimit(limit))
+	if err != nil {
+		return nil, err
+	}
+	return scanSamples(rows)
+}
+
+// PreviewRows 在只读事务中读取 public 架构的表的一页数据，statement_timeout 使服务器在查询超时后中止语句
+func (c *PostgreSQLConnector) PreviewRows(ctx context.Context, database, table string, request PreviewRequest) (page *PreviewPage, err error) {
+	if c.db == nil {
+		return nil, fmt.Errorf("数据库未连接")
+	}
+
+	// 确保连接到正确的数据库
+	if err := c.useDatabase(ctx, database); err != nil {
+		return nil, err
+	}
+
+	ctx, done := c.config.queryContext(ctx, &err)
+	defer done()
+
+	tx, err := c.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if timeout := timeoutMillis(ctx); timeout > 0 {
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf("SET LOCAL statement_timeout = %d", timeout)); err != nil {
+			return nil, err
+		}
+	}
+
+	limit := previewLimit(request.Limit)
+	query := "SELECT * FROM public." + pq.QuoteIdentifier(table)
+	var args []interface{}
+	if request.FilterColumn != "" {
+		args = append(args, request.FilterValue)
+		query += fmt.Sprintf(" WHERE strpos(%s::text, $%d) > 0", pq.QuoteIdentifier(request.FilterColumn), len(args))
+	}
+	args = append(args, limit+1, previewOffset(request.Offset))
+	query += previewOrderBy(request.OrderBy, pq.QuoteIdentifier, "") + fmt.Sprintf(" LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return readPreviewPage(rows, limit)
+}
+
+// postgresQueryColumnType 将驱动报告的类型名称转换为 format_type 的写法，数组类型 _INT4 转换为 integer[]
+// 驱动不认识的类型（例如枚举和自定义类型）名称为空
+func postgresQueryColumnType(columnType *sql.ColumnType) string {
+	typeName := columnType.DatabaseTypeName()
+	suffix := ""
+	if element, ok := strings.CutPrefix(typeName, "_"); ok {
+		typeName, suffix = element, "[]"
+	}
+	if name, ok := postgresDriverTypes[typeName]; ok {
+		return name + suffix
+	}
+	return strings.ToLower(typeName) + suffix
+}
+
 // Close 关闭数据库连接
 func (c *PostgreSQLConnector) Close() error {
+	var err error
 	if c.db != nil {
-		return c.db.Close()
+		err = c.db.Close()
+	}
+	if c.tunnel != nil {
+		c.tunnel.Close()
+		c.tunnel = nil
 	}
-	return nil
+	return err
 }
@@ -0,0 +1,134 @@
+package connector
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+)
+
+// DefaultPreviewLimit 是未指定行数时每页读取的行数
+const DefaultPreviewLimit = 100
+
+// PreviewRequest 描述要读取的一页数据
+type PreviewRequest struct {
+	Limit        int      // 每页行数，不大于0时使用 DefaultPreviewLimit
+	Offset       int      // 跳过的行数
+	FilterColumn string   // 筛选的列，为空时不筛选
+	FilterValue  string   // 筛选列的文本形式需要包含的内容
+	OrderBy      []string // 排序的列，通常为主键，使分页结果稳定
+}
+
+// PreviewPage 是读取到的一页数据
+// 每个值为 nil（NULL）、[]byte（二进制列）、string、数字、bool 或 time.Time
+type PreviewPage struct {
+	Columns []string
+	Types   []string // 数据库报告的列类型名称
+	Rows    [][]interface{}
+	HasMore bool // 之后是否还有数据
+}
+
+// RowPreviewer 由能够分页读取表数据的连接器实现，用于在生成前预览数据
+// 读取在只读事务中进行并受查询超时限制，事务结束时总是回滚
+// 不是所有数据库都支持，调用前通过类型断言判断
+type RowPreviewer interface {
+	PreviewRows(ctx context.Context, database, table string, request PreviewRequest) (*PreviewPage, error)
+}
+
+// previewLimit 返回有效的每页行数
+func previewLimit(limit int) int {
+	if limit <= 0 {
+		return DefaultPreviewLimit
+	}
+	return limit
+}
+
+// previewOffset 返回有效的跳过行数
+func previewOffset(offset int) int {
+	if offset < 0 {
+		return 0
+	}
+	return offset
+}
+
+// previewOrderBy 使用 quote 引用排序的列，生成 ORDER BY 子句；没有排序列时返回 fallback
+func previewOrderBy(columns []string, quote func(string) string, fallback string) string {
+	if len(columns) == 0 {
+		return fallback
+	}
+	quoted := make([]string, 0, len(columns))
+	for _, column := range columns {
+		quoted = append(quoted, quote(column))
+	}
+	return " ORDER BY " + strings.Join(quoted, ", ")
+}
+
+// timeoutMillis 返回上下文剩余的时间（毫秒），用于设置数据库的语句超时；没有截止时间时返回0
+func timeoutMillis(ctx context.Context) int64 {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return 0
+	}
+	if remaining := time.Until(deadline).Milliseconds(); remaining > 0 {
+		return remaining
+	}
+	return 1
+}
+
+// isBinaryType 判断数据库报告的列类型是否为二进制类型
+func isBinaryType(typeName string) bool {
+	typeName = strings.ToUpper(typeName)
+	return strings.Contains(typeName, "BLOB") || strings.Contains(typeName, "BINARY") ||
+		typeName == "BYTEA" || typeName == "IMAGE"
+}
+
+// readPreviewPage 读取最多 limit 行数据，多读一行用于判断之后是否还有数据
+// 二进制列的值保留为 []byte，其他列的 []byte 转换为字符串；没有类型名称的列按二进制处理 []byte
+func readPreviewPage(rows *sql.Rows, limit int) (*PreviewPage, error) {
+	defer rows.Close()
+
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+	page := &PreviewPage{
+		Columns: make([]string, len(columnTypes)),
+		Types:   make([]string, len(columnTypes)),
+	}
+	binary := make([]bool, len(columnTypes))
+	for i, columnType := range columnTypes {
+		page.Columns[i] = columnType.Name()
+		page.Types[i] = columnType.DatabaseTypeName()
+		binary[i] = page.Types[i] == "" || isBinaryType(page.Types[i])
+	}
+
+	for rows.Next() {
+		if len(page.Rows) == limit {
+			page.HasMore = true
+			break
+		}
+
+		values := make([]interface{}, len(columnTypes))
+		pointers := make([]interface{}, len(columnTypes))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return nil, err
+		}
+		for i, value := range values {
+			if data, ok := value.([]byte); ok {
+				if binary[i] {
+					values[i] = append([]byte(nil), data...)
+				} else {
+					values[i] = string(data)
+				}
+			}
+		}
+		page.Rows = append(page.Rows, values)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
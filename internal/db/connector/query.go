@@ -0,0 +1,172 @@
+package connector
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// QueryDescriber 由能够分析任意查询结果结构的连接器实现
+// 不是所有数据库都支持，调用前通过类型断言判断
+type QueryDescriber interface {
+	// DescribeQuery 分析查询的结果列和参数，不读取数据
+	// 返回的元数据 Kind 为 KindQuery，Definition 为查询语句，Params 为按位置排列的参数
+	DescribeQuery(ctx context.Context, database, query string) (*TableMetadata, error)
+}
+
+// trimQuery 去掉查询首尾的空白和结尾的分号，以便把查询包装为子查询
+// 查询中间还有分号时拒绝，否则包装后的语句可以结束子查询并执行其他语句
+func trimQuery(query string) (string, error) {
+	query = strings.TrimRight(strings.TrimSpace(query), "; \t\r\n")
+	if query == "" {
+		return "", fmt.Errorf("查询语句为空")
+	}
+	runes := []rune(query)
+	for i := 0; i < len(runes); i++ {
+		if end := skipQuoted(runes, i); end != i {
+			i = end
+			continue
+		}
+		if runes[i] == ';' {
+			return "", fmt.Errorf("查询只能包含一条语句")
+		}
+	}
+	return query, nil
+}
+
+// skipQuoted 跳过从 i 开始的字符串、带引号的标识符、美元符号引用的字符串或注释，返回其最后一个字符的位置
+// i 处不是这些内容的开头时返回 i；没有结束时返回最后一个字符之后的位置
+func skipQuoted(runes []rune, i int) int {
+	switch r := runes[i]; {
+	case r == '\'' || r == '"' || r == '`':
+		// 连续两个引号表示转义
+		for i++; i < len(runes); i++ {
+			if runes[i] == r {
+				if i+1 < len(runes) && runes[i+1] == r {
+					i++
+					continue
+				}
+				return i
+			}
+		}
+		return i
+	case r == '-' && i+1 < len(runes) && runes[i+1] == '-':
+		for i < len(runes) && runes[i] != '\n' {
+			i++
+		}
+		return i
+	case r == '/' && i+1 < len(runes) && runes[i+1] == '*':
+		for i += 2; i+1 < len(runes) && !(runes[i] == '*' && runes[i+1] == '/'); i++ {
+		}
+		return i + 1
+	case r == '$' && (i == 0 || !isIdentifierRune(runes[i-1])):
+		// PostgreSQL 的 $标签$...$标签$，标签可以为空，不能以数字开头
+		tagEnd := i + 1
+		for tagEnd < len(runes) && isIdentifierRune(runes[tagEnd]) {
+			tagEnd++
+		}
+		if tagEnd >= len(runes) || runes[tagEnd] != '$' || (tagEnd > i+1 && unicode.IsDigit(runes[i+1])) {
+			return i
+		}
+		tag := string(runes[i : tagEnd+1])
+		rest := string(runes[tagEnd+1:])
+		end := strings.Index(rest, tag)
+		if end < 0 {
+			return len(runes)
+		}
+		return tagEnd + len([]rune(rest[:end])) + len([]rune(tag))
+	}
+	return i
+}
+
+// isIdentifierRune 判断字符是否可以出现在不带引号的标识符中
+func isIdentifierRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// queryParamName 返回第 position 个位置参数的名称（从1开始）
+func queryParamName(position int) string {
+	return "param" + strconv.Itoa(position)
+}
+
+// positionalParams 返回查询中 ? 和 $n 占位符对应的参数，忽略字符串、带引号的标识符和注释中的内容
+// $n 按最大的序号计算参数个数；参数类型为空，由类型映射器映射为 any
+func positionalParams(query string) []FieldInfo {
+	count, maxNumbered := 0, 0
+	runes := []rune(query)
+	for i := 0; i < len(runes); i++ {
+		if end := skipQuoted(runes, i); end != i {
+			i = end
+			continue
+		}
+		switch r := runes[i]; {
+		case r == '?':
+			count++
+		case r == '$' && i+1 < len(runes) && unicode.IsDigit(runes[i+1]):
+			j := i + 1
+			for j < len(runes) && unicode.IsDigit(runes[j]) {
+				j++
+			}
+			if n, err := strconv.Atoi(string(runes[i+1 : j])); err == nil && n > maxNumbered {
+				maxNumbered = n
+			}
+			i = j - 1
+		}
+	}
+
+	if maxNumbered > count {
+		count = maxNumbered
+	}
+	params := make([]FieldInfo, 0, count)
+	for i := 1; i <= count; i++ {
+		params = append(params, FieldInfo{Name: queryParamName(i)})
+	}
+	return params
+}
+
+// nullArgs 返回 n 个 NULL 参数值，用于在不读取数据的情况下执行带参数的查询
+func nullArgs(n int) []interface{} {
+	return make([]interface{}, n)
+}
+
+// describeColumns 根据结果集的列类型生成字段信息，typeName 将驱动报告的类型名称转换为类型映射器使用的名称
+// 驱动无法确定是否可为空时按可为空处理
+func describeColumns(rows *sql.Rows, typeName func(*sql.ColumnType) string) ([]FieldInfo, error) {
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make([]FieldInfo, 0, len(columnTypes))
+	for i, columnType := range columnTypes {
+		field := FieldInfo{
+			Name:       columnType.Name(),
+			Type:       typeName(columnType),
+			IsNullable: true,
+		}
+		if field.Name == "" {
+			field.Name = "column" + strconv.Itoa(i+1)
+		}
+		if length, ok := columnType.Length(); ok && length > 0 && length < 1<<31-1 {
+			field.Length = int(length)
+		}
+		if nullable, ok := columnType.Nullable(); ok {
+			field.IsNullable = nullable
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// newQueryMetadata 创建查询的元数据
+func newQueryMetadata(query string, fields, params []FieldInfo) *TableMetadata {
+	return &TableMetadata{
+		Kind:       KindQuery,
+		Definition: query,
+		Fields:     fields,
+		Params:     params,
+	}
+}
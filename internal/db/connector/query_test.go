@@ -0,0 +1,35 @@
+package connector
+
+import "testing"
+
+func TestTrimQuery(t *testing.T) {
+	accepted := map[string]string{
+		"SELECT 1;\n":                          "SELECT 1",
+		"SELECT ';' AS a":                      "SELECT ';' AS a",
+		`SELECT 1 AS "a;b"`:                    `SELECT 1 AS "a;b"`,
+		"SELECT 1 -- a; b\n":                   "SELECT 1 -- a; b",
+		"SELECT 1 /* a; b */ ;;":               "SELECT 1 /* a; b */",
+		"SELECT $$a;b$$, $tag$c;$$d$tag$":      "SELECT $$a;b$$, $tag$c;$$d$tag$",
+		"SELECT a$b FROM t WHERE id = $1":      "SELECT a$b FROM t WHERE id = $1",
+		"SELECT 'it''s; fine' FROM t WHERE ?;": "SELECT 'it''s; fine' FROM t WHERE ?",
+	}
+	for query, want := range accepted {
+		got, err := trimQuery(query)
+		if err != nil || got != want {
+			t.Errorf("trimQuery(%q) = %q, %v，应为 %q", query, got, err, want)
+		}
+	}
+
+	for _, query := range append([]string{"", " ;\n", "SELECT 1; SELECT 2"}, hostileQueries...) {
+		if got, err := trimQuery(query); err == nil {
+			t.Errorf("trimQuery(%q) = %q，应当返回错误", query, got)
+		}
+	}
+}
+
+func TestPositionalParamsSkipsDollarQuotes(t *testing.T) {
+	params := positionalParams("SELECT $$ $3 ? $$, $tag$ ? $tag$ FROM t WHERE a = $1 AND b = ?")
+	if len(params) != 1 {
+		t.Errorf("参数为 %+v，应为 1 个", params)
+	}
+}
@@ -0,0 +1,77 @@
+package connector
+
+import (
+	"context"
+	"strconv"
+)
+
+// RoutineConnector 由支持存储过程和函数的连接器实现
+// 不是所有数据库都支持，调用前通过类型断言判断
+type RoutineConnector interface {
+	// GetRoutines 获取指定数据库中的存储过程和函数及其签名
+	GetRoutines(ctx context.Context, database string) ([]Routine, error)
+}
+
+// RoutineKind 表示例程的类型
+type RoutineKind string
+
+// 例程类型
+const (
+	RoutineFunction  RoutineKind = "function"  // 函数
+	RoutineProcedure RoutineKind = "procedure" // 存储过程
+)
+
+// 参数模式
+const (
+	ParamIn       = "IN"
+	ParamOut      = "OUT"
+	ParamInOut    = "INOUT"
+	ParamVariadic = "VARIADIC" // PostgreSQL 的可变参数，调用时传入数组
+)
+
+// Routine 表示一个存储过程或函数
+type Routine struct {
+	Name      string         // 名称
+	Schema    string         // 所在的架构，数据库没有架构时为空
+	Signature string         // 包含参数类型的签名，用于区分重载的函数，例如 get_orders(integer)
+	Kind      RoutineKind    // 例程类型
+	Params    []RoutineParam // 参数，按位置排列，不包括返回值和返回的列
+	Comment   string         // 注释
+
+	// ReturnType 是函数返回单个值时的数据库类型，返回多列、没有返回值或存储过程时为空
+	ReturnType string
+	// ReturnsSet 表示返回多行，例如 SETOF、RETURNS TABLE 和表值函数
+	ReturnsSet bool
+	// Columns 是返回的列，例如 RETURNS TABLE 的列、返回的复合类型的字段和表值函数的列，Mode 为空
+	Columns []RoutineParam
+
+	// CallSQL 是调用该例程的SQL，参数使用驱动的占位符；OUT 参数的值作为结果行返回
+	CallSQL string
+	// CallArgs 是 CallSQL 中占位符对应的参数名称，按占位符的顺序排列
+	CallArgs []string
+}
+
+// RoutineParam 表示例程的参数或返回的列
+type RoutineParam struct {
+	Name string // 名称，没有名称的参数为 arg 加位置，例如 arg1
+	Mode string // 参数模式，取值为 Param* 常量之一
+	Type string // 数据库类型
+}
+
+// IsInput 判断调用时是否需要传入该参数
+func (p RoutineParam) IsInput() bool {
+	return p.Mode == ParamIn || p.Mode == ParamInOut || p.Mode == ParamVariadic
+}
+
+// IsOutput 判断该参数的值是否在结果中返回
+func (p RoutineParam) IsOutput() bool {
+	return p.Mode == ParamOut || p.Mode == ParamInOut
+}
+
+// routineParamName 返回参数名称，没有名称时使用 arg 加位置（从1开始）
+func routineParamName(name string, position int) string {
+	if name == "" {
+		return "arg" + strconv.Itoa(position)
+	}
+	return name
+}
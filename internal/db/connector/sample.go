@@ -0,0 +1,48 @@
+package connector
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+)
+
+// DefaultSampleSize 是未指定数量时读取的样本行数
+const DefaultSampleSize = 100
+
+// ColumnSampler 由能够读取列样本数据的连接器实现，用于推断JSON列的结构
+// 不是所有数据库都支持，调用前通过类型断言判断
+type ColumnSampler interface {
+	// SampleColumn 读取表中最多 limit 个非空的列值，以文本形式返回
+	SampleColumn(ctx context.Context, database, table, column string, limit int) ([]string, error)
+}
+
+// sampleLimit 返回有效的样本行数，limit 不大于0时使用 DefaultSampleSize
+func sampleLimit(limit int) int {
+	if limit <= 0 {
+		return DefaultSampleSize
+	}
+	return limit
+}
+
+// scanSamples 读取只有一列的结果集中的所有值
+func scanSamples(rows *sql.Rows) ([]string, error) {
+	defer rows.Close()
+
+	var samples []string
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			return nil, err
+		}
+		samples = append(samples, value)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return samples, nil
+}
+
+// quoteIdentifier 使用双引号引用标识符，适用于SQLite、PostgreSQL和DuckDB
+func quoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
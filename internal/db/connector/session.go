@@ -0,0 +1,35 @@
+package connector
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+)
+
+// sessionConnector 包装驱动的连接器，在连接池新建的每个连接上先执行会话设置，
+// 用于把会话设置为只读，连接池中的所有连接因此都不能写入
+type sessionConnector struct {
+	driver.Connector
+	statements []string
+}
+
+// Connect 建立连接并执行会话设置，设置失败时关闭连接
+func (c *sessionConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	execer, ok := conn.(driver.ExecerContext)
+	if !ok {
+		conn.Close()
+		return nil, fmt.Errorf("驱动不支持执行会话设置")
+	}
+	for _, statement := range c.statements {
+		if _, err := execer.ExecContext(ctx, statement, nil); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("设置只读会话失败: %v", err)
+		}
+	}
+	return conn, nil
+}
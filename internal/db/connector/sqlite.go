@@ -1,11 +1,14 @@
 package connector
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	_ "github.com/mattn/go-sqlite3"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 // SQLiteConnector 实现SQLite数据库连接器
@@ -22,7 +25,10 @@ func NewSQLiteConnector(config *ConnectionConfig) *SQLiteConnector {
 }
 
 // Connect 连接到SQLite数据库
-func (c *SQLiteConnector) Connect() (*sql.DB, error) {
+func (c *SQLiteConnector) Connect(ctx context.Context) (db *sql.DB, err error) {
+	ctx, done := c.config.connectContext(ctx, &err)
+	defer done()
+
 	// 对于SQLite，Host字段实际上是文件路径
 	dbPath := c.config.Host
 
@@ -31,14 +37,15 @@ func (c *SQLiteConnector) Connect() (*sql.DB, error) {
 		return nil, fmt.Errorf("SQLite数据库文件不存在: %s", dbPath)
 	}
 
-	// 连接数据库
-	db, err := sql.Open("sqlite3", dbPath)
+	// 以只读模式打开数据库，任何写入都会失败
+	db, err = sql.Open("sqlite3", sqliteReadOnlyDSN(dbPath))
 	if err != nil {
 		return nil, fmt.Errorf("连接SQLite失败: %v", err)
 	}
 
 	// 测试连接
-	if err := db.Ping(); err != nil {
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
 		return nil, fmt.Errorf("SQLite连接测试失败: %v", err)
 	}
 
@@ -46,9 +53,21 @@ func (c *SQLiteConnector) Connect() (*sql.DB, error) {
 	return db, nil
 }
 
+// sqliteReadOnlyDSN 返回以只读模式打开数据库文件的URI
+func sqliteReadOnlyDSN(path string) string {
+	return sqliteFileURI(path) + "?mode=ro"
+}
+
+// sqliteFileURI 返回数据库文件的URI，不带参数
+// 路径中URI的特殊字符需要转义，否则会被当作参数或片段
+func sqliteFileURI(path string) string {
+	escaper := strings.NewReplacer("%", "%25", "?", "%3f", "#", "%23")
+	return "file:" + escaper.Replace(filepath.ToSlash(path))
+}
+
 // GetDatabases 获取所有数据库
 // 注意：SQLite不支持多数据库，返回文件名作为数据库名
-func (c *SQLiteConnector) GetDatabases() ([]string, error) {
+func (c *SQLiteConnector) GetDatabases(ctx context.Context) ([]string, error) {
 	if c.db == nil {
 		return nil, fmt.Errorf("数据库未连接")
 	}
@@ -58,53 +77,74 @@ func (c *SQLiteConnector) GetDatabases() ([]string, error) {
 	return []string{dbName}, nil
 }
 
-// GetTables 获取指定数据库中的所有表
-func (c *SQLiteConnector) GetTables(database string) ([]string, error) {
+// GetTables 获取指定数据库中的所有表和视图
+func (c *SQLiteConnector) GetTables(ctx context.Context, database string) (tables []TableInfo, err error) {
+	ctx, done := c.config.queryContext(ctx, &err)
+	defer done()
+
 	if c.db == nil {
 		return nil, fmt.Errorf("数据库未连接")
 	}
 
-	// 查询所有表
+	// 查询所有表和视图
 	query := `
-		SELECT name 
+		SELECT name, type
 		FROM sqlite_master 
-		WHERE type='table' AND name NOT LIKE 'sqlite_%'
+		WHERE type IN ('table', 'view') AND name NOT LIKE 'sqlite_%'
 		ORDER BY name
 	`
 
-	rows, err := c.db.Query(query)
+	rows, err := c.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var tables []string
 	for rows.Next() {
-		var tableName string
-		if err := rows.Scan(&tableName); err != nil {
+		var tableName, tableType string
+		if err := rows.Scan(&tableName, &tableType); err != nil {
 			return nil, err
 		}
-		tables = append(tables, tableName)
+		tables = append(tables, TableInfo{Name: tableName, Kind: sqliteObjectKind(tableType)})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
 
 	return tables, nil
 }
 
 // GetTableMetadata 获取表的元数据信息
-func (c *SQLiteConnector) GetTableMetadata(database, table string) (*TableMetadata, error) {
+func (c *SQLiteConnector) GetTableMetadata(ctx context.Context, database, table string) (metadata *TableMetadata, err error) {
+	ctx, done := c.config.queryContext(ctx, &err)
+	defer done()
+
 	if c.db == nil {
 		return nil, fmt.Errorf("数据库未连接")
 	}
 
 	// 创建表元数据
-	metadata := &TableMetadata{
+	metadata = &TableMetadata{
 		Name:   table,
 		Fields: make([]FieldInfo, 0),
 	}
 
-	// 获取表结构信息
-	query := fmt.Sprintf("PRAGMA table_info(%s)", table)
-	rows, err := c.db.Query(query)
+	// 获取对象类型，视图的定义是完整的 CREATE VIEW 语句
+	var tableType, definition string
+	kindQuery := "SELECT type, IFNULL(sql, '') FROM sqlite_master WHERE type IN ('table', 'view') AND name = ?"
+	if err := c.db.QueryRowContext(ctx, kindQuery, table).Scan(&tableType, &definition); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("表 %s 不存在", table)
+		}
+		return nil, err
+	}
+	metadata.Kind = sqliteObjectKind(tableType)
+	if metadata.Kind == KindView {
+		metadata.Definition = definition
+	}
+
+	// 获取表结构信息，表名作为参数传入 PRAGMA 表值函数，不拼接到SQL中
+	rows, err := c.db.QueryContext(ctx, "SELECT cid, name, type, \"notnull\", dflt_value, pk FROM pragma_table_info(?)", table)
 	if err != nil {
 		return nil, err
 	}
@@ -114,11 +154,15 @@ func (c *SQLiteConnector) GetTableMetadata(database, table string) (*TableMetada
 		var cid int
 		var field FieldInfo
 		var notNull, pk int
+		var defaultValue sql.NullString
 
-		if err := rows.Scan(&cid, &field.Name, &field.Type, &notNull, &field.Default, &pk); err != nil {
+		if err := rows.Scan(&cid, &field.Name, &field.Type, &notNull, &defaultValue, &pk); err != nil {
 			return nil, err
 		}
 
+		// 处理默认值，没有默认值的字段和视图的字段为 NULL
+		field.Default = defaultValue.String
+
 		// 处理是否可为空
 		field.IsNullable = notNull == 0
 
@@ -129,10 +173,12 @@ func (c *SQLiteConnector) GetTableMetadata(database, table string) (*TableMetada
 
 		metadata.Fields = append(metadata.Fields, field)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
 
 	// 获取索引信息
-	indexListQuery := fmt.Sprintf("PRAGMA index_list(%s)", table)
-	indexRows, err := c.db.Query(indexListQuery)
+	indexRows, err := c.db.QueryContext(ctx, "SELECT seq, name, \"unique\", origin, partial FROM pragma_index_list(?)", table)
 	if err != nil {
 		return nil, err
 	}
@@ -149,8 +195,7 @@ func (c *SQLiteConnector) GetTableMetadata(database, table string) (*TableMetada
 		}
 
 		// 获取索引列
-		indexInfoQuery := fmt.Sprintf("PRAGMA index_info(%s)", indexName)
-		infoRows, err := c.db.Query(indexInfoQuery)
+		infoRows, err := c.db.QueryContext(ctx, "SELECT seqno, cid, name FROM pragma_index_info(?)", indexName)
 		if err != nil {
 			return nil, err
 		}
@@ -167,6 +212,10 @@ func (c *SQLiteConnector) GetTableMetadata(database, table string) (*TableMetada
 
 			columns = append(columns, columnName)
 		}
+		if err := infoRows.Err(); err != nil {
+			infoRows.Close()
+			return nil, err
+		}
 		infoRows.Close()
 
 		// 确定索引类型
@@ -183,10 +232,100 @@ func (c *SQLiteConnector) GetTableMetadata(database, table string) (*TableMetada
 
 		metadata.Indexes = append(metadata.Indexes, index)
 	}
+	if err := indexRows.Err(); err != nil {
+		return nil, err
+	}
 
 	return metadata, nil
 }
 
+// DescribeQuery 分析查询的结果列和参数，查询包装为 LIMIT 0 的子查询执行，参数传入 NULL
+// 只有直接来自表字段的列才有类型，表达式的类型为空；SQLite 无法确定参数类型和是否可为空
+func (c *SQLiteConnector) DescribeQuery(ctx context.Context, database, query string) (metadata *TableMetadata, err error) {
+	ctx, done := c.config.queryContext(ctx, &err)
+	defer done()
+
+	if c.db == nil {
+		return nil, fmt.Errorf("数据库未连接")
+	}
+	if query, err = trimQuery(query); err != nil {
+		return nil, err
+	}
+
+	params := positionalParams(query)
+	rows, err := c.db.QueryContext(ctx, "SELECT * FROM ("+query+"\n) LIMIT 0", nullArgs(len(params))...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	fields, err := describeColumns(rows, func(columnType *sql.ColumnType) string {
+		return strings.ToLower(columnType.DatabaseTypeName())
+	})
+	if err != nil {
+		return nil, err
+	}
+	return newQueryMetadata(query, fields, params), nil
+}
+
+// SampleColumn 读取表中最多 limit 个非空的列值
+func (c *SQLiteConnector) SampleColumn(ctx context.Context, database, table, column string, limit int) (samples []string, err error) {
+	ctx, done := c.config.queryContext(ctx, &err)
+	defer done()
+
+	if c.db == nil {
+		return nil, fmt.Errorf("数据库未连接")
+	}
+
+	query := fmt.Sprintf("SELECT %[1]s FROM %[2]s WHERE %[1]s IS NOT NULL LIMIT ?", quoteIdentifier(column), quoteIdentifier(table))
+	rows, err := c.db.QueryContext(ctx, query, sampleLimit(limit))
+	if err != nil {
+		return nil, err
+	}
+	return scanSamples(rows)
+}
+
+// PreviewRows 在事务中读取一页表数据，数据库以只读模式打开，不能写入
+// SQLite 没有服务器端的语句超时，超时后通过取消上下文中断查询
+func (c *SQLiteConnector) PreviewRows(ctx context.Context, database, table string, request PreviewRequest) (page *PreviewPage, err error) {
+	ctx, done := c.config.queryContext(ctx, &err)
+	defer done()
+
+	if c.db == nil {
+		return nil, fmt.Errorf("数据库未连接")
+	}
+
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	limit := previewLimit(request.Limit)
+	query := "SELECT * FROM " + quoteIdentifier(table)
+	var args []interface{}
+	if request.FilterColumn != "" {
+		query += " WHERE instr(CAST(" + quoteIdentifier(request.FilterColumn) + " AS TEXT), ?) > 0"
+		args = append(args, request.FilterValue)
+	}
+	query += previewOrderBy(request.OrderBy, quoteIdentifier, "") + " LIMIT ? OFFSET ?"
+	args = append(args, limit+1, previewOffset(request.Offset))
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return readPreviewPage(rows, limit)
+}
+
+// sqliteObjectKind 根据 sqlite_master.type 确定对象类型
+func sqliteObjectKind(tableType string) ObjectKind {
+	if tableType == "view" {
+		return KindView
+	}
+	return KindTable
+}
+
 // Close 关闭数据库连接
 func (c *SQLiteConnector) Close() error {
 	if c.db != nil {
@@ -0,0 +1,179 @@
+package connector
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// hostileNames 是用作表名、列名和索引名的恶意名称
+var hostileNames = []string{
+	`x"); DROP TABLE victim; --`,
+	`x'); DROP TABLE victim; --`,
+	"back`tick",
+	`[bracket]`,
+	`semi;colon`,
+	`space name`,
+	`we"ird`,
+	`percent%?#`,
+}
+
+// quoteSQLiteFixture 使用双引号引用SQLite标识符，只用于创建测试数据
+func quoteSQLiteFixture(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// createHostileFixture 创建测试数据库：每个恶意名称对应一个表，列名与表名相同，索引名加 idx 前缀
+func createHostileFixture(t *testing.T, path string) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	db, err := sql.Open("sqlite3", sqliteFileURI(path)+"?mode=rwc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	statements := []string{
+		"CREATE TABLE victim (id INTEGER PRIMARY KEY)",
+		"INSERT INTO victim (id) VALUES (1)",
+	}
+	for _, name := range hostileNames {
+		quoted := quoteSQLiteFixture(name)
+		statements = append(statements,
+			fmt.Sprintf("CREATE TABLE %s (id INTEGER PRIMARY KEY, %s TEXT)", quoted, quoted),
+			fmt.Sprintf("CREATE INDEX %s ON %s (%s)", quoteSQLiteFixture("idx "+name), quoted, quoted),
+			fmt.Sprintf("INSERT INTO %s (id, %s) VALUES (1, 'value')", quoted, quoted),
+		)
+	}
+	for _, statement := range statements {
+		if _, err := db.Exec(statement); err != nil {
+			t.Fatalf("%s: %v", statement, err)
+		}
+	}
+}
+
+// openHostileFixture 创建测试数据库并通过连接器打开，文件路径中也包含URI的特殊字符
+func openHostileFixture(t *testing.T) (*SQLiteConnector, *sql.DB) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "we?ird#dir", "data%20.db")
+	createHostileFixture(t, path)
+
+	conn := NewSQLiteConnector(&ConnectionConfig{Type: "SQLite", Host: path})
+	db, err := conn.Connect(context.Background())
+	if err != nil {
+		t.Fatalf("连接失败: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn, db
+}
+
+func TestSQLiteHostileNames(t *testing.T) {
+	conn, _ := openHostileFixture(t)
+	ctx := context.Background()
+
+	tables, err := conn.GetTables(ctx, "main")
+	if err != nil {
+		t.Fatalf("读取表列表失败: %v", err)
+	}
+	present := make(map[string]bool)
+	for _, table := range tables {
+		present[table.Name] = true
+	}
+
+	for _, name := range hostileNames {
+		t.Run(name, func(t *testing.T) {
+			if !present[name] {
+				t.Errorf("表列表不包含 %q", name)
+			}
+
+			metadata, err := conn.GetTableMetadata(ctx, "main", name)
+			if err != nil {
+				t.Fatalf("读取表结构失败: %v", err)
+			}
+			if len(metadata.Fields) != 2 || !metadata.Fields[0].IsPrimary || metadata.Fields[1].Name != name {
+				t.Errorf("字段为 %+v，应为主键列和同名的列", metadata.Fields)
+			}
+			if len(metadata.Indexes) != 1 || metadata.Indexes[0].Name != "idx "+name ||
+				len(metadata.Indexes[0].Columns) != 1 || metadata.Indexes[0].Columns[0] != name {
+				t.Errorf("索引为 %+v，应包含同名的列", metadata.Indexes)
+			}
+
+			page, err := conn.PreviewRows(ctx, "main", name, PreviewRequest{
+				FilterColumn: name,
+				FilterValue:  "value",
+				OrderBy:      []string{"id"},
+			})
+			if err != nil {
+				t.Fatalf("按同名的列筛选预览失败: %v", err)
+			}
+			if len(page.Rows) != 1 {
+				t.Errorf("预览返回 %d 行，应为 1 行", len(page.Rows))
+			}
+
+			samples, err := conn.SampleColumn(ctx, "main", name, name, 10)
+			if err != nil {
+				t.Fatalf("读取样本失败: %v", err)
+			}
+			if len(samples) != 1 {
+				t.Errorf("样本为 %q，应为 1 个值", samples)
+			}
+		})
+	}
+}
+
+func TestSQLiteSessionIsReadOnly(t *testing.T) {
+	_, db := openHostileFixture(t)
+	ctx := context.Background()
+
+	if _, err := db.ExecContext(ctx, "DELETE FROM victim"); err == nil {
+		t.Error("只读会话应当拒绝删除数据")
+	}
+	if _, err := db.ExecContext(ctx, "CREATE TABLE injected (id INTEGER)"); err == nil {
+		t.Error("只读会话应当拒绝创建表")
+	}
+
+	// 受害表完好
+	var count int
+	if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM victim").Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Errorf("受害表有 %d 行数据，应为 1 行", count)
+	}
+}
+
+// hostileQueries 是试图结束包装的子查询并执行其他语句的查询
+var hostileQueries = []string{
+	"SELECT 1) q; DELETE FROM victim; SELECT * FROM (SELECT 1",
+	"SELECT 1) q; SET default_transaction_read_only=off; COMMIT; DELETE FROM victim; SELECT * FROM (SELECT 1",
+	"SELECT $$'$$) q; DELETE FROM victim; -- '",
+	"SELECT '--' AS a) q; DELETE FROM victim; SELECT * FROM (SELECT 1",
+	"SELECT 1 /* ; */) q; DROP TABLE victim; --",
+}
+
+func TestSQLiteDescribeQueryRejectsHostileQueries(t *testing.T) {
+	conn, db := openHostileFixture(t)
+	ctx := context.Background()
+
+	for _, query := range hostileQueries {
+		if _, err := conn.DescribeQuery(ctx, "main", query); err == nil {
+			t.Errorf("DescribeQuery(%q) 应当拒绝多条语句", query)
+		}
+	}
+
+	var count int
+	if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM victim").Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Errorf("受害表有 %d 行数据，应为 1 行", count)
+	}
+}
@@ -0,0 +1,948 @@
+package connector
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	mssql "github.com/microsoft/go-mssqldb"
+	"github.com/microsoft/go-mssqldb/msdsn"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// sqlServerDefaultSchema 是SQL Server的默认架构，其中的表不带架构名显示
+const sqlServerDefaultSchema = "dbo"
+
+// SQLServerConnector 实现SQL Server数据库连接器
+// 其他架构中的表名为 架构.表名，dbo 架构中的表只显示表名
+type SQLServerConnector struct {
+	config *ConnectionConfig
+	db     *sql.DB
+	tunnel *SSHTunnel
+}
+
+// NewSQLServerConnector 创建一个新的SQL Server连接器
+func NewSQLServerConnector(config *ConnectionConfig) *SQLServerConnector {
+	return &SQLServerConnector{
+		config: config,
+	}
+}
+
+// Connect 连接到SQL Server数据库
+func (c *SQLServerConnector) Connect(ctx context.Context) (db *sql.DB, err error) {
+	ctx, done := c.config.connectContext(ctx, &err)
+	defer done()
+
+	// 主机可以写作 主机\实例名 连接命名实例
+	host, instance, _ := strings.Cut(c.config.Host, `\`)
+	dsn := &url.URL{
+		Scheme: "sqlserver",
+		User:   url.UserPassword(c.config.Username, c.config.Password),
+		Host:   host,
+		Path:   instance,
+	}
+	if c.config.Port != "" {
+		dsn.Host = net.JoinHostPort(host, c.config.Port)
+	}
+	if c.config.Database != "" {
+		dsn.RawQuery = url.Values{"database": {c.config.Database}}.Encode()
+	}
+	params, err := msdsn.Parse(dsn.String())
+	if err != nil {
+		return nil, fmt.Errorf("连接SQL Server失败: %v", err)
+	}
+
+	// 登录时声明 ApplicationIntent=ReadOnly，连接可用性组侦听器并指定了数据库时路由到只读副本。
+	// 驱动解析DSN时要求同时指定数据库，因此直接设置，未指定数据库的连接也声明只读意图
+	params.ReadOnlyIntent = true
+
+	// 未启用TLS时按协议只加密登录过程，服务器默认使用自签名证书，因此不校验证书
+	if c.config.TLS.enabled() {
+		tlsConfig, err := c.config.TLS.clientConfig(host)
+		if err != nil {
+			return nil, err
+		}
+		params.Encryption = msdsn.EncryptionRequired
+		params.TLSConfig = tlsConfig
+	} else {
+		params.Encryption = msdsn.EncryptionOff
+		params.TLSConfig, err = msdsn.SetupTLS("", true, host, "")
+		if err != nil {
+			return nil, err
+		}
+	}
+	// SQL Server 要求每个TDS包对应一个TLS记录
+	params.TLSConfig.DynamicRecordSizingDisabled = true
+
+	// 驱动登录时不响应 ctx 取消，只遵守每次读写的超时。读写超时对整个会话有效，
+	// 因此取连接和查询超时中较长的一个并多留一秒，不影响正常的查询
+	params.ConnTimeout = c.config.connectTimeout()
+	if timeout := c.config.queryTimeout(); timeout > params.ConnTimeout {
+		params.ConnTimeout = timeout
+	}
+	params.ConnTimeout += time.Second
+
+	// 配置了SSH隧道时通过跳板机连接，主机名在跳板机上解析
+	sqlConnector := mssql.NewConnectorConfig(params)
+	if c.config.SSH != nil {
+		tunnel, err := NewSSHTunnel(ctx, c.config.SSH)
+		if err != nil {
+			return nil, err
+		}
+		sqlConnector.Dialer = tunnelHostDialer{tunnel: tunnel, host: host}
+		c.tunnel = tunnel
+	}
+	db = sql.OpenDB(sqlConnector)
+
+	// 测试连接
+	if err := pingContext(ctx, db); err != nil {
+		db.Close()
+		c.Close()
+		return nil, fmt.Errorf("SQL Server连接测试失败: %v", err)
+	}
+
+	c.db = db
+	return db, nil
+}
+
+// tunnelHostDialer 通过SSH隧道连接SQL Server
+// 实现 mssql.HostDialer 接口，驱动不会在本地解析主机名
+type tunnelHostDialer struct {
+	tunnel *SSHTunnel
+	host   string
+}
+
+// DialContext 实现 mssql.Dialer 接口
+func (d tunnelHostDialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	return d.tunnel.DialContext(ctx, network, address)
+}
+
+// HostName 实现 mssql.HostDialer 接口
+func (d tunnelHostDialer) HostName() string {
+	return d.host
+}
+
+// quoteSQLServerIdentifier 用方括号引用SQL Server标识符
+func quoteSQLServerIdentifier(name string) string {
+	return "[" + strings.ReplaceAll(name, "]", "]]") + "]"
+}
+
+// sqlServerTableName 返回表在表列表中显示的名称
+func sqlServerTableName(schema, table string) string {
+	if schema == sqlServerDefaultSchema && !strings.Contains(table, ".") {
+		return table
+	}
+	return schema + "." + table
+}
+
+// splitSQLServerTableName 将表列表中的名称拆分为架构和表名
+func splitSQLServerTableName(name string) (schema, table string) {
+	if schema, table, ok := strings.Cut(name, "."); ok {
+		return schema, table
+	}
+	return sqlServerDefaultSchema, name
+}
+
+// GetDatabases 获取所有数据库
+func (c *SQLServerConnector) GetDatabases(ctx context.Context) (databases []string, err error) {
+	ctx, done := c.config.queryContext(ctx, &err)
+	defer done()
+
+	if c.db == nil {
+		return nil, fmt.Errorf("数据库未连接")
+	}
+
+	// 驱动不支持只读事务，在总是回滚的事务中读取，语句做出的修改不会提交
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// 查询所有在线的数据库，过滤 master、tempdb、model 和 msdb 系统数据库
+	query := `
+		SELECT name
+		FROM sys.databases
+		WHERE database_id > 4 AND state = 0
+		ORDER BY name
+	`
+
+	rows, err := tx.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var dbName string
+		if err := rows.Scan(&dbName); err != nil {
+			return nil, err
+		}
+		databases = append(databases, dbName)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return databases, nil
+}
+
+// sqlServerObjectKind 返回计算 sys.objects 中对象 o 类型的SQL表达式，db 为带括号的数据库名
+// 有聚集索引的视图（索引视图）作为物化视图，堆或聚集索引位于分区方案上的表作为分区表
+func sqlServerObjectKind(db string) string {
+	return `CASE
+			WHEN o.type = 'V' AND EXISTS (
+				SELECT 1 FROM ` + db + `.sys.indexes i WHERE i.object_id = o.object_id AND i.index_id = 1
+			) THEN '` + string(KindMaterializedView) + `'
+			WHEN o.type = 'V' THEN '` + string(KindView) + `'
+			WHEN EXISTS (
+				SELECT 1 FROM ` + db + `.sys.indexes i
+					JOIN ` + db + `.sys.partition_schemes ps ON ps.data_space_id = i.data_space_id
+				WHERE i.object_id = o.object_id AND i.index_id IN (0, 1)
+			) THEN '` + string(KindPartitionedTable) + `'
+			ELSE '` + string(KindTable) + `'
+		END`
+}
+
+// GetTables 获取指定数据库中所有架构的表和视图
+func (c *SQLServerConnector) GetTables(ctx context.Context, database string) (tables []TableInfo, err error) {
+	ctx, done := c.config.queryContext(ctx, &err)
+	defer done()
+
+	if c.db == nil {
+		return nil, fmt.Errorf("数据库未连接")
+	}
+
+	// 与 GetDatabases 相同，在总是回滚的事务中读取
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// 连接池中的连接可能位于不同的数据库，查询都使用 数据库.sys.视图 的三部分名称
+	db := quoteSQLServerIdentifier(database)
+	query := `
+		SELECT s.name, o.name, ` + sqlServerObjectKind(db) + `
+		FROM ` + db + `.sys.objects o
+			JOIN ` + db + `.sys.schemas s ON s.schema_id = o.schema_id
+		WHERE o.type IN ('U', 'V') AND o.is_ms_shipped = 0
+		ORDER BY s.name, o.name
+	`
+
+	rows, err := tx.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var schemaName, tableName string
+		var kind ObjectKind
+		if err := rows.Scan(&schemaName, &tableName, &kind); err != nil {
+			return nil, err
+		}
+		tables = append(tables, TableInfo{Name: sqlServerTableName(schemaName, tableName), Kind: kind})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return tables, nil
+}
+
+// GetTableMetadata 获取表的元数据信息，包括字段说明（MS_Description）、标识列、索引和外键
+func (c *SQLServerConnector) GetTableMetadata(ctx context.Context, database, table string) (metadata *TableMetadata, err error) {
+	ctx, done := c.config.queryContext(ctx, &err)
+	defer done()
+
+	if c.db == nil {
+		return nil, fmt.Errorf("数据库未连接")
+	}
+
+	// 与 GetDatabases 相同，在总是回滚的事务中读取
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	schema, name := splitSQLServerTableName(table)
+	metadata = &TableMetadata{
+		Name:   name,
+		Schema: schema,
+		Fields: make([]FieldInfo, 0),
+	}
+
+	// 所有查询都通过 OBJECT_ID 按三部分名称定位表
+	db := quoteSQLServerIdentifier(database)
+	objectName := db + "." + quoteSQLServerIdentifier(schema) + "." + quoteSQLServerIdentifier(name)
+
+	// 获取对象类型，视图同时获取定义（完整的 CREATE VIEW 语句，加密的视图为空）
+	kindQuery := `
+		SELECT ` + sqlServerObjectKind(db) + `, ISNULL(m.definition, '')
+		FROM ` + db + `.sys.objects o
+			LEFT JOIN ` + db + `.sys.sql_modules m ON m.object_id = o.object_id
+		WHERE o.object_id = OBJECT_ID(@p1) AND o.type IN ('U', 'V')
+	`
+	if err := tx.QueryRowContext(ctx, kindQuery, objectName).Scan(&metadata.Kind, &metadata.Definition); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("表 %s 不存在", table)
+		}
+		return nil, err
+	}
+
+	// 获取表字段信息，用户定义的别名类型使用其基础类型
+	query := `
+		SELECT
+			c.name,
+			CASE WHEN t.is_user_defined = 1 THEN TYPE_NAME(c.system_type_id) ELSE t.name END,
+			c.max_length,
+			c.is_nullable,
+			c.is_identity,
+			ISNULL(d.definition, ''),
+			ISNULL(CAST(p.value AS NVARCHAR(4000)), '')
+		FROM ` + db + `.sys.columns c
+			JOIN ` + db + `.sys.types t ON t.user_type_id = c.user_type_id
+			LEFT JOIN ` + db + `.sys.default_constraints d ON d.object_id = c.default_object_id
+			LEFT JOIN ` + db + `.sys.extended_properties p
+				ON p.class = 1 AND p.major_id = c.object_id AND p.minor_id = c.column_id AND p.name = 'MS_Description'
+		WHERE c.object_id = OBJECT_ID(@p1)
+		ORDER BY c.column_id
+	`
+
+	rows, err := tx.QueryContext(ctx, query, objectName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var field FieldInfo
+		var maxLength int
+
+		if err := rows.Scan(
+			&field.Name,
+			&field.Type,
+			&maxLength,
+			&field.IsNullable,
+			&field.IsIdentity,
+			&field.Default,
+			&field.Comment,
+		); err != nil {
+			return nil, err
+		}
+
+		// 处理长度，max_length 为字节数，-1 表示 (max)
+		switch {
+		case maxLength == -1:
+			field.Type += "(max)"
+		case field.Type == "nchar" || field.Type == "nvarchar":
+			field.Length = maxLength / 2
+		case field.Type == "char" || field.Type == "varchar" || field.Type == "binary" || field.Type == "varbinary":
+			field.Length = maxLength
+		}
+
+		// 默认值保存为 ((0)) 或 (getdate()) 的形式，去掉外层括号
+		field.Default = trimSQLServerDefault(field.Default)
+
+		metadata.Fields = append(metadata.Fields, field)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(metadata.Fields) == 0 {
+		return nil, fmt.Errorf("表 %s 不存在", table)
+	}
+
+	// 获取索引信息，不包括 INCLUDE 列
+	indexQuery := `
+		SELECT
+			i.name,
+			i.type_desc,
+			i.is_primary_key,
+			i.is_unique,
+			c.name
+		FROM ` + db + `.sys.indexes i
+			JOIN ` + db + `.sys.index_columns ic ON ic.object_id = i.object_id AND ic.index_id = i.index_id
+			JOIN ` + db + `.sys.columns c ON c.object_id = ic.object_id AND c.column_id = ic.column_id
+		WHERE i.object_id = OBJECT_ID(@p1) AND ic.is_included_column = 0
+		ORDER BY i.index_id, ic.key_ordinal
+	`
+
+	indexRows, err := tx.QueryContext(ctx, indexQuery, objectName)
+	if err != nil {
+		return nil, err
+	}
+	defer indexRows.Close()
+
+	indexPositions := make(map[string]int)
+	for indexRows.Next() {
+		var indexName, indexType, columnName string
+		var isPrimary, isUnique bool
+
+		if err := indexRows.Scan(&indexName, &indexType, &isPrimary, &isUnique, &columnName); err != nil {
+			return nil, err
+		}
+
+		// 如果索引不存在，创建它
+		position, exists := indexPositions[indexName]
+		if !exists {
+			switch {
+			case isPrimary:
+				indexType = "PRIMARY"
+			case isUnique:
+				indexType = "UNIQUE"
+			}
+			position = len(metadata.Indexes)
+			indexPositions[indexName] = position
+			metadata.Indexes = append(metadata.Indexes, IndexInfo{Name: indexName, Type: indexType})
+		}
+
+		// 添加列到索引
+		metadata.Indexes[position].Columns = append(metadata.Indexes[position].Columns, columnName)
+	}
+	if err := indexRows.Err(); err != nil {
+		return nil, err
+	}
+
+	// 根据索引标记主键列和单列唯一键
+	for _, index := range metadata.Indexes {
+		for i := range metadata.Fields {
+			field := &metadata.Fields[i]
+			switch {
+			case index.Type == "PRIMARY" && containsString(index.Columns, field.Name):
+				field.IsPrimary = true
+			case index.Type == "UNIQUE" && len(index.Columns) == 1 && index.Columns[0] == field.Name:
+				field.IsUnique = true
+			}
+		}
+	}
+
+	// 获取外键信息
+	foreignKeyQuery := `
+		SELECT
+			fk.name,
+			pc.name,
+			rs.name,
+			rt.name,
+			rc.name
+		FROM ` + db + `.sys.foreign_keys fk
+			JOIN ` + db + `.sys.foreign_key_columns fkc ON fkc.constraint_object_id = fk.object_id
+			JOIN ` + db + `.sys.columns pc ON pc.object_id = fkc.parent_object_id AND pc.column_id = fkc.parent_column_id
+			JOIN ` + db + `.sys.objects rt ON rt.object_id = fkc.referenced_object_id
+			JOIN ` + db + `.sys.schemas rs ON rs.schema_id = rt.schema_id
+			JOIN ` + db + `.sys.columns rc ON rc.object_id = fkc.referenced_object_id AND rc.column_id = fkc.referenced_column_id
+		WHERE fk.parent_object_id = OBJECT_ID(@p1)
+		ORDER BY fk.name, fkc.constraint_column_id
+	`
+
+	foreignKeyRows, err := tx.QueryContext(ctx, foreignKeyQuery, objectName)
+	if err != nil {
+		return nil, err
+	}
+	defer foreignKeyRows.Close()
+
+	foreignKeyPositions := make(map[string]int)
+	for foreignKeyRows.Next() {
+		var keyName, columnName, refSchema, refTable, refColumn string
+
+		if err := foreignKeyRows.Scan(&keyName, &columnName, &refSchema, &refTable, &refColumn); err != nil {
+			return nil, err
+		}
+
+		position, exists := foreignKeyPositions[keyName]
+		if !exists {
+			position = len(metadata.ForeignKeys)
+			foreignKeyPositions[keyName] = position
+			metadata.ForeignKeys = append(metadata.ForeignKeys, ForeignKeyInfo{
+				Name:     keyName,
+				RefTable: sqlServerTableName(refSchema, refTable),
+			})
+		}
+
+		key := &metadata.ForeignKeys[position]
+		key.Columns = append(key.Columns, columnName)
+		key.RefColumns = append(key.RefColumns, refColumn)
+	}
+	if err := foreignKeyRows.Err(); err != nil {
+		return nil, err
+	}
+
+	return metadata, nil
+}
+
+// GetRoutines 获取指定数据库中所有架构的存储过程、标量函数和表值函数
+// OUTPUT 参数作为 OUT 参数，调用时不传入值
+func (c *SQLServerConnector) GetRoutines(ctx context.Context, database string) (routines []Routine, err error) {
+	ctx, done := c.config.queryContext(ctx, &err)
+	defer done()
+
+	if c.db == nil {
+		return nil, fmt.Errorf("数据库未连接")
+	}
+
+	// 与 GetDatabases 相同，在总是回滚的事务中读取
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// P 存储过程，FN 标量函数，IF 内联表值函数，TF 多语句表值函数
+	db := quoteSQLServerIdentifier(database)
+	query := `
+		SELECT
+			o.object_id,
+			s.name,
+			o.name,
+			RTRIM(o.type),
+			ISNULL(CAST(p.value AS NVARCHAR(4000)), '')
+		FROM ` + db + `.sys.objects o
+			JOIN ` + db + `.sys.schemas s ON s.schema_id = o.schema_id
+			LEFT JOIN ` + db + `.sys.extended_properties p
+				ON p.class = 1 AND p.major_id = o.object_id AND p.minor_id = 0 AND p.name = 'MS_Description'
+		WHERE o.type IN ('P', 'FN', 'IF', 'TF') AND o.is_ms_shipped = 0
+		ORDER BY s.name, o.name
+	`
+
+	rows, err := tx.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	positions := make(map[int]int)
+	for rows.Next() {
+		var routine Routine
+		var objectID int
+		var objectType string
+
+		if err := rows.Scan(&objectID, &routine.Schema, &routine.Name, &objectType, &routine.Comment); err != nil {
+			return nil, err
+		}
+
+		routine.Kind = RoutineFunction
+		switch objectType {
+		case "P":
+			routine.Kind = RoutineProcedure
+		case "IF", "TF":
+			routine.ReturnsSet = true
+		}
+
+		positions[objectID] = len(routines)
+		routines = append(routines, routine)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// 获取参数，标量函数的返回值是 parameter_id 为0的参数
+	paramQuery := `
+		SELECT
+			pr.object_id,
+			pr.parameter_id,
+			pr.name,
+			pr.is_output,
+			CASE WHEN t.is_user_defined = 1 THEN TYPE_NAME(pr.system_type_id) ELSE t.name END,
+			pr.max_length,
+			pr.precision,
+			pr.scale
+		FROM ` + db + `.sys.parameters pr
+			JOIN ` + db + `.sys.types t ON t.user_type_id = pr.user_type_id
+			JOIN ` + db + `.sys.objects o ON o.object_id = pr.object_id
+		WHERE o.type IN ('P', 'FN', 'IF', 'TF') AND o.is_ms_shipped = 0
+		ORDER BY pr.object_id, pr.parameter_id
+	`
+
+	paramRows, err := tx.QueryContext(ctx, paramQuery)
+	if err != nil {
+		return nil, err
+	}
+	defer paramRows.Close()
+
+	for paramRows.Next() {
+		var objectID, parameterID, maxLength, precision, scale int
+		var name, typeName string
+		var isOutput bool
+
+		if err := paramRows.Scan(&objectID, &parameterID, &name, &isOutput, &typeName, &maxLength, &precision, &scale); err != nil {
+			return nil, err
+		}
+
+		i, exists := positions[objectID]
+		if !exists {
+			continue
+		}
+		paramType := sqlServerDeclaredType(typeName, maxLength, precision, scale)
+		if parameterID == 0 {
+			if !routines[i].ReturnsSet {
+				routines[i].ReturnType = paramType
+			}
+			continue
+		}
+
+		param := RoutineParam{
+			Name: routineParamName(strings.TrimPrefix(name, "@"), parameterID),
+			Mode: ParamIn,
+			Type: paramType,
+		}
+		if isOutput {
+			param.Mode = ParamOut
+		}
+		routines[i].Params = append(routines[i].Params, param)
+	}
+	if err := paramRows.Err(); err != nil {
+		return nil, err
+	}
+
+	// 获取表值函数返回的列
+	columnQuery := `
+		SELECT
+			c.object_id,
+			c.name,
+			CASE WHEN t.is_user_defined = 1 THEN TYPE_NAME(c.system_type_id) ELSE t.name END,
+			c.max_length,
+			c.precision,
+			c.scale
+		FROM ` + db + `.sys.columns c
+			JOIN ` + db + `.sys.types t ON t.user_type_id = c.user_type_id
+			JOIN ` + db + `.sys.objects o ON o.object_id = c.object_id
+		WHERE o.type IN ('IF', 'TF') AND o.is_ms_shipped = 0
+		ORDER BY c.object_id, c.column_id
+	`
+
+	columnRows, err := tx.QueryContext(ctx, columnQuery)
+	if err != nil {
+		return nil, err
+	}
+	defer columnRows.Close()
+
+	for columnRows.Next() {
+		var objectID, maxLength, precision, scale int
+		var column RoutineParam
+		var typeName string
+
+		if err := columnRows.Scan(&objectID, &column.Name, &typeName, &maxLength, &precision, &scale); err != nil {
+			return nil, err
+		}
+
+		if i, exists := positions[objectID]; exists {
+			column.Type = sqlServerDeclaredType(typeName, maxLength, precision, scale)
+			routines[i].Columns = append(routines[i].Columns, column)
+		}
+	}
+	if err := columnRows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range routines {
+		sqlServerRoutineCall(&routines[i])
+	}
+
+	return routines, nil
+}
+
+// sqlServerDeclaredType 返回带长度或精度的类型，例如 nvarchar(50)、nvarchar(max) 和 decimal(18,2)
+// max_length 为字节数，-1 表示 (max)
+func sqlServerDeclaredType(typeName string, maxLength, precision, scale int) string {
+	switch typeName {
+	case "nchar", "nvarchar":
+		if maxLength == -1 {
+			return typeName + "(max)"
+		}
+		return fmt.Sprintf("%s(%d)", typeName, maxLength/2)
+	case "char", "varchar", "binary", "varbinary":
+		if maxLength == -1 {
+			return typeName + "(max)"
+		}
+		return fmt.Sprintf("%s(%d)", typeName, maxLength)
+	case "decimal", "numeric":
+		return fmt.Sprintf("%s(%d,%d)", typeName, precision, scale)
+	case "datetime2", "datetimeoffset", "time":
+		return fmt.Sprintf("%s(%d)", typeName, scale)
+	}
+	return typeName
+}
+
+// sqlServerRoutineCall 生成调用例程的SQL和签名，占位符为 @p1、@p2 等
+// 存储过程的 OUTPUT 参数保存在局部变量中，执行后作为结果行返回
+func sqlServerRoutineCall(routine *Routine) {
+	name := quoteSQLServerIdentifier(routine.Schema) + "." + quoteSQLServerIdentifier(routine.Name)
+
+	types := make([]string, 0, len(routine.Params))
+	var args, declares, outputs []string
+	for _, param := range routine.Params {
+		types = append(types, param.Type)
+		if param.Mode == ParamOut {
+			variable := "@out_" + param.Name
+			declares = append(declares, "DECLARE "+variable+" "+param.Type)
+			args = append(args, "@"+param.Name+" = "+variable+" OUTPUT")
+			outputs = append(outputs, variable+" AS "+quoteSQLServerIdentifier(param.Name))
+			continue
+		}
+
+		routine.CallArgs = append(routine.CallArgs, param.Name)
+		placeholder := "@p" + strconv.Itoa(len(routine.CallArgs))
+		if routine.Kind == RoutineProcedure {
+			placeholder = "@" + param.Name + " = " + placeholder
+		}
+		args = append(args, placeholder)
+	}
+	routine.Signature = routine.Name + "(" + strings.Join(types, ", ") + ")"
+	if routine.Schema != sqlServerDefaultSchema {
+		routine.Signature = routine.Schema + "." + routine.Signature
+	}
+
+	switch {
+	case routine.Kind == RoutineProcedure:
+		statements := append(declares, strings.TrimSpace("EXEC "+name+" "+strings.Join(args, ", ")))
+		if len(outputs) > 0 {
+			statements = append(statements, "SELECT "+strings.Join(outputs, ", "))
+		}
+		routine.CallSQL = strings.Join(statements, "; ")
+	case routine.ReturnsSet:
+		routine.CallSQL = "SELECT * FROM " + name + "(" + strings.Join(args, ", ") + ")"
+	default:
+		routine.CallSQL = "SELECT " + name + "(" + strings.Join(args, ", ") + ") AS result"
+	}
+}
+
+// trimSQLServerDefault 去掉SQL Server默认值定义外层的括号
+func trimSQLServerDefault(definition string) string {
+	for len(definition) >= 2 && definition[0] == '(' && definition[len(definition)-1] == ')' {
+		inner := definition[1 : len(definition)-1]
+		// 只有外层括号互相匹配时才能去掉，例如 (1)+(2) 不能去掉
+		depth := 0
+		for _, ch := range inner {
+			if ch == '(' {
+				depth++
+			} else if ch == ')' {
+				depth--
+				if depth < 0 {
+					return definition
+				}
+			}
+		}
+		definition = inner
+	}
+	return definition
+}
+
+// containsString 判断列表中是否包含指定字符串
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// DescribeQuery 分析查询的结果列和参数类型，不执行查询
+// 参数类型来自 sp_describe_undeclared_parameters，结果列来自 sys.dm_exec_describe_first_result_set，
+// 因此包含 ORDER BY 的查询也可以分析；参数使用 @名称 的形式
+func (c *SQLServerConnector) DescribeQuery(ctx context.Context, database, query string) (metadata *TableMetadata, err error) {
+	ctx, done := c.config.queryContext(ctx, &err)
+	defer done()
+
+	if c.db == nil {
+		return nil, fmt.Errorf("数据库未连接")
+	}
+	if query, err = trimQuery(query); err != nil {
+		return nil, err
+	}
+
+	// 在同一个事务的连接上切换数据库并分析，其他查询都使用三部分名称，不受 USE 影响
+	// 与读取元数据相同，事务总是回滚
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	if _, err := tx.ExecContext(ctx, "USE "+quoteSQLServerIdentifier(database)); err != nil {
+		return nil, err
+	}
+
+	// 获取参数，结果集的列较多，按名称读取需要的列
+	paramRows, err := tx.QueryContext(ctx, "EXEC sys.sp_describe_undeclared_parameters @tsql = @p1", query)
+	if err != nil {
+		return nil, err
+	}
+	defer paramRows.Close()
+
+	columns, err := paramRows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	var params []FieldInfo
+	var declarations []string
+	for paramRows.Next() {
+		values := make([]interface{}, len(columns))
+		targets := make([]interface{}, len(columns))
+		for i := range values {
+			targets[i] = &values[i]
+		}
+		if err := paramRows.Scan(targets...); err != nil {
+			return nil, err
+		}
+
+		var name, typeName string
+		for i, column := range columns {
+			switch column {
+			case "name":
+				name, _ = values[i].(string)
+			case "suggested_system_type_name":
+				typeName, _ = values[i].(string)
+			}
+		}
+		declarations = append(declarations, name+" "+typeName)
+		params = append(params, FieldInfo{Name: strings.TrimPrefix(name, "@"), Type: typeName})
+	}
+	if err := paramRows.Err(); err != nil {
+		return nil, err
+	}
+
+	// 获取结果列，查询无法分析时 error_message 不为空
+	columnQuery := `
+		SELECT
+			ISNULL(name, ''),
+			ISNULL(is_nullable, 1),
+			ISNULL(system_type_name, ''),
+			ISNULL(max_length, 0),
+			ISNULL(error_message, '')
+		FROM sys.dm_exec_describe_first_result_set(@p1, @p2, 0)
+		WHERE ISNULL(is_hidden, 0) = 0
+		ORDER BY column_ordinal
+	`
+	rows, err := tx.QueryContext(ctx, columnQuery, query, strings.Join(declarations, ", "))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	fields := make([]FieldInfo, 0)
+	for rows.Next() {
+		var field FieldInfo
+		var maxLength int
+		var errorMessage string
+
+		if err := rows.Scan(&field.Name, &field.IsNullable, &field.Type, &maxLength, &errorMessage); err != nil {
+			return nil, err
+		}
+		if errorMessage != "" {
+			return nil, errors.New(errorMessage)
+		}
+
+		// 没有名称的表达式列使用位置命名
+		if field.Name == "" {
+			field.Name = "column" + strconv.Itoa(len(fields)+1)
+		}
+
+		// 处理长度，类型名称中已包含长度，max_length 为字节数，-1 表示 (max)
+		baseType, _, _ := strings.Cut(field.Type, "(")
+		switch {
+		case maxLength == -1:
+		case baseType == "nchar" || baseType == "nvarchar":
+			field.Length = maxLength / 2
+		case baseType == "char" || baseType == "varchar" || baseType == "binary" || baseType == "varbinary":
+			field.Length = maxLength
+		}
+
+		fields = append(fields, field)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("查询没有返回结果集")
+	}
+
+	return newQueryMetadata(query, fields, params), nil
+}
+
+// SampleColumn 读取表中最多 limit 个非空的列值，JSON保存在字符串列中
+func (c *SQLServerConnector) SampleColumn(ctx context.Context, database, table, column string, limit int) (samples []string, err error) {
+	ctx, done := c.config.queryContext(ctx, &err)
+	defer done()
+
+	if c.db == nil {
+		return nil, fmt.Errorf("数据库未连接")
+	}
+
+	// 与 GetDatabases 相同，在总是回滚的事务中读取
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	schema, name := splitSQLServerTableName(table)
+	query := fmt.Sprintf("SELECT TOP (@p1) CAST(%[1]s AS NVARCHAR(MAX)) FROM %[2]s.%[3]s.%[4]s WHERE %[1]s IS NOT NULL",
+		quoteSQLServerIdentifier(column), quoteSQLServerIdentifier(database), quoteSQLServerIdentifier(schema), quoteSQLServerIdentifier(name))
+	rows, err := tx.QueryContext(ctx, query, sampleLimit(limit))
+	if err != nil {
+		return nil, err
+	}
+	return scanSamples(rows)
+}
+
+// PreviewRows 在事务中读取一页表数据，事务结束时回滚
+// 驱动不支持只读事务，SET LOCK_TIMEOUT 使等待锁的语句在查询超时后失败
+func (c *SQLServerConnector) PreviewRows(ctx context.Context, database, table string, request PreviewRequest) (page *PreviewPage, err error) {
+	ctx, done := c.config.queryContext(ctx, &err)
+	defer done()
+
+	if c.db == nil {
+		return nil, fmt.Errorf("数据库未连接")
+	}
+
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if timeout := timeoutMillis(ctx); timeout > 0 {
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf("SET LOCK_TIMEOUT %d", timeout)); err != nil {
+			return nil, err
+		}
+	}
+
+	limit := previewLimit(request.Limit)
+	schema, name := splitSQLServerTableName(table)
+	query := fmt.Sprintf("SELECT * FROM %s.%s.%s",
+		quoteSQLServerIdentifier(database), quoteSQLServerIdentifier(schema), quoteSQLServerIdentifier(name))
+	var args []interface{}
+	if request.FilterColumn != "" {
+		args = append(args, request.FilterValue)
+		query += fmt.Sprintf(" WHERE CHARINDEX(@p%d, CAST(%s AS NVARCHAR(MAX))) > 0", len(args), quoteSQLServerIdentifier(request.FilterColumn))
+	}
+	// OFFSET 需要 ORDER BY，没有排序列时按任意顺序
+	args = append(args, previewOffset(request.Offset), limit+1)
+	query += previewOrderBy(request.OrderBy, quoteSQLServerIdentifier, " ORDER BY (SELECT NULL)") +
+		fmt.Sprintf(" OFFSET @p%d ROWS FETCH NEXT @p%d ROWS ONLY", len(args)-1, len(args))
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return readPreviewPage(rows, limit)
+}
+
+// Close 关闭数据库连接
+func (c *SQLServerConnector) Close() error {
+	var err error
+	if c.db != nil {
+		err = c.db.Close()
+	}
+	if c.tunnel != nil {
+		c.tunnel.Close()
+		c.tunnel = nil
+	}
+	return err
+}
@@ -0,0 +1,253 @@
+package connector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// SSH认证方式，与配置文件中的取值相同
+const (
+	SSHAuthKey      = "key"      // 私钥文件
+	SSHAuthAgent    = "agent"    // SSH Agent
+	SSHAuthPassword = "password" // 密码
+)
+
+// SSHConfig 表示通过SSH跳板机连接数据库的隧道配置
+type SSHConfig struct {
+	Host                  string // 跳板机主机名或IP地址
+	Port                  string // 跳板机端口，默认为22
+	User                  string // 跳板机用户名
+	Auth                  string // 认证方式：SSHAuthKey、SSHAuthAgent 或 SSHAuthPassword
+	KeyFile               string // 私钥文件路径
+	Password              string // 密码认证的密码，或加密私钥的密码
+	KnownHostsFile        string // known_hosts 文件路径，默认为 ~/.ssh/known_hosts
+	InsecureIgnoreHostKey bool   // 不检查跳板机的主机密钥
+}
+
+// SSHTunnel 表示到跳板机的SSH连接，数据库连接通过它转发
+// 数据库主机名在跳板机上解析，例如 localhost 表示跳板机本身
+type SSHTunnel struct {
+	client *ssh.Client
+}
+
+// NewSSHTunnel 连接跳板机并完成认证，ctx 取消或超时时中止连接
+func NewSSHTunnel(ctx context.Context, config *SSHConfig) (*SSHTunnel, error) {
+	port := config.Port
+	if port == "" {
+		port = "22"
+	}
+	address := net.JoinHostPort(config.Host, port)
+
+	clientConfig, release, err := sshClientConfig(config, address)
+	if err != nil {
+		return nil, err
+	}
+	// 认证只在握手期间进行，握手结束后释放认证使用的资源
+	defer release()
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("连接SSH跳板机 %s 失败: %w", address, err)
+	}
+
+	// 握手期间 ctx 取消或超时时关闭连接
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, address, clientConfig)
+	if !stop() {
+		if err == nil {
+			sshConn.Close()
+		}
+		return nil, fmt.Errorf("连接SSH跳板机 %s 失败: %w", address, ctx.Err())
+	}
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("连接SSH跳板机 %s 失败: %v", address, err)
+	}
+	return &SSHTunnel{client: ssh.NewClient(sshConn, chans, reqs)}, nil
+}
+
+// Dial 通过跳板机连接数据库地址，实现 pq.Dialer 接口
+func (t *SSHTunnel) Dial(network, address string) (net.Conn, error) {
+	return t.DialContext(context.Background(), network, address)
+}
+
+// DialTimeout 在超时时间内通过跳板机连接数据库地址，实现 pq.Dialer 接口
+func (t *SSHTunnel) DialTimeout(network, address string, timeout time.Duration) (net.Conn, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return t.DialContext(ctx, network, address)
+}
+
+// DialContext 通过跳板机连接数据库地址，ctx 取消时放弃等待并返回 ctx 的错误
+func (t *SSHTunnel) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	conn, err := t.client.DialContext(ctx, network, address)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("通过SSH隧道连接 %s 失败: %v", address, err)
+	}
+	return conn, nil
+}
+
+// Close 关闭到跳板机的SSH连接
+func (t *SSHTunnel) Close() error {
+	return t.client.Close()
+}
+
+// sshClientConfig 根据隧道配置创建SSH客户端配置，握手结束后调用 release 释放认证使用的资源
+func sshClientConfig(config *SSHConfig, address string) (clientConfig *ssh.ClientConfig, release func(), err error) {
+	if config.Host == "" || config.User == "" {
+		return nil, nil, errors.New("SSH隧道需要填写跳板机主机和用户名")
+	}
+
+	auth, release, err := sshAuthMethod(config)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() {
+		if err != nil {
+			release()
+		}
+	}()
+
+	clientConfig = &ssh.ClientConfig{
+		User: config.User,
+		Auth: []ssh.AuthMethod{auth},
+	}
+
+	if config.InsecureIgnoreHostKey {
+		clientConfig.HostKeyCallback = ssh.InsecureIgnoreHostKey()
+		return clientConfig, release, nil
+	}
+
+	knownHostsFile := config.KnownHostsFile
+	if knownHostsFile == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, nil, err
+		}
+		knownHostsFile = filepath.Join(home, ".ssh", "known_hosts")
+	}
+	callback, err := knownhosts.New(expandHome(knownHostsFile))
+	if err != nil {
+		return nil, nil, fmt.Errorf("读取 known_hosts 文件失败: %v", err)
+	}
+	clientConfig.HostKeyCallback = func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		err := callback(hostname, remote, key)
+		var keyErr *knownhosts.KeyError
+		if errors.As(err, &keyErr) {
+			if len(keyErr.Want) == 0 {
+				return fmt.Errorf("跳板机 %s 不在 %s 中，请先使用 ssh 命令连接一次以确认主机密钥", hostname, knownHostsFile)
+			}
+			return fmt.Errorf("跳板机 %s 的主机密钥与 %s 中记录的不一致，连接可能被劫持", hostname, knownHostsFile)
+		}
+		return err
+	}
+	// 只协商 known_hosts 中已记录的密钥类型，否则服务器可能提供另一种类型的密钥导致校验失败
+	clientConfig.HostKeyAlgorithms = knownHostAlgorithms(callback, address)
+
+	return clientConfig, release, nil
+}
+
+// sshAuthMethod 根据认证方式创建SSH认证方法，握手结束后调用 release 释放认证使用的资源
+// SSH Agent 在握手期间为签名保持连接，release 时关闭
+func sshAuthMethod(config *SSHConfig) (auth ssh.AuthMethod, release func(), err error) {
+	release = func() {}
+	switch config.Auth {
+	case SSHAuthPassword:
+		return ssh.Password(config.Password), release, nil
+	case SSHAuthAgent:
+		socket := os.Getenv("SSH_AUTH_SOCK")
+		if socket == "" {
+			return nil, nil, errors.New("未找到SSH Agent，环境变量 SSH_AUTH_SOCK 未设置")
+		}
+		var conn net.Conn
+		release = func() {
+			if conn != nil {
+				conn.Close()
+			}
+		}
+		return ssh.PublicKeysCallback(func() ([]ssh.Signer, error) {
+			if conn == nil {
+				var err error
+				if conn, err = net.Dial("unix", socket); err != nil {
+					return nil, fmt.Errorf("连接SSH Agent失败: %v", err)
+				}
+			}
+			return agent.NewClient(conn).Signers()
+		}), release, nil
+	case SSHAuthKey, "":
+		if config.KeyFile == "" {
+			return nil, nil, errors.New("SSH隧道需要指定私钥文件")
+		}
+		data, err := os.ReadFile(expandHome(config.KeyFile))
+		if err != nil {
+			return nil, nil, fmt.Errorf("读取私钥文件失败: %v", err)
+		}
+		signer, err := ssh.ParsePrivateKey(data)
+		var missing *ssh.PassphraseMissingError
+		if errors.As(err, &missing) {
+			if config.Password == "" {
+				return nil, nil, errors.New("私钥已加密，请填写私钥密码")
+			}
+			signer, err = ssh.ParsePrivateKeyWithPassphrase(data, []byte(config.Password))
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("解析私钥文件失败: %v", err)
+		}
+		return ssh.PublicKeys(signer), release, nil
+	default:
+		return nil, nil, fmt.Errorf("不支持的SSH认证方式: %s", config.Auth)
+	}
+}
+
+// knownHostAlgorithms 返回 known_hosts 中为该主机记录的密钥算法，没有记录时返回空列表使用默认算法
+func knownHostAlgorithms(callback ssh.HostKeyCallback, address string) []string {
+	var keyErr *knownhosts.KeyError
+	err := callback(address, &net.TCPAddr{IP: net.IPv4zero}, placeholderKey{})
+	if !errors.As(err, &keyErr) {
+		return nil
+	}
+
+	var algorithms []string
+	for _, known := range keyErr.Want {
+		keyType := known.Key.Type()
+		if keyType == ssh.KeyAlgoRSA {
+			algorithms = append(algorithms, ssh.KeyAlgoRSASHA512, ssh.KeyAlgoRSASHA256)
+		}
+		algorithms = append(algorithms, keyType)
+	}
+	return algorithms
+}
+
+// placeholderKey 是不与任何记录匹配的公钥，用于查询 known_hosts 中记录的密钥
+type placeholderKey struct{}
+
+func (placeholderKey) Type() string    { return "placeholder" }
+func (placeholderKey) Marshal() []byte { return []byte("placeholder") }
+func (placeholderKey) Verify([]byte, *ssh.Signature) error {
+	return errors.New("placeholder key")
+}
+
+// expandHome 将路径开头的 ~ 展开为用户主目录
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, strings.TrimPrefix(path, "~"))
+}
@@ -0,0 +1,424 @@
+package connector
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// testSSHPassword 是测试SSH服务器接受的密码
+const testSSHPassword = "secret"
+
+// testSSHServer 是在 127.0.0.1 上运行的进程内SSH服务器，只支持 direct-tcpip 转发
+type testSSHServer struct {
+	address   string
+	hostKey   ssh.Signer
+	clientKey ed25519.PrivateKey
+}
+
+// startTestSSHServer 启动测试SSH服务器，接受密码 testSSHPassword 和 clientKey 对应的公钥
+func startTestSSHServer(t *testing.T) *testSSHServer {
+	t.Helper()
+
+	_, hostPrivate, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hostKey, err := ssh.NewSignerFromKey(hostPrivate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, clientPrivate, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientPublic, err := ssh.NewPublicKey(clientPrivate.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	serverConfig := &ssh.ServerConfig{
+		PasswordCallback: func(meta ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+			if meta.User() == "tester" && string(password) == testSSHPassword {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("密码错误")
+		},
+		PublicKeyCallback: func(meta ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if meta.User() == "tester" && bytes.Equal(key.Marshal(), clientPublic.Marshal()) {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("公钥未授权")
+		},
+	}
+	serverConfig.AddHostKey(hostKey)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	t.Cleanup(func() {
+		listener.Close()
+		wg.Wait()
+	})
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				serveTestSSHConn(conn, serverConfig)
+			}()
+		}
+	}()
+
+	return &testSSHServer{
+		address:   listener.Addr().String(),
+		hostKey:   hostKey,
+		clientKey: clientPrivate,
+	}
+}
+
+// serveTestSSHConn 处理一个SSH连接，把 direct-tcpip 通道转发到请求的地址
+func serveTestSSHConn(conn net.Conn, config *ssh.ServerConfig) {
+	defer conn.Close()
+	sshConn, chans, reqs, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		return
+	}
+	defer sshConn.Close()
+	go ssh.DiscardRequests(reqs)
+
+	for newChannel := range chans {
+		if newChannel.ChannelType() != "direct-tcpip" {
+			newChannel.Reject(ssh.UnknownChannelType, "unsupported channel type")
+			continue
+		}
+		// RFC 4254 7.2: 目标主机、目标端口、来源主机、来源端口
+		var target struct {
+			Host       string
+			Port       uint32
+			OriginHost string
+			OriginPort uint32
+		}
+		if err := ssh.Unmarshal(newChannel.ExtraData(), &target); err != nil {
+			newChannel.Reject(ssh.ConnectionFailed, err.Error())
+			continue
+		}
+		upstream, err := net.Dial("tcp", net.JoinHostPort(target.Host, strconv.FormatUint(uint64(target.Port), 10)))
+		if err != nil {
+			newChannel.Reject(ssh.ConnectionFailed, err.Error())
+			continue
+		}
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			upstream.Close()
+			continue
+		}
+		go ssh.DiscardRequests(requests)
+		go func() {
+			defer channel.Close()
+			defer upstream.Close()
+			go io.Copy(upstream, channel)
+			io.Copy(channel, upstream)
+		}()
+	}
+}
+
+// knownHostsFile 写入只记录 key 为 address 主机密钥的 known_hosts 文件
+func (s *testSSHServer) knownHostsFile(t *testing.T, key ssh.PublicKey) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "known_hosts")
+	line := knownhosts.Line([]string{knownhosts.Normalize(s.address)}, key)
+	if err := os.WriteFile(path, []byte(line+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// config 返回连接测试服务器的隧道配置，主机密钥按 known_hosts 检查
+func (s *testSSHServer) config(t *testing.T) *SSHConfig {
+	host, port, err := net.SplitHostPort(s.address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &SSHConfig{
+		Host:           host,
+		Port:           port,
+		User:           "tester",
+		Auth:           SSHAuthPassword,
+		Password:       testSSHPassword,
+		KnownHostsFile: s.knownHostsFile(t, s.hostKey.PublicKey()),
+	}
+}
+
+// startEchoServer 启动原样返回收到内容的本地服务，模拟隧道另一端的数据库
+func startEchoServer(t *testing.T) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				io.Copy(conn, conn)
+			}()
+		}
+	}()
+	return listener.Addr().String()
+}
+
+// openTunnel 按配置连接测试服务器
+func openTunnel(t *testing.T, config *SSHConfig) (*SSHTunnel, error) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	tunnel, err := NewSSHTunnel(ctx, config)
+	if err == nil {
+		t.Cleanup(func() { tunnel.Close() })
+	}
+	return tunnel, err
+}
+
+// assertForwards 检查通过隧道连接 address 后收发的数据原样返回
+func assertForwards(t *testing.T, tunnel *SSHTunnel, address string) {
+	t.Helper()
+	conn, err := tunnel.DialTimeout("tcp", address, 5*time.Second)
+	if err != nil {
+		t.Fatalf("通过隧道连接失败: %v", err)
+	}
+	defer conn.Close()
+
+	message := []byte("SELECT 1;\n")
+	if _, err := conn.Write(message); err != nil {
+		t.Fatal(err)
+	}
+	reply := make([]byte, len(message))
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		t.Fatalf("读取转发的数据失败: %v", err)
+	}
+	if !bytes.Equal(reply, message) {
+		t.Fatalf("转发的数据为 %q，应为 %q", reply, message)
+	}
+}
+
+func TestSSHTunnelForwardsToLocalListener(t *testing.T) {
+	server := startTestSSHServer(t)
+	echo := startEchoServer(t)
+
+	tunnel, err := openTunnel(t, server.config(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertForwards(t, tunnel, echo)
+
+	// 同一隧道可以建立多个连接
+	assertForwards(t, tunnel, echo)
+
+	// 目标端口没有监听时返回错误
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := closed.Addr().String()
+	closed.Close()
+	if _, err := tunnel.DialTimeout("tcp", address, 5*time.Second); err == nil {
+		t.Fatal("连接没有监听的端口应当失败")
+	}
+}
+
+func TestSSHTunnelDialContextCancel(t *testing.T) {
+	server := startTestSSHServer(t)
+	tunnel, err := openTunnel(t, server.config(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := tunnel.DialContext(ctx, "tcp", startEchoServer(t)); err != context.Canceled {
+		t.Fatalf("取消的 ctx 应当返回 context.Canceled，实际为 %v", err)
+	}
+}
+
+func TestSSHTunnelHostKeyChecking(t *testing.T) {
+	server := startTestSSHServer(t)
+
+	t.Run("unknown host", func(t *testing.T) {
+		config := server.config(t)
+		config.KnownHostsFile = filepath.Join(t.TempDir(), "known_hosts")
+		if err := os.WriteFile(config.KnownHostsFile, nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+		_, err := openTunnel(t, config)
+		if err == nil || !strings.Contains(err.Error(), "不在") {
+			t.Fatalf("未记录的主机应当被拒绝，实际为 %v", err)
+		}
+	})
+
+	t.Run("mismatched key", func(t *testing.T) {
+		_, otherPrivate, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		otherKey, err := ssh.NewPublicKey(otherPrivate.Public())
+		if err != nil {
+			t.Fatal(err)
+		}
+		config := server.config(t)
+		config.KnownHostsFile = server.knownHostsFile(t, otherKey)
+		_, err = openTunnel(t, config)
+		if err == nil || !strings.Contains(err.Error(), "不一致") {
+			t.Fatalf("主机密钥不一致时应当被拒绝，实际为 %v", err)
+		}
+	})
+
+	t.Run("insecure ignore", func(t *testing.T) {
+		config := server.config(t)
+		config.KnownHostsFile = filepath.Join(t.TempDir(), "missing")
+		config.InsecureIgnoreHostKey = true
+		if _, err := openTunnel(t, config); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func TestSSHTunnelAuthModes(t *testing.T) {
+	server := startTestSSHServer(t)
+	echo := startEchoServer(t)
+	dir := t.TempDir()
+
+	writeKey := func(name string, block *pem.Block) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, pem.EncodeToMemory(block), 0600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	block, err := ssh.MarshalPrivateKey(server.clientKey, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyFile := writeKey("id_ed25519", block)
+	block, err = ssh.MarshalPrivateKeyWithPassphrase(server.clientKey, "", []byte("phrase"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	encryptedKeyFile := writeKey("id_ed25519_encrypted", block)
+
+	t.Run("password", func(t *testing.T) {
+		tunnel, err := openTunnel(t, server.config(t))
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertForwards(t, tunnel, echo)
+	})
+
+	t.Run("wrong password", func(t *testing.T) {
+		config := server.config(t)
+		config.Password = "wrong"
+		if _, err := openTunnel(t, config); err == nil {
+			t.Fatal("密码错误时应当连接失败")
+		}
+	})
+
+	t.Run("key", func(t *testing.T) {
+		config := server.config(t)
+		config.Auth = SSHAuthKey
+		config.Password = ""
+		config.KeyFile = keyFile
+		tunnel, err := openTunnel(t, config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertForwards(t, tunnel, echo)
+	})
+
+	t.Run("encrypted key", func(t *testing.T) {
+		config := server.config(t)
+		config.Auth = SSHAuthKey
+		config.KeyFile = encryptedKeyFile
+		config.Password = ""
+		if _, err := openTunnel(t, config); err == nil || !strings.Contains(err.Error(), "私钥已加密") {
+			t.Fatalf("没有私钥密码时应当提示，实际为 %v", err)
+		}
+		config.Password = "phrase"
+		tunnel, err := openTunnel(t, config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertForwards(t, tunnel, echo)
+	})
+
+	t.Run("agent", func(t *testing.T) {
+		keyring := agent.NewKeyring()
+		if err := keyring.Add(agent.AddedKey{PrivateKey: server.clientKey}); err != nil {
+			t.Fatal(err)
+		}
+		socket := filepath.Join(t.TempDir(), "agent.sock")
+		listener, err := net.Listen("unix", socket)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { listener.Close() })
+		go func() {
+			for {
+				conn, err := listener.Accept()
+				if err != nil {
+					return
+				}
+				go func() {
+					defer conn.Close()
+					agent.ServeAgent(keyring, conn)
+				}()
+			}
+		}()
+		t.Setenv("SSH_AUTH_SOCK", socket)
+
+		config := server.config(t)
+		config.Auth = SSHAuthAgent
+		config.Password = ""
+		tunnel, err := openTunnel(t, config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertForwards(t, tunnel, echo)
+	})
+
+	t.Run("agent missing", func(t *testing.T) {
+		t.Setenv("SSH_AUTH_SOCK", "")
+		config := server.config(t)
+		config.Auth = SSHAuthAgent
+		if _, err := openTunnel(t, config); err == nil {
+			t.Fatal("没有SSH Agent时应当连接失败")
+		}
+	})
+}
@@ -0,0 +1,149 @@
+package connector
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"time"
+)
+
+// TLS模式，与PostgreSQL的 sslmode 取值相同
+const (
+	TLSModeDisable    = "disable"     // 不使用TLS
+	TLSModeRequire    = "require"     // 使用TLS，但不校验服务器证书
+	TLSModeVerifyCA   = "verify-ca"   // 校验服务器证书由受信任的CA签发
+	TLSModeVerifyFull = "verify-full" // 校验服务器证书和主机名
+)
+
+// TLSConfig 表示数据库连接的TLS配置
+type TLSConfig struct {
+	Mode       string // TLS模式，为空时等同于 TLSModeDisable
+	CACert     string // CA证书文件，为空时使用系统的根证书
+	ClientCert string // 客户端证书文件，服务器要求客户端证书时填写
+	ClientKey  string // 客户端私钥文件
+	ServerName string // 校验证书使用的服务器名称，为空时使用连接的主机名
+}
+
+// enabled 判断是否使用TLS，配置为空时不使用
+func (c *TLSConfig) enabled() bool {
+	return c != nil && c.Mode != "" && c.Mode != TLSModeDisable
+}
+
+// clientConfig 根据TLS模式创建 crypto/tls 配置，host 是连接的数据库主机名
+func (c *TLSConfig) clientConfig(host string) (*tls.Config, error) {
+	config := &tls.Config{ServerName: host}
+	if c.ServerName != "" {
+		config.ServerName = c.ServerName
+	}
+
+	if c.CACert != "" {
+		data, err := os.ReadFile(expandHome(c.CACert))
+		if err != nil {
+			return nil, fmt.Errorf("读取CA证书失败: %v", err)
+		}
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("CA证书 %s 不是有效的PEM文件", c.CACert)
+		}
+	}
+
+	if c.ClientCert != "" || c.ClientKey != "" {
+		cert, err := tls.LoadX509KeyPair(expandHome(c.ClientCert), expandHome(c.ClientKey))
+		if err != nil {
+			return nil, fmt.Errorf("加载客户端证书失败: %v", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	switch c.Mode {
+	case TLSModeRequire:
+		config.InsecureSkipVerify = true
+	case TLSModeVerifyCA:
+		// 只校验证书链，不校验主机名
+		config.InsecureSkipVerify = true
+		config.VerifyPeerCertificate = func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			return verifyCertificateChain(rawCerts, config.RootCAs)
+		}
+	case TLSModeVerifyFull:
+	default:
+		return nil, fmt.Errorf("不支持的TLS模式: %s", c.Mode)
+	}
+	return config, nil
+}
+
+// pqParams 返回 lib/pq 连接字符串中的TLS参数
+func (c *TLSConfig) pqParams() string {
+	if !c.enabled() {
+		return "sslmode=disable"
+	}
+	params := []string{"sslmode=" + c.Mode}
+	for key, value := range map[string]string{
+		"sslrootcert": c.CACert,
+		"sslcert":     c.ClientCert,
+		"sslkey":      c.ClientKey,
+	} {
+		if value != "" {
+			params = append(params, key+"="+pqQuote(expandHome(value)))
+		}
+	}
+	return strings.Join(params, " ")
+}
+
+// pqQuote 按 lib/pq 连接字符串的规则为值加引号
+func pqQuote(value string) string {
+	value = strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value)
+	return "'" + value + "'"
+}
+
+// verifyCertificateChain 校验服务器证书链由受信任的CA签发，roots 为空时使用系统的根证书
+func verifyCertificateChain(rawCerts [][]byte, roots *x509.CertPool) error {
+	if len(rawCerts) == 0 {
+		return errors.New("服务器没有提供证书")
+	}
+	certs := make([]*x509.Certificate, len(rawCerts))
+	for i, raw := range rawCerts {
+		cert, err := x509.ParseCertificate(raw)
+		if err != nil {
+			return fmt.Errorf("解析服务器证书失败: %v", err)
+		}
+		certs[i] = cert
+	}
+
+	opts := x509.VerifyOptions{Roots: roots, Intermediates: x509.NewCertPool()}
+	for _, cert := range certs[1:] {
+		opts.Intermediates.AddCert(cert)
+	}
+	if _, err := certs[0].Verify(opts); err != nil {
+		return fmt.Errorf("服务器证书校验失败: %v", err)
+	}
+	return nil
+}
+
+// addressDialer 忽略 lib/pq 请求的地址，总是连接固定的数据库地址
+// 用于SSH隧道，以及证书服务器名称与连接主机不同的情况（lib/pq 使用 host 参数校验证书）
+type addressDialer struct {
+	address string
+	dial    func(ctx context.Context, network, address string) (net.Conn, error)
+}
+
+// Dial 实现 pq.Dialer 接口
+func (d addressDialer) Dial(network, _ string) (net.Conn, error) {
+	return d.dial(context.Background(), network, d.address)
+}
+
+// DialTimeout 实现 pq.Dialer 接口
+func (d addressDialer) DialTimeout(network, _ string, timeout time.Duration) (net.Conn, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return d.dial(ctx, network, d.address)
+}
+
+// DialContext 实现 pq.DialerContext 接口
+func (d addressDialer) DialContext(ctx context.Context, network, _ string) (net.Conn, error) {
+	return d.dial(ctx, network, d.address)
+}
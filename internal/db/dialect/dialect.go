@@ -0,0 +1,150 @@
+package dialect
+
+import (
+	"fmt"
+	"go-DBmodeler/internal/db/connector"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// 连接表单字段的键，对应连接配置中的同名字段
+const (
+	FieldHost     = "host"
+	FieldPort     = "port"
+	FieldUsername = "username"
+	FieldPassword = "password"
+	FieldDatabase = "database"
+)
+
+// passwordPlaceHolder 是内置数据库密码输入框的提示文字
+const passwordPlaceHolder = "密码，或 ${env:变量名} / ${file:路径}"
+
+// TypeMapper 定义类型映射器接口
+type TypeMapper interface {
+	// Map 将数据库类型映射为TypeScript类型
+	Map(dbType string) string
+}
+
+// Field 表示连接表单中的一个字段
+type Field struct {
+	Key         string // 字段键，取值为 Field* 常量之一
+	Label       string // 表单中显示的名称
+	PlaceHolder string // 输入框的提示文字
+	Required    bool   // 是否必填
+}
+
+// Dialect 描述一种数据库
+// 界面、生成器和命令行都从注册表中查找数据库类型，新的数据库只需要注册一个 Dialect
+type Dialect struct {
+	Name        string  // 数据库类型名称，保存在连接配置中，例如 MySQL
+	DefaultPort string  // 未填写端口时使用的默认端口，没有端口时为空
+	Fields      []Field // 连接表单中显示的字段，按顺序排列
+	SSH         bool    // 是否支持SSH隧道
+	TLS         bool    // 是否支持TLS
+
+	// NewConnector 创建连接器
+	NewConnector func(config *connector.ConnectionConfig) connector.Connector
+	// NewTypeMapper 创建数据库类型到TypeScript类型的映射器
+	NewTypeMapper func() TypeMapper
+	// Validate 对表单字段做额外的检查，返回发现的问题，可以为空
+	// values 的键为 Field* 常量，必填字段由 Check 检查
+	Validate func(values map[string]string) []string
+}
+
+var (
+	mu       sync.RWMutex
+	dialects = make(map[string]*Dialect)
+)
+
+// Register 注册一种数据库，通常在包的 init 函数中调用
+// 名称为空、缺少连接器或类型映射器、或重复注册时 panic
+func Register(d *Dialect) {
+	if d == nil || d.Name == "" {
+		panic("dialect: 注册的数据库类型缺少名称")
+	}
+	if d.NewConnector == nil || d.NewTypeMapper == nil {
+		panic(fmt.Sprintf("dialect: 数据库类型 %s 缺少连接器或类型映射器", d.Name))
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if _, exists := dialects[d.Name]; exists {
+		panic(fmt.Sprintf("dialect: 数据库类型 %s 重复注册", d.Name))
+	}
+	dialects[d.Name] = d
+}
+
+// Lookup 按名称查找数据库类型
+func Lookup(name string) (*Dialect, error) {
+	mu.RLock()
+	defer mu.RUnlock()
+	if d, ok := dialects[name]; ok {
+		return d, nil
+	}
+	return nil, fmt.Errorf("不支持的数据库类型: %s", name)
+}
+
+// Names 返回所有已注册的数据库类型名称，按名称排序
+func Names() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	names := make([]string, 0, len(dialects))
+	for name := range dialects {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Normalize 将大小写不同的数据库类型名称转换为注册的名称，无法识别时原样返回
+func Normalize(name string) string {
+	for _, registered := range Names() {
+		if strings.EqualFold(name, registered) {
+			return registered
+		}
+	}
+	return name
+}
+
+// NewConnector 根据配置中的数据库类型创建连接器
+func NewConnector(config *connector.ConnectionConfig) (connector.Connector, error) {
+	d, err := Lookup(config.Type)
+	if err != nil {
+		return nil, err
+	}
+	return d.NewConnector(config), nil
+}
+
+// NewTypeMapper 创建指定数据库类型的类型映射器
+func NewTypeMapper(name string) (TypeMapper, error) {
+	d, err := Lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	return d.NewTypeMapper(), nil
+}
+
+// HasField 判断连接表单中是否包含指定字段
+func (d *Dialect) HasField(key string) bool {
+	for _, field := range d.Fields {
+		if field.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// Check 检查连接表单字段，返回发现的问题
+func (d *Dialect) Check(values map[string]string) []string {
+	var issues []string
+	for _, field := range d.Fields {
+		if field.Required && strings.TrimSpace(values[field.Key]) == "" {
+			issues = append(issues, "需要填写"+field.Label)
+		}
+	}
+	if d.Validate != nil {
+		issues = append(issues, d.Validate(values)...)
+	}
+	return issues
+}
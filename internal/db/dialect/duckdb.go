@@ -0,0 +1,21 @@
+//go:build duckdb && cgo
+
+package dialect
+
+import "go-DBmodeler/internal/db/connector"
+
+// DuckDB驱动需要cgo并且体积较大，使用 -tags duckdb 编译时才注册
+func init() {
+	Register(&Dialect{
+		Name: "DuckDB",
+		Fields: []Field{
+			{Key: FieldHost, Label: "数据库文件", PlaceHolder: "数据库文件路径，例如 analytics.duckdb", Required: true},
+		},
+		NewConnector: func(config *connector.ConnectionConfig) connector.Connector {
+			return connector.NewDuckDBConnector(config)
+		},
+		NewTypeMapper: func() TypeMapper {
+			return NewDuckDBMapper()
+		},
+	})
+}
@@ -0,0 +1,234 @@
+package dialect
+
+import (
+	"regexp"
+	"strings"
+)
+
+// duckDBIdentifier 匹配可以直接作为TypeScript属性名的字段名
+var duckDBIdentifier = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// duckDBFixedArray 匹配固定长度数组的后缀，例如 FLOAT[3]
+var duckDBFixedArray = regexp.MustCompile(`\[\d*\]$`)
+
+// DuckDBMapper 实现DuckDB类型到TypeScript类型的映射
+// 嵌套类型映射为嵌套的TypeScript类型：
+// LIST 映射为数组，STRUCT 映射为对象类型，MAP 映射为 Record 或 Map，UNION 和 ENUM 映射为联合类型
+type DuckDBMapper struct {
+	// 类型映射表，键为大写的基本类型
+	typeMap map[string]string
+}
+
+// NewDuckDBMapper 创建一个新的DuckDB类型映射器
+func NewDuckDBMapper() *DuckDBMapper {
+	mapper := &DuckDBMapper{
+		typeMap: make(map[string]string),
+	}
+
+	// 初始化默认映射
+	for _, t := range []string{
+		"TINYINT", "SMALLINT", "INTEGER", "INT", "BIGINT", "HUGEINT",
+		"UTINYINT", "USMALLINT", "UINTEGER", "UBIGINT", "UHUGEINT",
+		"FLOAT", "REAL", "DOUBLE", "DECIMAL", "NUMERIC",
+	} {
+		mapper.typeMap[t] = "number"
+	}
+
+	for _, t := range []string{
+		"VARCHAR", "TEXT", "STRING", "CHAR", "BPCHAR", "UUID",
+		"TIME", "TIME WITH TIME ZONE", "TIMETZ", "INTERVAL", "BIT", "BITSTRING",
+	} {
+		mapper.typeMap[t] = "string"
+	}
+
+	for _, t := range []string{
+		"DATE", "DATETIME", "TIMESTAMP", "TIMESTAMP WITH TIME ZONE", "TIMESTAMPTZ",
+		"TIMESTAMP_S", "TIMESTAMP_MS", "TIMESTAMP_NS",
+	} {
+		mapper.typeMap[t] = "Date"
+	}
+
+	mapper.typeMap["BOOLEAN"] = "boolean"
+	mapper.typeMap["BOOL"] = "boolean"
+
+	mapper.typeMap["BLOB"] = "Buffer"
+	mapper.typeMap["BYTEA"] = "Buffer"
+
+	mapper.typeMap["JSON"] = "any"
+
+	return mapper
+}
+
+// Map 将DuckDB类型映射为TypeScript类型
+func (m *DuckDBMapper) Map(dbType string) string {
+	dbType = strings.TrimSpace(dbType)
+
+	// LIST 写作 T[]，固定长度的 ARRAY 写作 T[n]
+	if suffix := duckDBFixedArray.FindString(dbType); suffix != "" {
+		element := m.Map(dbType[:len(dbType)-len(suffix)])
+		if strings.Contains(element, " | ") {
+			element = "(" + element + ")"
+		}
+		return element + "[]"
+	}
+
+	name, args, nested := splitDuckDBType(dbType)
+	if nested {
+		switch strings.ToUpper(name) {
+		case "STRUCT":
+			return m.mapStruct(args)
+		case "MAP":
+			return m.mapMap(args)
+		case "UNION":
+			return m.mapUnion(args)
+		case "ENUM":
+			return mapDuckDBEnum(args)
+		}
+	}
+
+	// 查找映射，DECIMAL(18,3) 和 VARCHAR(20) 等按基本类型查找
+	if tsType, ok := m.typeMap[strings.ToUpper(name)]; ok {
+		return tsType
+	}
+
+	// 默认为any类型
+	return "any"
+}
+
+// mapStruct 将 STRUCT(a INTEGER, b VARCHAR) 映射为 { a: number; b: string }
+func (m *DuckDBMapper) mapStruct(args string) string {
+	members := splitDuckDBArgs(args)
+	if len(members) == 0 {
+		return "{}"
+	}
+
+	fields := make([]string, 0, len(members))
+	for _, member := range members {
+		name, memberType := splitDuckDBMember(member)
+		if !duckDBIdentifier.MatchString(name) {
+			name = "'" + strings.ReplaceAll(name, "'", "\\'") + "'"
+		}
+		fields = append(fields, name+": "+m.Map(memberType))
+	}
+	return "{ " + strings.Join(fields, "; ") + " }"
+}
+
+// mapMap 将 MAP(K, V) 映射为 Record<K, V>，键不能作为对象属性时映射为 Map<K, V>
+func (m *DuckDBMapper) mapMap(args string) string {
+	parts := splitDuckDBArgs(args)
+	if len(parts) != 2 {
+		return "any"
+	}
+
+	key, value := m.Map(parts[0]), m.Map(parts[1])
+	if key == "string" || key == "number" {
+		return "Record<" + key + ", " + value + ">"
+	}
+	return "Map<" + key + ", " + value + ">"
+}
+
+// mapUnion 将 UNION(num INTEGER, str VARCHAR) 映射为 number | string
+func (m *DuckDBMapper) mapUnion(args string) string {
+	var types []string
+	seen := make(map[string]bool)
+	for _, member := range splitDuckDBArgs(args) {
+		_, memberType := splitDuckDBMember(member)
+		tsType := m.Map(memberType)
+		if !seen[tsType] {
+			seen[tsType] = true
+			types = append(types, tsType)
+		}
+	}
+	if len(types) == 0 {
+		return "any"
+	}
+	return strings.Join(types, " | ")
+}
+
+// mapDuckDBEnum 将 ENUM('a', 'b') 映射为 'a' | 'b'
+func mapDuckDBEnum(args string) string {
+	values := splitDuckDBArgs(args)
+	if len(values) == 0 {
+		return "string"
+	}
+	for i, value := range values {
+		if !strings.HasPrefix(value, "'") {
+			return "string"
+		}
+		values[i] = "'" + strings.ReplaceAll(unquoteDuckDB(value), "'", "\\'") + "'"
+	}
+	return strings.Join(values, " | ")
+}
+
+// splitDuckDBType 拆分类型名称和括号中的参数，例如 STRUCT(a INTEGER) 拆分为 STRUCT 和 a INTEGER
+// 没有括号或括号后还有其他内容时 nested 为 false，name 为去掉括号后的类型
+func splitDuckDBType(dbType string) (name, args string, nested bool) {
+	open := strings.Index(dbType, "(")
+	if open < 0 {
+		return dbType, "", false
+	}
+	name = strings.TrimSpace(dbType[:open])
+	if !strings.HasSuffix(dbType, ")") {
+		return name, "", false
+	}
+	return name, dbType[open+1 : len(dbType)-1], true
+}
+
+// splitDuckDBArgs 按顶层的逗号拆分参数，忽略括号和引号中的逗号
+func splitDuckDBArgs(args string) []string {
+	var parts []string
+	depth, start := 0, 0
+	var quote rune
+	for i, c := range args {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '(' || c == '[':
+			depth++
+		case c == ')' || c == ']':
+			depth--
+		case c == ',' && depth == 0:
+			parts = append(parts, strings.TrimSpace(args[start:i]))
+			start = i + 1
+		}
+	}
+	if last := strings.TrimSpace(args[start:]); last != "" {
+		parts = append(parts, last)
+	}
+	return parts
+}
+
+// splitDuckDBMember 拆分 STRUCT 和 UNION 成员的名称和类型，名称可能带双引号
+func splitDuckDBMember(member string) (name, memberType string) {
+	if strings.HasPrefix(member, `"`) {
+		for i := 1; i < len(member); i++ {
+			if member[i] != '"' {
+				continue
+			}
+			// 两个双引号表示名称中的一个双引号
+			if i+1 < len(member) && member[i+1] == '"' {
+				i++
+				continue
+			}
+			return unquoteDuckDB(member[:i+1]), strings.TrimSpace(member[i+1:])
+		}
+	}
+	name, memberType, _ = strings.Cut(member, " ")
+	return name, strings.TrimSpace(memberType)
+}
+
+// unquoteDuckDB 去掉标识符或字符串两端的引号，并还原重复的引号
+func unquoteDuckDB(value string) string {
+	if len(value) < 2 {
+		return value
+	}
+	quote := value[:1]
+	if (quote != `"` && quote != "'") || !strings.HasSuffix(value, quote) {
+		return value
+	}
+	return strings.ReplaceAll(value[1:len(value)-1], quote+quote, quote)
+}
@@ -0,0 +1,99 @@
+package dialect
+
+import "go-DBmodeler/internal/db/connector"
+
+func init() {
+	Register(&Dialect{
+		Name:        "MySQL",
+		DefaultPort: "3306",
+		Fields: []Field{
+			{Key: FieldHost, Label: "主机", PlaceHolder: "主机名/IP地址", Required: true},
+			{Key: FieldPort, Label: "端口", PlaceHolder: "端口号，默认 3306"},
+			{Key: FieldUsername, Label: "用户名", PlaceHolder: "用户名", Required: true},
+			{Key: FieldPassword, Label: "密码", PlaceHolder: passwordPlaceHolder},
+			{Key: FieldDatabase, Label: "数据库", PlaceHolder: "数据库名（可选）"},
+		},
+		SSH: true,
+		TLS: true,
+		NewConnector: func(config *connector.ConnectionConfig) connector.Connector {
+			return connector.NewMySQLConnector(config)
+		},
+		NewTypeMapper: func() TypeMapper {
+			return NewMySQLMapper()
+		},
+	})
+}
+
+// MySQLMapper 实现MySQL类型到TypeScript类型的映射
+type MySQLMapper struct {
+	// 类型映射表
+	typeMap map[string]string
+}
+
+// NewMySQLMapper 创建一个新的MySQL类型映射器
+func NewMySQLMapper() *MySQLMapper {
+	mapper := &MySQLMapper{
+		typeMap: make(map[string]string),
+	}
+
+	// 初始化默认映射
+	mapper.typeMap["int"] = "number"
+	mapper.typeMap["tinyint"] = "number"
+	mapper.typeMap["smallint"] = "number"
+	mapper.typeMap["mediumint"] = "number"
+	mapper.typeMap["bigint"] = "number"
+	mapper.typeMap["float"] = "number"
+	mapper.typeMap["double"] = "number"
+	mapper.typeMap["decimal"] = "number"
+
+	mapper.typeMap["char"] = "string"
+	mapper.typeMap["varchar"] = "string"
+	mapper.typeMap["tinytext"] = "string"
+	mapper.typeMap["text"] = "string"
+	mapper.typeMap["mediumtext"] = "string"
+	mapper.typeMap["longtext"] = "string"
+
+	mapper.typeMap["date"] = "Date"
+	mapper.typeMap["datetime"] = "Date"
+	mapper.typeMap["timestamp"] = "Date"
+	mapper.typeMap["time"] = "string"
+	mapper.typeMap["year"] = "number"
+
+	mapper.typeMap["tinyint(1)"] = "boolean"
+	mapper.typeMap["bit"] = "boolean"
+
+	mapper.typeMap["json"] = "any"
+	mapper.typeMap["enum"] = "string"
+	mapper.typeMap["set"] = "string[]"
+
+	mapper.typeMap["binary"] = "Buffer"
+	mapper.typeMap["varbinary"] = "Buffer"
+	mapper.typeMap["blob"] = "Buffer"
+
+	return mapper
+}
+
+// Map 将MySQL类型映射为TypeScript类型
+func (m *MySQLMapper) Map(dbType string) string {
+	// 检查是否为tinyint(1)，这通常表示布尔值
+	if dbType == "tinyint(1)" {
+		return "boolean"
+	}
+
+	// 提取基本类型（去掉长度等信息）
+	baseType := dbType
+	for i, c := range dbType {
+		if c == '(' || c == ' ' {
+			baseType = dbType[:i]
+			break
+		}
+	}
+
+	// 查找映射
+	if tsType, ok := m.typeMap[baseType]; ok {
+		return tsType
+	}
+
+	// 默认为any类型
+	return "any"
+}
@@ -0,0 +1,87 @@
+package dialect
+
+import "go-DBmodeler/internal/db/connector"
+
+func init() {
+	Register(&Dialect{
+		Name:        "PostgreSQL",
+		DefaultPort: "5432",
+		Fields: []Field{
+			{Key: FieldHost, Label: "主机", PlaceHolder: "主机名/IP地址", Required: true},
+			{Key: FieldPort, Label: "端口", PlaceHolder: "端口号，默认 5432"},
+			{Key: FieldUsername, Label: "用户名", PlaceHolder: "用户名", Required: true},
+			{Key: FieldPassword, Label: "密码", PlaceHolder: passwordPlaceHolder},
+			{Key: FieldDatabase, Label: "数据库", PlaceHolder: "数据库名（可选），默认 postgres"},
+		},
+		SSH: true,
+		TLS: true,
+		NewConnector: func(config *connector.ConnectionConfig) connector.Connector {
+			return connector.NewPostgreSQLConnector(config)
+		},
+		NewTypeMapper: func() TypeMapper {
+			return NewPostgreSQLMapper()
+		},
+	})
+}
+
+// PostgreSQLMapper 实现PostgreSQL类型到TypeScript类型的映射
+type PostgreSQLMapper struct {
+	// 类型映射表
+	typeMap map[string]string
+}
+
+// NewPostgreSQLMapper 创建一个新的PostgreSQL类型映射器
+func NewPostgreSQLMapper() *PostgreSQLMapper {
+	mapper := &PostgreSQLMapper{
+		typeMap: make(map[string]string),
+	}
+
+	// 初始化默认映射
+	mapper.typeMap["smallint"] = "number"
+	mapper.typeMap["integer"] = "number"
+	mapper.typeMap["bigint"] = "number"
+	mapper.typeMap["decimal"] = "number"
+	mapper.typeMap["numeric"] = "number"
+	mapper.typeMap["real"] = "number"
+	mapper.typeMap["double precision"] = "number"
+	mapper.typeMap["serial"] = "number"
+	mapper.typeMap["bigserial"] = "number"
+
+	mapper.typeMap["varchar"] = "string"
+	mapper.typeMap["character varying"] = "string"
+	mapper.typeMap["character"] = "string"
+	mapper.typeMap["text"] = "string"
+
+	mapper.typeMap["timestamp"] = "Date"
+	mapper.typeMap["timestamp with time zone"] = "Date"
+	mapper.typeMap["timestamp without time zone"] = "Date"
+	mapper.typeMap["date"] = "Date"
+	mapper.typeMap["time"] = "string"
+	mapper.typeMap["time with time zone"] = "string"
+	mapper.typeMap["time without time zone"] = "string"
+	mapper.typeMap["interval"] = "string"
+
+	mapper.typeMap["boolean"] = "boolean"
+
+	mapper.typeMap["json"] = "any"
+	mapper.typeMap["jsonb"] = "any"
+	mapper.typeMap["uuid"] = "string"
+	mapper.typeMap["inet"] = "string"
+	mapper.typeMap["cidr"] = "string"
+	mapper.typeMap["macaddr"] = "string"
+
+	mapper.typeMap["bytea"] = "Buffer"
+
+	return mapper
+}
+
+// Map 将PostgreSQL类型映射为TypeScript类型
+func (m *PostgreSQLMapper) Map(dbType string) string {
+	// 查找映射
+	if tsType, ok := m.typeMap[dbType]; ok {
+		return tsType
+	}
+
+	// 默认为any类型
+	return "any"
+}
@@ -0,0 +1,86 @@
+package dialect
+
+import "go-DBmodeler/internal/db/connector"
+
+func init() {
+	Register(&Dialect{
+		Name: "SQLite",
+		Fields: []Field{
+			{Key: FieldHost, Label: "数据库文件", PlaceHolder: "数据库文件路径", Required: true},
+		},
+		NewConnector: func(config *connector.ConnectionConfig) connector.Connector {
+			return connector.NewSQLiteConnector(config)
+		},
+		NewTypeMapper: func() TypeMapper {
+			return NewSQLiteMapper()
+		},
+	})
+}
+
+// SQLiteMapper 实现SQLite类型到TypeScript类型的映射
+type SQLiteMapper struct {
+	// 类型映射表
+	typeMap map[string]string
+}
+
+// NewSQLiteMapper 创建一个新的SQLite类型映射器
+func NewSQLiteMapper() *SQLiteMapper {
+	mapper := &SQLiteMapper{
+		typeMap: make(map[string]string),
+	}
+
+	// 初始化默认映射
+	mapper.typeMap["integer"] = "number"
+	mapper.typeMap["int"] = "number"
+	mapper.typeMap["tinyint"] = "number"
+	mapper.typeMap["smallint"] = "number"
+	mapper.typeMap["mediumint"] = "number"
+	mapper.typeMap["bigint"] = "number"
+	mapper.typeMap["real"] = "number"
+	mapper.typeMap["double"] = "number"
+	mapper.typeMap["float"] = "number"
+	mapper.typeMap["numeric"] = "number"
+
+	mapper.typeMap["text"] = "string"
+	mapper.typeMap["char"] = "string"
+	mapper.typeMap["varchar"] = "string"
+	mapper.typeMap["varying character"] = "string"
+	mapper.typeMap["nchar"] = "string"
+	mapper.typeMap["native character"] = "string"
+	mapper.typeMap["nvarchar"] = "string"
+
+	mapper.typeMap["date"] = "Date"
+	mapper.typeMap["datetime"] = "Date"
+	mapper.typeMap["timestamp"] = "Date"
+
+	mapper.typeMap["boolean"] = "boolean"
+
+	mapper.typeMap["blob"] = "Buffer"
+
+	return mapper
+}
+
+// Map 将SQLite类型映射为TypeScript类型
+func (m *SQLiteMapper) Map(dbType string) string {
+	// 提取基本类型（去掉长度等信息）
+	baseType := dbType
+	for i, c := range dbType {
+		if c == '(' || c == ' ' {
+			baseType = dbType[:i]
+			break
+		}
+	}
+
+	// 检查是否为布尔值
+	if baseType == "tinyint" && dbType == "tinyint(1)" {
+		return "boolean"
+	}
+
+	// 查找映射
+	if tsType, ok := m.typeMap[baseType]; ok {
+		return tsType
+	}
+
+	// 默认为any类型
+	return "any"
+}
@@ -0,0 +1,101 @@
+package dialect
+
+import "go-DBmodeler/internal/db/connector"
+
+func init() {
+	Register(&Dialect{
+		// 不填写默认端口：命名实例没有端口时通过 SQL Server Browser 查找，否则驱动使用 1433
+		Name: "SQL Server",
+		Fields: []Field{
+			{Key: FieldHost, Label: "主机", PlaceHolder: "主机名/IP地址，命名实例写作 主机\\实例名", Required: true},
+			{Key: FieldPort, Label: "端口", PlaceHolder: "端口号，默认 1433"},
+			{Key: FieldUsername, Label: "用户名", PlaceHolder: "用户名", Required: true},
+			{Key: FieldPassword, Label: "密码", PlaceHolder: passwordPlaceHolder},
+			{Key: FieldDatabase, Label: "数据库", PlaceHolder: "数据库名（可选）"},
+		},
+		SSH: true,
+		TLS: true,
+		NewConnector: func(config *connector.ConnectionConfig) connector.Connector {
+			return connector.NewSQLServerConnector(config)
+		},
+		NewTypeMapper: func() TypeMapper {
+			return NewSQLServerMapper()
+		},
+	})
+}
+
+// SQLServerMapper 实现SQL Server类型到TypeScript类型的映射
+type SQLServerMapper struct {
+	// 类型映射表
+	typeMap map[string]string
+}
+
+// NewSQLServerMapper 创建一个新的SQL Server类型映射器
+func NewSQLServerMapper() *SQLServerMapper {
+	mapper := &SQLServerMapper{
+		typeMap: make(map[string]string),
+	}
+
+	// 初始化默认映射
+	mapper.typeMap["tinyint"] = "number"
+	mapper.typeMap["smallint"] = "number"
+	mapper.typeMap["int"] = "number"
+	mapper.typeMap["bigint"] = "number"
+	mapper.typeMap["decimal"] = "number"
+	mapper.typeMap["numeric"] = "number"
+	mapper.typeMap["money"] = "number"
+	mapper.typeMap["smallmoney"] = "number"
+	mapper.typeMap["float"] = "number"
+	mapper.typeMap["real"] = "number"
+
+	mapper.typeMap["char"] = "string"
+	mapper.typeMap["varchar"] = "string"
+	mapper.typeMap["text"] = "string"
+	mapper.typeMap["nchar"] = "string"
+	mapper.typeMap["nvarchar"] = "string"
+	mapper.typeMap["ntext"] = "string"
+	mapper.typeMap["uniqueidentifier"] = "string"
+	mapper.typeMap["xml"] = "string"
+	mapper.typeMap["hierarchyid"] = "string"
+
+	mapper.typeMap["date"] = "Date"
+	mapper.typeMap["datetime"] = "Date"
+	mapper.typeMap["datetime2"] = "Date"
+	mapper.typeMap["smalldatetime"] = "Date"
+	mapper.typeMap["datetimeoffset"] = "Date"
+	mapper.typeMap["time"] = "string"
+
+	mapper.typeMap["bit"] = "boolean"
+
+	mapper.typeMap["binary"] = "Buffer"
+	mapper.typeMap["varbinary"] = "Buffer"
+	mapper.typeMap["image"] = "Buffer"
+	mapper.typeMap["timestamp"] = "Buffer"
+	mapper.typeMap["rowversion"] = "Buffer"
+
+	mapper.typeMap["sql_variant"] = "any"
+	mapper.typeMap["geometry"] = "any"
+	mapper.typeMap["geography"] = "any"
+
+	return mapper
+}
+
+// Map 将SQL Server类型映射为TypeScript类型
+func (m *SQLServerMapper) Map(dbType string) string {
+	// 提取基本类型（去掉长度等信息），例如 nvarchar(max) 和 decimal(18,2)
+	baseType := dbType
+	for i, c := range dbType {
+		if c == '(' || c == ' ' {
+			baseType = dbType[:i]
+			break
+		}
+	}
+
+	// 查找映射
+	if tsType, ok := m.typeMap[baseType]; ok {
+		return tsType
+	}
+
+	// 默认为any类型
+	return "any"
+}
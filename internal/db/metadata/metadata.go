@@ -1,10 +1,13 @@
 package metadata
 
 import (
+	"context"
+	"fmt"
 	"go-DBmodeler/internal/db/connector"
 )
 
 // Processor 表示元数据处理器
+// 所有查询都接受 ctx，取消 ctx 会中止正在进行的查询
 type Processor struct {
 	connector connector.Connector
 }
@@ -17,18 +20,83 @@ func NewProcessor(connector connector.Connector) *Processor {
 }
 
 // GetDatabases 获取所有数据库
-func (p *Processor) GetDatabases() ([]string, error) {
-	return p.connector.GetDatabases()
+func (p *Processor) GetDatabases(ctx context.Context) ([]string, error) {
+	return p.connector.GetDatabases(ctx)
 }
 
-// GetTables 获取指定数据库中的所有表
-func (p *Processor) GetTables(database string) ([]string, error) {
-	return p.connector.GetTables(database)
+// GetTables 获取指定数据库中的所有表和视图
+func (p *Processor) GetTables(ctx context.Context, database string) ([]connector.TableInfo, error) {
+	return p.connector.GetTables(ctx, database)
 }
 
 // GetTableMetadata 获取表的元数据信息
-func (p *Processor) GetTableMetadata(database, table string) (*connector.TableMetadata, error) {
-	return p.connector.GetTableMetadata(database, table)
+func (p *Processor) GetTableMetadata(ctx context.Context, database, table string) (*connector.TableMetadata, error) {
+	return p.connector.GetTableMetadata(ctx, database, table)
+}
+
+// SupportsRoutines 判断数据库是否支持读取存储过程和函数
+func (p *Processor) SupportsRoutines() bool {
+	_, ok := p.connector.(connector.RoutineConnector)
+	return ok
+}
+
+// GetRoutines 获取指定数据库中的存储过程和函数
+func (p *Processor) GetRoutines(ctx context.Context, database string) ([]connector.Routine, error) {
+	routineConnector, ok := p.connector.(connector.RoutineConnector)
+	if !ok {
+		return nil, fmt.Errorf("该数据库不支持读取存储过程和函数")
+	}
+	return routineConnector.GetRoutines(ctx, database)
+}
+
+// SupportsQueries 判断数据库是否支持分析任意查询的结果结构
+func (p *Processor) SupportsQueries() bool {
+	_, ok := p.connector.(connector.QueryDescriber)
+	return ok
+}
+
+// DescribeQuery 分析查询的结果列和参数，name 作为生成的接口名称
+func (p *Processor) DescribeQuery(ctx context.Context, database, name, query string) (*connector.TableMetadata, error) {
+	describer, ok := p.connector.(connector.QueryDescriber)
+	if !ok {
+		return nil, fmt.Errorf("该数据库不支持分析查询")
+	}
+	metadata, err := describer.DescribeQuery(ctx, database, query)
+	if err != nil {
+		return nil, err
+	}
+	metadata.Name = name
+	return metadata, nil
+}
+
+// SupportsSampling 判断连接器是否支持读取列的样本数据
+func (p *Processor) SupportsSampling() bool {
+	_, ok := p.connector.(connector.ColumnSampler)
+	return ok
+}
+
+// SampleColumn 读取表中最多 limit 个非空的列值，limit 不大于0时使用默认的样本行数
+func (p *Processor) SampleColumn(ctx context.Context, database, table, column string, limit int) ([]string, error) {
+	sampler, ok := p.connector.(connector.ColumnSampler)
+	if !ok {
+		return nil, fmt.Errorf("该数据库不支持读取样本数据")
+	}
+	return sampler.SampleColumn(ctx, database, table, column, limit)
+}
+
+// SupportsPreview 判断连接器是否支持分页读取表数据
+func (p *Processor) SupportsPreview() bool {
+	_, ok := p.connector.(connector.RowPreviewer)
+	return ok
+}
+
+// PreviewRows 在只读事务中读取一页表数据
+func (p *Processor) PreviewRows(ctx context.Context, database, table string, request connector.PreviewRequest) (*connector.PreviewPage, error) {
+	previewer, ok := p.connector.(connector.RowPreviewer)
+	if !ok {
+		return nil, fmt.Errorf("该数据库不支持预览数据")
+	}
+	return previewer.PreviewRows(ctx, database, table, request)
 }
 
 // Close 关闭数据库连接
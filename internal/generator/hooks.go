@@ -0,0 +1,275 @@
+package generator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/dop251/goja"
+	"github.com/dop251/goja/ast"
+	"github.com/dop251/goja/parser"
+	"go-DBmodeler/internal/db/connector"
+)
+
+// 脚本可以定义的生命周期钩子函数
+const (
+	// HookTransformMetadata 在类型映射前调用：transformMetadata(table)，可隐藏或重命名字段
+	HookTransformMetadata = "transformMetadata"
+	// HookMapType 对每个字段调用：mapType(field)，返回字符串时覆盖该字段的TypeScript类型
+	HookMapType = "mapType"
+	// HookPostProcess 在模板渲染后调用：postProcess(code, input)，返回处理后的代码
+	HookPostProcess = "postProcess"
+)
+
+// scriptHooks 是所有支持的钩子名称
+var scriptHooks = []string{HookTransformMetadata, HookMapType, HookPostProcess}
+
+// HookTable 表示传递给 transformMetadata 钩子的表结构
+// 与脚本顶层代码的 input（TemplateData）不同：表名为 name，字段的 column 是原始列名
+type HookTable struct {
+	Name        string                `json:"name"`
+	Schema      string                `json:"schema,omitempty"`
+	Kind        string                `json:"kind"`
+	Definition  string                `json:"definition,omitempty"`
+	Fields      []HookField           `json:"fields"`
+	Params      []HookField           `json:"params,omitempty"`
+	Indexes     []connector.IndexInfo `json:"indexes"`
+	ForeignKeys []HookForeignKey      `json:"foreignKeys,omitempty"`
+}
+
+// HookForeignKey 表示传递给钩子的外键信息
+type HookForeignKey struct {
+	Name       string   `json:"name"`
+	Columns    []string `json:"columns"`
+	RefTable   string   `json:"refTable"`
+	RefColumns []string `json:"refColumns"`
+}
+
+// HookField 表示传递给钩子的字段信息
+// 设置 hidden 为 true 可以在生成结果中隐藏该字段
+type HookField struct {
+	Name       string `json:"name"`
+	Column     string `json:"column"` // 原始列名，重命名字段后保持不变
+	Type       string `json:"type"`
+	TsType     string `json:"tsType,omitempty"`
+	Length     int    `json:"length"`
+	IsNullable bool   `json:"isNullable"`
+	IsPrimary  bool   `json:"isPrimary"`
+	IsUnique   bool   `json:"isUnique"`
+	IsIdentity bool   `json:"isIdentity,omitempty"`
+	Default    string `json:"default"`
+	Comment    string `json:"comment"`
+	Hidden     bool   `json:"hidden,omitempty"`
+}
+
+// DetectScriptHooks 返回脚本顶层定义的钩子函数名称
+// 支持 function 声明、var/let/const 声明以及对全局变量的赋值
+func DetectScriptHooks(script string) (map[string]bool, error) {
+	program, err := parser.ParseFile(nil, scriptFileName, script, 0)
+	if err != nil {
+		return nil, newScriptError(err)
+	}
+
+	declared := make(map[string]bool)
+	addBindings := func(bindings []*ast.Binding) {
+		for _, binding := range bindings {
+			if ident, ok := binding.Target.(*ast.Identifier); ok {
+				declared[string(ident.Name)] = true
+			}
+		}
+	}
+
+	for _, stmt := range program.Body {
+		switch s := stmt.(type) {
+		case *ast.FunctionDeclaration:
+			if s.Function.Name != nil {
+				declared[string(s.Function.Name.Name)] = true
+			}
+		case *ast.VariableStatement:
+			addBindings(s.List)
+		case *ast.LexicalDeclaration:
+			addBindings(s.List)
+		case *ast.ExpressionStatement:
+			if assign, ok := s.Expression.(*ast.AssignExpression); ok {
+				if ident, ok := assign.Left.(*ast.Identifier); ok {
+					declared[string(ident.Name)] = true
+				}
+			}
+		}
+	}
+
+	hooks := make(map[string]bool)
+	for _, name := range scriptHooks {
+		if declared[name] {
+			hooks[name] = true
+		}
+	}
+	return hooks, nil
+}
+
+// newHookTable 根据表元数据创建钩子使用的表结构
+func newHookTable(metadata *connector.TableMetadata) *HookTable {
+	table := &HookTable{
+		Name:       metadata.Name,
+		Schema:     metadata.Schema,
+		Kind:       string(metadata.Kind),
+		Definition: metadata.Definition,
+		Fields:     make([]HookField, 0, len(metadata.Fields)),
+		Indexes:    metadata.Indexes,
+	}
+	for _, key := range metadata.ForeignKeys {
+		table.ForeignKeys = append(table.ForeignKeys, HookForeignKey{
+			Name:       key.Name,
+			Columns:    key.Columns,
+			RefTable:   key.RefTable,
+			RefColumns: key.RefColumns,
+		})
+	}
+	for _, field := range metadata.Fields {
+		table.Fields = append(table.Fields, newHookField(field))
+	}
+	for _, param := range metadata.Params {
+		table.Params = append(table.Params, newHookField(param))
+	}
+	return table
+}
+
+// newHookField 根据字段信息创建钩子使用的字段
+func newHookField(field connector.FieldInfo) HookField {
+	return HookField{
+		Name:       field.Name,
+		Column:     field.Name,
+		Type:       field.Type,
+		Length:     field.Length,
+		IsNullable: field.IsNullable,
+		IsPrimary:  field.IsPrimary,
+		IsUnique:   field.IsUnique,
+		IsIdentity: field.IsIdentity,
+		Default:    field.Default,
+		Comment:    field.Comment,
+	}
+}
+
+// generateWithHooks 按钩子流程生成代码：
+// transformMetadata -> 类型映射（mapType）-> 模板渲染 -> postProcess
+func (g *Generator) generateWithHooks(metadata *connector.TableMetadata, processor *JavaScriptProcessor, hooks map[string]bool) (string, error) {
+	// 加载脚本，顶层代码的 input 与不使用钩子时相同，是钩子处理前的模板数据
+	input, _ := g.templateData(metadata)
+	if err := processor.Load(g.script, input); err != nil {
+		return "", err
+	}
+
+	table := newHookTable(metadata)
+
+	// 类型映射前修改元数据
+	if hooks[HookTransformMetadata] {
+		transformed, err := g.callTransformMetadata(processor, table)
+		if err != nil {
+			return "", err
+		}
+		table = transformed
+	}
+
+	// 准备模板数据，逐个字段映射类型
+	data := TemplateData{
+		TableName:  table.Name,
+		Kind:       table.Kind,
+		ReadOnly:   connector.ObjectKind(table.Kind).ReadOnly(),
+		Definition: table.Definition,
+		Fields:     make([]FieldData, 0, len(table.Fields)),
+	}
+	var columns []string
+	for _, field := range table.Fields {
+		if field.Hidden {
+			continue
+		}
+
+		// 保存了JSON结构的列使用结构的类型，mapType 仍可以覆盖
+		if tsType, ok := g.jsonShapeType(field.Column); ok {
+			field.TsType = tsType
+			columns = append(columns, field.Column)
+		} else {
+			field.TsType = g.mapper.Map(field.Type)
+		}
+		if hooks[HookMapType] {
+			result, err := processor.CallHook(HookMapType, field)
+			if err != nil {
+				return "", err
+			}
+			if tsType := hookString(result); tsType != "" {
+				field.TsType = tsType
+			}
+		}
+
+		data.Fields = append(data.Fields, FieldData{
+			Name:     field.Name,
+			TsType:   field.TsType,
+			Nullable: field.IsNullable,
+			Comment:  field.Comment,
+		})
+	}
+
+	// 查询的参数只使用类型映射器，不调用 mapType
+	for _, param := range table.Params {
+		data.Params = append(data.Params, FieldData{
+			Name:     param.Name,
+			TsType:   g.mapper.Map(param.Type),
+			Nullable: param.IsNullable,
+		})
+	}
+
+	// 执行模板
+	var buf bytes.Buffer
+	if err := g.template.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	code := g.withJSONShapes(buf.String(), columns)
+
+	// 模板渲染后处理代码
+	if hooks[HookPostProcess] {
+		result, err := processor.CallHook(HookPostProcess, code, data)
+		if err != nil {
+			return "", err
+		}
+		if processed := hookString(result); processed != "" {
+			code = g.withJSONShapes(processed, columns)
+		}
+	}
+
+	return code, nil
+}
+
+// callTransformMetadata 调用 transformMetadata 钩子
+// 钩子可以返回新的表结构，也可以直接修改传入的对象
+func (g *Generator) callTransformMetadata(processor *JavaScriptProcessor, table *HookTable) (*HookTable, error) {
+	plain, err := toPlainValue(table)
+	if err != nil {
+		return nil, err
+	}
+	arg := processor.vm.ToValue(plain)
+
+	value, err := processor.CallHook(HookTransformMetadata, arg)
+	if err != nil {
+		return nil, err
+	}
+	if value == nil || goja.IsUndefined(value) || goja.IsNull(value) {
+		value = arg
+	}
+
+	data, err := json.Marshal(value.Export())
+	if err != nil {
+		return nil, fmt.Errorf("%s 返回的表结构无法序列化: %v", HookTransformMetadata, err)
+	}
+	var transformed HookTable
+	if err := json.Unmarshal(data, &transformed); err != nil {
+		return nil, fmt.Errorf("%s 返回的表结构无效: %v", HookTransformMetadata, err)
+	}
+	return &transformed, nil
+}
+
+// hookString 将钩子返回值转换为字符串，undefined 和 null 返回空字符串
+func hookString(value goja.Value) string {
+	if value == nil || goja.IsUndefined(value) || goja.IsNull(value) {
+		return ""
+	}
+	return value.String()
+}
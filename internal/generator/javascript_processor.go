@@ -2,16 +2,93 @@ package generator
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/dop251/goja"
+	"github.com/dop251/goja/parser"
 	"go-DBmodeler/pkg/logger"
 	"strings"
+	"time"
 )
 
+// scriptFileName 是脚本在错误信息和调用栈中显示的文件名
+const scriptFileName = "script.js"
+
+// ConsoleEntry 表示脚本通过 console 输出的一条记录
+type ConsoleEntry struct {
+	Time    time.Time // 输出时间
+	Level   string    // 级别：log, warn, error
+	Message string    // 输出内容
+}
+
+// ScriptError 表示脚本编译或执行错误，包含出错位置和调用栈
+type ScriptError struct {
+	Message string // 错误信息
+	Line    int    // 出错行号（从1开始，未知时为0）
+	Column  int    // 出错列号（从1开始，未知时为0）
+	Stack   string // 调用栈
+}
+
+// Error 实现error接口
+func (e *ScriptError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("JavaScript执行错误: %s (第%d行，第%d列)", e.Message, e.Line, e.Column)
+	}
+	return fmt.Sprintf("JavaScript执行错误: %s", e.Message)
+}
+
+// newScriptError 将goja返回的错误转换为带位置信息的脚本错误
+func newScriptError(err error) *ScriptError {
+	var parseErrs parser.ErrorList
+	if errors.As(err, &parseErrs) && len(parseErrs) > 0 {
+		first := parseErrs[0]
+		return &ScriptError{
+			Message: "语法错误: " + first.Message,
+			Line:    first.Position.Line,
+			Column:  first.Position.Column,
+		}
+	}
+
+	var syntaxErr *goja.CompilerSyntaxError
+	if errors.As(err, &syntaxErr) {
+		scriptErr := &ScriptError{Message: "语法错误: " + syntaxErr.Message}
+		if syntaxErr.File != nil {
+			pos := syntaxErr.File.Position(syntaxErr.Offset)
+			scriptErr.Line, scriptErr.Column = pos.Line, pos.Column
+		}
+		return scriptErr
+	}
+
+	var exception *goja.Exception
+	if errors.As(err, &exception) {
+		scriptErr := &ScriptError{Message: exception.Value().String()}
+
+		// 取脚本内最内层的调用位置作为出错位置
+		var stack strings.Builder
+		for _, frame := range exception.Stack() {
+			pos := frame.Position()
+			if frame.SrcName() == scriptFileName && scriptErr.Line == 0 && pos.Line > 0 {
+				scriptErr.Line, scriptErr.Column = pos.Line, pos.Column
+			}
+			funcName := frame.FuncName()
+			if funcName == "" {
+				funcName = "<main>"
+			}
+			fmt.Fprintf(&stack, "    at %s (%s:%d:%d)\n", funcName, frame.SrcName(), pos.Line, pos.Column)
+		}
+		scriptErr.Stack = strings.TrimRight(stack.String(), "\n")
+		return scriptErr
+	}
+
+	return &ScriptError{Message: err.Error()}
+}
+
 // JavaScriptProcessor 表示JavaScript脚本处理器
 type JavaScriptProcessor struct {
-	log *logger.Logger
-	vm  *goja.Runtime
+	log    *logger.Logger
+	vm     *goja.Runtime
+	params map[string]interface{} // 脚本参数
+	logs   []ConsoleEntry         // 本次运行的控制台输出
 }
 
 // NewJavaScriptProcessor 创建一个新的JavaScript处理器
@@ -22,18 +99,81 @@ func NewJavaScriptProcessor(log *logger.Logger) *JavaScriptProcessor {
 	}
 }
 
+// SetParams 设置传递给脚本的参数，脚本中通过 params 变量访问
+func (p *JavaScriptProcessor) SetParams(params map[string]interface{}) {
+	p.params = params
+}
+
 // Process 使用JavaScript脚本处理表结构数据和生成的TypeScript代码
 func (p *JavaScriptProcessor) Process(tsCode string, script string, metadata interface{}) (string, error) {
 	if strings.TrimSpace(script) == "" {
 		return tsCode, nil // 如果没有脚本，直接返回原代码
 	}
 
+	if err := p.run(tsCode, script, metadata); err != nil {
+		return "", err
+	}
+
+	// 获取处理后的结果
+	outputVal := p.vm.Get("output")
+	if outputVal == nil || goja.IsUndefined(outputVal) {
+		return tsCode, nil // 如果没有输出，返回原代码
+	}
+
+	output := outputVal.String()
+	if output == "" {
+		return tsCode, nil // 如果输出为空，返回原代码
+	}
+
+	return output, nil
+}
+
+// Load 加载脚本并执行其顶层代码，之后可以通过 CallHook 调用脚本定义的钩子函数
+func (p *JavaScriptProcessor) Load(script string, metadata interface{}) error {
+	return p.run("", script, metadata)
+}
+
+// CallHook 调用脚本在顶层定义的函数，函数不存在时返回 nil
+// 除 goja.Value 外的参数会先转换为普通的JavaScript对象
+func (p *JavaScriptProcessor) CallHook(name string, args ...interface{}) (goja.Value, error) {
+	// 通过求值标识符获取函数，这样 let/const 声明的函数也能找到
+	value, err := p.vm.RunString("typeof " + name + " === 'function' ? " + name + " : undefined")
+	if err != nil {
+		return nil, newScriptError(err)
+	}
+	fn, ok := goja.AssertFunction(value)
+	if !ok {
+		return nil, nil
+	}
+
+	jsArgs := make([]goja.Value, 0, len(args))
+	for _, arg := range args {
+		if value, ok := arg.(goja.Value); ok {
+			jsArgs = append(jsArgs, value)
+			continue
+		}
+		plain, err := toPlainValue(arg)
+		if err != nil {
+			return nil, err
+		}
+		jsArgs = append(jsArgs, p.vm.ToValue(plain))
+	}
+
+	result, err := fn(goja.Undefined(), jsArgs...)
+	if err != nil {
+		return nil, newScriptError(err)
+	}
+	return result, nil
+}
+
+// run 设置全局变量并执行脚本
+func (p *JavaScriptProcessor) run(tsCode string, script string, metadata interface{}) error {
 	// 将Go结构体转换为JSON字符串，然后传递给JavaScript环境
 	var inputJSON string
 	if metadata != nil {
 		jsonBytes, err := json.Marshal(metadata)
 		if err != nil {
-			return "", fmt.Errorf("JSON序列化错误: %v", err)
+			return fmt.Errorf("JSON序列化错误: %v", err)
 		}
 		inputJSON = string(jsonBytes)
 	} else {
@@ -51,36 +191,101 @@ func (p *JavaScriptProcessor) Process(tsCode string, script string, metadata int
 		parsedInput = map[string]interface{}{}
 	}
 	p.vm.Set("input", parsedInput)
+
+	// 设置脚本参数
+	params := p.params
+	if params == nil {
+		params = map[string]interface{}{}
+	}
+	p.vm.Set("params", params)
 	p.vm.Set("console", map[string]interface{}{
-		"log": func(args ...interface{}) {
-			p.log.Infof("JS Console: %v", args...)
-		},
-		"warn": func(args ...interface{}) {
-			p.log.Warnf("JS Console: %v", args...)
-		},
-		"error": func(args ...interface{}) {
-			p.log.Errorf("JS Console: %v", args...)
-		},
+		"log":   p.consoleFunc("log"),
+		"info":  p.consoleFunc("log"),
+		"warn":  p.consoleFunc("warn"),
+		"error": p.consoleFunc("error"),
 	})
 
-	// 执行脚本
-	_, err := p.vm.RunString(script)
+	// 解析、编译并执行脚本
+	ast, err := parser.ParseFile(nil, scriptFileName, script, 0)
+	if err != nil {
+		return newScriptError(err)
+	}
+	program, err := goja.CompileAST(ast, false)
 	if err != nil {
-		return "", fmt.Errorf("JavaScript执行错误: %v", err)
+		return newScriptError(err)
+	}
+	if _, err := p.vm.RunProgram(program); err != nil {
+		return newScriptError(err)
 	}
 
-	// 获取处理后的结果
-	outputVal := p.vm.Get("output")
-	if outputVal == nil || goja.IsUndefined(outputVal) {
-		return tsCode, nil // 如果没有输出，返回原代码
+	return nil
+}
+
+// toPlainValue 通过JSON将Go值转换为由map和slice组成的普通值
+func toPlainValue(value interface{}) (interface{}, error) {
+	if s, ok := value.(string); ok {
+		return s, nil
 	}
 
-	output := outputVal.String()
-	if output == "" {
-		return tsCode, nil // 如果输出为空，返回原代码
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("JSON序列化错误: %v", err)
+	}
+	var plain interface{}
+	if err := json.Unmarshal(data, &plain); err != nil {
+		return nil, fmt.Errorf("JSON解析错误: %v", err)
 	}
+	return plain, nil
+}
 
-	return output, nil
+// Logs 返回脚本运行期间的控制台输出
+func (p *JavaScriptProcessor) Logs() []ConsoleEntry {
+	return p.logs
+}
+
+// consoleFunc 创建指定级别的console函数，记录输出并写入日志
+func (p *JavaScriptProcessor) consoleFunc(level string) func(goja.FunctionCall) goja.Value {
+	return func(call goja.FunctionCall) goja.Value {
+		parts := make([]string, 0, len(call.Arguments))
+		for _, arg := range call.Arguments {
+			parts = append(parts, formatConsoleValue(arg))
+		}
+		message := strings.Join(parts, " ")
+
+		p.logs = append(p.logs, ConsoleEntry{
+			Time:    time.Now(),
+			Level:   level,
+			Message: message,
+		})
+
+		switch level {
+		case "warn":
+			p.log.Warnf("JS Console: %s", message)
+		case "error":
+			p.log.Errorf("JS Console: %s", message)
+		default:
+			p.log.Infof("JS Console: %s", message)
+		}
+		return goja.Undefined()
+	}
+}
+
+// formatConsoleValue 将console参数格式化为字符串，对象和数组输出为JSON
+func formatConsoleValue(value goja.Value) string {
+	if value == nil || goja.IsUndefined(value) {
+		return "undefined"
+	}
+	if goja.IsNull(value) {
+		return "null"
+	}
+
+	switch exported := value.Export().(type) {
+	case map[string]interface{}, []interface{}:
+		if data, err := json.Marshal(exported); err == nil {
+			return string(data)
+		}
+	}
+	return value.String()
 }
 
 // ProcessWithDefaultScript 使用默认脚本处理TypeScript代码
@@ -0,0 +1,326 @@
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"go-DBmodeler/internal/config"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// JSON值的种类，Shape 用位集合记录出现过的种类
+const (
+	shapeString = 1 << iota
+	shapeNumber
+	shapeBoolean
+	shapeObject
+	shapeArray
+	shapeNull
+)
+
+// Shape 是从JSON样本推断出的结构类型
+// 同一位置出现过多种值时为联合类型，对象的键在部分样本中缺失时为可选
+type Shape struct {
+	kinds   int
+	fields  []*shapeField // 对象的键，按首次出现的顺序排列
+	objects int           // 合并的对象个数，用于判断键是否可选
+	items   *Shape        // 数组元素的结构
+}
+
+// shapeField 表示对象的一个键
+type shapeField struct {
+	name  string
+	count int // 包含该键的对象个数
+	shape *Shape
+}
+
+// InferShape 合并所有样本推断JSON结构，返回无法解析为JSON的样本个数
+// 所有样本都无法解析时返回错误
+func InferShape(samples []string) (*Shape, int, error) {
+	shape := &Shape{}
+	invalid := 0
+	for _, sample := range samples {
+		sampleShape := &Shape{}
+		if err := decodeShape(json.NewDecoder(strings.NewReader(sample)), sampleShape); err != nil {
+			invalid++
+			continue
+		}
+		shape.merge(sampleShape)
+	}
+
+	if len(samples) == 0 {
+		return nil, 0, fmt.Errorf("没有样本数据")
+	}
+	if invalid == len(samples) {
+		return nil, invalid, fmt.Errorf("%d 个样本都不是有效的JSON", invalid)
+	}
+	return shape, invalid, nil
+}
+
+// decodeShape 读取一个完整的JSON值并记录到 shape，值之后不能有其他内容
+func decodeShape(dec *json.Decoder, shape *Shape) error {
+	token, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if err := decodeValue(dec, token, shape); err != nil {
+		return err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return fmt.Errorf("JSON值之后有多余的内容")
+	}
+	return nil
+}
+
+// decodeValue 从已读取的 token 开始读取一个JSON值，按顺序保留对象的键
+func decodeValue(dec *json.Decoder, token json.Token, shape *Shape) error {
+	switch value := token.(type) {
+	case string:
+		shape.kinds |= shapeString
+	case float64:
+		shape.kinds |= shapeNumber
+	case bool:
+		shape.kinds |= shapeBoolean
+	case nil:
+		shape.kinds |= shapeNull
+	case json.Delim:
+		switch value {
+		case '{':
+			shape.kinds |= shapeObject
+			shape.objects++
+			// 同一个对象中重复的键只计数一次
+			seen := make(map[string]bool)
+			for dec.More() {
+				keyToken, err := dec.Token()
+				if err != nil {
+					return err
+				}
+				valueToken, err := dec.Token()
+				if err != nil {
+					return err
+				}
+				key := keyToken.(string)
+				field := shape.field(key)
+				if !seen[key] {
+					seen[key] = true
+					field.count++
+				}
+				if err := decodeValue(dec, valueToken, field.shape); err != nil {
+					return err
+				}
+			}
+		case '[':
+			shape.kinds |= shapeArray
+			if shape.items == nil {
+				shape.items = &Shape{}
+			}
+			for dec.More() {
+				itemToken, err := dec.Token()
+				if err != nil {
+					return err
+				}
+				if err := decodeValue(dec, itemToken, shape.items); err != nil {
+					return err
+				}
+			}
+		}
+		// 读取结束的 } 或 ]
+		if _, err := dec.Token(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// field 返回对象中名为 name 的键，不存在时添加
+func (s *Shape) field(name string) *shapeField {
+	for _, field := range s.fields {
+		if field.name == name {
+			return field
+		}
+	}
+	field := &shapeField{name: name, shape: &Shape{}}
+	s.fields = append(s.fields, field)
+	return field
+}
+
+// merge 将另一个结构合并到 s
+func (s *Shape) merge(other *Shape) {
+	s.kinds |= other.kinds
+	s.objects += other.objects
+	for _, otherField := range other.fields {
+		var field *shapeField
+		for _, existing := range s.fields {
+			if existing.name == otherField.name {
+				field = existing
+				break
+			}
+		}
+		if field == nil {
+			field = &shapeField{name: otherField.name, shape: &Shape{}}
+			s.fields = append(s.fields, field)
+		}
+		field.count += otherField.count
+		field.shape.merge(otherField.shape)
+	}
+	if other.items != nil {
+		if s.items == nil {
+			s.items = &Shape{}
+		}
+		s.items.merge(other.items)
+	}
+}
+
+// Declarations 生成以 typeName 命名的TypeScript类型声明
+// 根结构只是对象时生成接口，否则生成类型别名；嵌套的对象生成以父类型名称加键名命名的接口
+func (s *Shape) Declarations(typeName string) string {
+	w := &shapeWriter{names: map[string]bool{typeName: true}}
+	if s.kinds == shapeObject && len(s.fields) > 0 {
+		w.writeInterface(typeName, s)
+	} else {
+		w.declarations = append(w.declarations, "")
+		expr := w.typeExpr(s, typeName, typeName+"Object")
+		w.declarations[0] = fmt.Sprintf("export type %s = %s;\n", typeName, expr)
+	}
+	return strings.Join(w.declarations, "\n")
+}
+
+// shapeWriter 收集生成的类型声明，避免嵌套类型重名
+type shapeWriter struct {
+	declarations []string
+	names        map[string]bool
+}
+
+// writeInterface 生成对象的接口声明，嵌套类型的声明排在其后
+func (w *shapeWriter) writeInterface(name string, s *Shape) {
+	index := len(w.declarations)
+	w.declarations = append(w.declarations, "")
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "export interface %s {\n", name)
+	for _, field := range s.fields {
+		optional := ""
+		if field.count < s.objects {
+			optional = "?"
+		}
+		fieldName := w.uniqueName(name + shapeTypeName(field.name))
+		expr := w.typeExpr(field.shape, fieldName, fieldName)
+		fmt.Fprintf(&b, "  %s%s: %s;\n", shapeKey(field.name), optional, expr)
+	}
+	b.WriteString("}\n")
+	w.declarations[index] = b.String()
+}
+
+// typeExpr 返回结构对应的TypeScript类型表达式
+// 对象使用 objectName 生成接口，数组元素的类型以 name 加 Item 命名
+func (w *shapeWriter) typeExpr(s *Shape, name, objectName string) string {
+	var parts []string
+	if s.kinds&shapeString != 0 {
+		parts = append(parts, "string")
+	}
+	if s.kinds&shapeNumber != 0 {
+		parts = append(parts, "number")
+	}
+	if s.kinds&shapeBoolean != 0 {
+		parts = append(parts, "boolean")
+	}
+	if s.kinds&shapeObject != 0 {
+		if len(s.fields) == 0 {
+			parts = append(parts, "Record<string, unknown>")
+		} else {
+			w.names[objectName] = true
+			w.writeInterface(objectName, s)
+			parts = append(parts, objectName)
+		}
+	}
+	if s.kinds&shapeArray != 0 {
+		item := "unknown"
+		if s.items != nil && s.items.kinds != 0 {
+			itemName := w.uniqueName(name + "Item")
+			item = w.typeExpr(s.items, itemName, itemName)
+		}
+		if strings.Contains(item, " | ") {
+			item = "(" + item + ")"
+		}
+		parts = append(parts, item+"[]")
+	}
+	if s.kinds&shapeNull != 0 {
+		parts = append(parts, "null")
+	}
+
+	if len(parts) == 0 {
+		return "unknown"
+	}
+	return strings.Join(parts, " | ")
+}
+
+// uniqueName 返回没有使用过的类型名称，重名时加数字后缀
+func (w *shapeWriter) uniqueName(name string) string {
+	unique := name
+	for i := 2; w.names[unique]; i++ {
+		unique = name + strconv.Itoa(i)
+	}
+	return unique
+}
+
+// shapeTypeName 将对象的键转换为嵌套类型名称的一部分
+func shapeTypeName(key string) string {
+	if len(splitWords(key)) == 0 {
+		return "Value"
+	}
+	return pascalCase(key)
+}
+
+// identifierPattern 匹配不需要加引号的属性名
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// shapeKey 返回接口中的属性名，不是合法标识符时加引号
+func shapeKey(key string) string {
+	if identifierPattern.MatchString(key) {
+		return key
+	}
+	return strconv.Quote(key)
+}
+
+// SetJSONShapes 设置JSON列的结构，键为列名
+// 设置后这些列的字段类型为结构的类型名称，生成的代码前加上结构的类型声明
+func (g *Generator) SetJSONShapes(shapes map[string]config.JSONShape) {
+	g.jsonShapes = shapes
+}
+
+// jsonShapeType 返回为列保存的JSON结构类型名称
+func (g *Generator) jsonShapeType(column string) (string, bool) {
+	shape, ok := g.jsonShapes[column]
+	if !ok {
+		return "", false
+	}
+	return shape.TypeName, true
+}
+
+// withJSONShapes 在生成的代码前加上 columns 中各列的JSON结构类型声明
+// 代码中已经声明了结构的类型名称时不再添加，因此脚本重新生成代码后可以再次调用
+func (g *Generator) withJSONShapes(code string, columns []string) string {
+	var b strings.Builder
+	for _, column := range columns {
+		shape, ok := g.jsonShapes[column]
+		if !ok || strings.TrimSpace(shape.Declarations) == "" || declaresType(code, shape.TypeName) {
+			continue
+		}
+		b.WriteString(strings.TrimSpace(shape.Declarations))
+		b.WriteString("\n\n")
+	}
+	return b.String() + code
+}
+
+// declaresType 判断代码中是否有名为 name 的接口或类型声明
+func declaresType(code, name string) bool {
+	pattern := regexp.MustCompile(`\b(interface|type|class)\s+` + regexp.QuoteMeta(name) + `\b`)
+	return pattern.MatchString(code)
+}
+
+// JSONShapeTypeName 返回JSON列结构的默认类型名称，例如 users 表的 profile 列为 UsersProfile
+func JSONShapeTypeName(table, column string) string {
+	return pascalCase(table) + shapeTypeName(column)
+}
@@ -0,0 +1,138 @@
+package generator
+
+import (
+	"bytes"
+	"go-DBmodeler/internal/config"
+	"go-DBmodeler/internal/db/connector"
+	"strings"
+	"unicode"
+)
+
+// 例程模板中调用函数的返回方式
+const (
+	ReturnsRows   = "rows"   // 返回所有结果行
+	ReturnsRow    = "row"    // 返回第一行
+	ReturnsValues = "values" // 返回每行的 result 列
+	ReturnsValue  = "value"  // 返回第一行的 result 列
+	ReturnsVoid   = "void"   // 没有返回值
+)
+
+// RoutineTemplateData 表示存储过程和函数模板的数据
+type RoutineTemplateData struct {
+	Name         string      `json:"name"`         // 例程名称
+	Schema       string      `json:"schema"`       // 所在的架构
+	Signature    string      `json:"signature"`    // 包含参数类型的签名
+	Kind         string      `json:"kind"`         // function 或 procedure
+	Comment      string      `json:"comment"`      // 注释
+	TypeName     string      `json:"typeName"`     // 接口名称的前缀，例如 GetUserOrders
+	FunctionName string      `json:"functionName"` // 调用函数的名称，例如 getUserOrders
+	Params       []FieldData `json:"params"`       // 调用时传入的参数
+	Results      []FieldData `json:"results"`      // 结果行的列：返回的列或 OUT 参数
+	ReturnTsType string      `json:"returnTsType"` // 返回单个值时值的类型
+	ResultType   string      `json:"resultType"`   // 调用函数的返回类型，例如 GetUserOrdersResult[]、number 或 void
+	RowType      string      `json:"rowType"`      // 结果行的类型，没有返回值时为空
+	Returns      string      `json:"returns"`      // 返回方式，取值为 Returns* 常量之一
+	CallSQL      string      `json:"callSQL"`      // 调用例程的SQL
+	CallArgs     []string    `json:"callArgs"`     // CallSQL 中占位符对应的参数名称
+}
+
+// DefaultRoutineTemplate 返回存储过程和函数的默认模板
+func DefaultRoutineTemplate() string {
+	return config.DefaultRoutineTemplate()
+}
+
+// GenerateRoutine 使用模板为存储过程或函数生成参数和结果接口以及调用函数
+// 生成器的模板应为例程模板，例程不运行脚本
+func (g *Generator) GenerateRoutine(routine *connector.Routine) (string, error) {
+	g.consoleLogs = nil
+
+	data := RoutineTemplateData{
+		Name:         routine.Name,
+		Schema:       routine.Schema,
+		Signature:    routine.Signature,
+		Kind:         string(routine.Kind),
+		Comment:      routine.Comment,
+		TypeName:     pascalCase(routine.Name),
+		FunctionName: camelCase(routine.Name),
+		Params:       make([]FieldData, 0, len(routine.Params)),
+		CallSQL:      routine.CallSQL,
+		CallArgs:     routine.CallArgs,
+	}
+
+	// 传入的参数，可变参数映射为数组
+	for _, param := range routine.Params {
+		if param.IsInput() {
+			tsType := g.mapper.Map(param.Type)
+			if param.Mode == connector.ParamVariadic && !strings.HasSuffix(tsType, "[]") {
+				tsType += "[]"
+			}
+			data.Params = append(data.Params, FieldData{Name: param.Name, TsType: tsType})
+		}
+	}
+
+	// 结果列优先使用返回的列，否则使用 OUT 参数
+	for _, column := range routine.Columns {
+		data.Results = append(data.Results, FieldData{Name: column.Name, TsType: g.mapper.Map(column.Type)})
+	}
+	if len(data.Results) == 0 {
+		for _, param := range routine.Params {
+			if param.IsOutput() {
+				data.Results = append(data.Results, FieldData{Name: param.Name, TsType: g.mapper.Map(param.Type)})
+			}
+		}
+	}
+
+	// 确定调用函数的返回方式
+	resultName := data.TypeName + "Result"
+	switch {
+	case len(data.Results) > 0 && routine.ReturnsSet:
+		data.Returns, data.RowType, data.ResultType = ReturnsRows, resultName, resultName+"[]"
+	case len(data.Results) > 0:
+		data.Returns, data.RowType, data.ResultType = ReturnsRow, resultName, resultName
+	case routine.ReturnType != "":
+		data.ReturnTsType = g.mapper.Map(routine.ReturnType)
+		data.RowType = "{ result: " + data.ReturnTsType + " }"
+		if routine.ReturnsSet {
+			data.Returns, data.ResultType = ReturnsValues, data.ReturnTsType+"[]"
+		} else {
+			data.Returns, data.ResultType = ReturnsValue, data.ReturnTsType
+		}
+	default:
+		data.Returns, data.ResultType = ReturnsVoid, "void"
+	}
+
+	// 执行模板
+	var buf bytes.Buffer
+	if err := g.template.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// splitWords 按非字母数字字符拆分名称，例如 get_user_orders 拆分为 get、user、orders
+func splitWords(name string) []string {
+	return strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// pascalCase 将名称转换为大驼峰形式，例如 get_user_orders 转换为 GetUserOrders
+func pascalCase(name string) string {
+	var b strings.Builder
+	for _, word := range splitWords(name) {
+		runes := []rune(word)
+		b.WriteRune(unicode.ToUpper(runes[0]))
+		b.WriteString(string(runes[1:]))
+	}
+	if b.Len() == 0 {
+		return "Routine"
+	}
+	return b.String()
+}
+
+// camelCase 将名称转换为小驼峰形式，例如 get_user_orders 转换为 getUserOrders
+func camelCase(name string) string {
+	runes := []rune(pascalCase(name))
+	runes[0] = unicode.ToLower(runes[0])
+	return string(runes)
+}
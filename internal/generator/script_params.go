@@ -0,0 +1,194 @@
+package generator
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ScriptParamType 表示脚本参数类型
+type ScriptParamType string
+
+const (
+	ParamTypeString ScriptParamType = "string" // 字符串
+	ParamTypeBool   ScriptParamType = "bool"   // 布尔值
+	ParamTypeEnum   ScriptParamType = "enum"   // 枚举（从选项中选择）
+	ParamTypeNumber ScriptParamType = "number" // 数字
+)
+
+// ScriptParam 表示脚本头部声明的参数
+//
+// 参数在脚本开头的注释中声明，每行一个：
+//
+//	// @param <名称> <类型> <默认值> [说明]
+//
+// 类型为 string、bool、number 或 enum(选项1|选项2)，
+// 包含空格的默认值需要使用双引号括起来，例如：
+//
+//	// @param header string "Do not edit this file manually" 文件头说明
+//	// @param style enum(interface|class) interface 输出风格
+type ScriptParam struct {
+	Name    string          `json:"name"`
+	Type    ScriptParamType `json:"type"`
+	Default string          `json:"default"`
+	Options []string        `json:"options,omitempty"`
+	Label   string          `json:"label,omitempty"`
+}
+
+// ParseScriptParams 解析脚本头部块中声明的参数
+// 头部块是脚本开头连续的注释行，遇到第一行代码即停止解析
+func ParseScriptParams(script string) ([]ScriptParam, error) {
+	var params []ScriptParam
+	seen := make(map[string]bool)
+
+	scanner := bufio.NewScanner(strings.NewReader(script))
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if !strings.HasPrefix(line, "//") {
+			break
+		}
+
+		body := strings.TrimSpace(strings.TrimPrefix(line, "//"))
+		if !strings.HasPrefix(body, "@param ") {
+			continue
+		}
+
+		param, err := parseParamLine(strings.TrimSpace(strings.TrimPrefix(body, "@param ")))
+		if err != nil {
+			return nil, fmt.Errorf("第%d行参数声明错误: %v", lineNo, err)
+		}
+		if seen[param.Name] {
+			return nil, fmt.Errorf("第%d行参数重复声明: %s", lineNo, param.Name)
+		}
+		seen[param.Name] = true
+		params = append(params, param)
+	}
+
+	return params, nil
+}
+
+// parseParamLine 解析单行参数声明
+func parseParamLine(decl string) (ScriptParam, error) {
+	var param ScriptParam
+
+	name, rest := nextToken(decl)
+	if name == "" {
+		return param, fmt.Errorf("缺少参数名")
+	}
+	param.Name = name
+
+	typeToken, rest := nextToken(rest)
+	switch {
+	case typeToken == string(ParamTypeString), typeToken == string(ParamTypeBool), typeToken == string(ParamTypeNumber):
+		param.Type = ScriptParamType(typeToken)
+	case strings.HasPrefix(typeToken, "enum(") && strings.HasSuffix(typeToken, ")"):
+		param.Type = ParamTypeEnum
+		for _, opt := range strings.Split(typeToken[len("enum("):len(typeToken)-1], "|") {
+			if opt = strings.TrimSpace(opt); opt != "" {
+				param.Options = append(param.Options, opt)
+			}
+		}
+		if len(param.Options) == 0 {
+			return param, fmt.Errorf("枚举参数 %s 没有选项", name)
+		}
+	case typeToken == "":
+		return param, fmt.Errorf("参数 %s 缺少类型", name)
+	default:
+		return param, fmt.Errorf("参数 %s 的类型 %s 不受支持", name, typeToken)
+	}
+
+	// 解析默认值，支持双引号字符串
+	rest = strings.TrimSpace(rest)
+	if strings.HasPrefix(rest, `"`) {
+		end := closingQuote(rest)
+		if end < 0 {
+			return param, fmt.Errorf("参数 %s 的默认值缺少结束引号", name)
+		}
+		if err := json.Unmarshal([]byte(rest[:end+1]), &param.Default); err != nil {
+			return param, fmt.Errorf("参数 %s 的默认值无效: %v", name, err)
+		}
+		rest = rest[end+1:]
+	} else {
+		param.Default, rest = nextToken(rest)
+	}
+	param.Label = strings.TrimSpace(rest)
+
+	if _, err := param.Convert(param.Default); err != nil {
+		return param, fmt.Errorf("参数 %s 的默认值无效: %v", name, err)
+	}
+
+	return param, nil
+}
+
+// nextToken 返回下一个以空白分隔的词和剩余部分
+func nextToken(s string) (string, string) {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexAny(s, " \t"); i >= 0 {
+		return s[:i], s[i:]
+	}
+	return s, ""
+}
+
+// closingQuote 返回与开头双引号匹配的结束引号位置
+func closingQuote(s string) int {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return -1
+}
+
+// Convert 将界面输入的字符串值转换为参数类型对应的值
+func (p ScriptParam) Convert(value string) (interface{}, error) {
+	switch p.Type {
+	case ParamTypeBool:
+		if value == "" {
+			return false, nil
+		}
+		return strconv.ParseBool(value)
+	case ParamTypeNumber:
+		if value == "" {
+			return float64(0), nil
+		}
+		return strconv.ParseFloat(value, 64)
+	case ParamTypeEnum:
+		for _, opt := range p.Options {
+			if opt == value {
+				return value, nil
+			}
+		}
+		return nil, fmt.Errorf("%q 不是有效选项，可选值: %s", value, strings.Join(p.Options, ", "))
+	default:
+		return value, nil
+	}
+}
+
+// ResolveScriptParams 根据参数声明和用户输入值生成传递给脚本的参数对象
+// 未提供的值使用默认值，无效的值返回错误
+func ResolveScriptParams(params []ScriptParam, values map[string]string) (map[string]interface{}, error) {
+	resolved := make(map[string]interface{}, len(params))
+	for _, param := range params {
+		value, ok := values[param.Name]
+		if !ok {
+			value = param.Default
+		}
+
+		converted, err := param.Convert(value)
+		if err != nil {
+			return nil, fmt.Errorf("参数 %s 无效: %v", param.Name, err)
+		}
+		resolved[param.Name] = converted
+	}
+	return resolved, nil
+}
@@ -3,52 +3,57 @@ package generator
 import (
 	"bytes"
 	"fmt"
+	"go-DBmodeler/internal/config"
 	"go-DBmodeler/internal/db/connector"
+	"go-DBmodeler/internal/db/dialect"
 	"go-DBmodeler/pkg/logger"
 	"text/template"
 )
 
 // TemplateData 表示模板数据
+// ReadOnly 对视图和物化视图为 true，默认模板为它们生成 readonly 属性
+// 生成查询时 Kind 为 query，Definition 为查询语句，Params 为按位置排列的参数
 type TemplateData struct {
-	TableName string      `json:"tableName"`
-	Fields    []FieldData `json:"fields"`
+	TableName  string      `json:"tableName"`
+	Kind       string      `json:"kind"`
+	ReadOnly   bool        `json:"readOnly"`
+	Definition string      `json:"definition,omitempty"`
+	Fields     []FieldData `json:"fields"`
+	Params     []FieldData `json:"params,omitempty"`
 }
 
 // FieldData 表示字段数据
 type FieldData struct {
-	Name    string `json:"name"`
-	TsType  string `json:"tsType"`
-	Comment string `json:"comment"`
+	Name     string `json:"name"`
+	TsType   string `json:"tsType"`
+	Nullable bool   `json:"nullable"`
+	Comment  string `json:"comment"`
 }
 
 // Generator 表示TypeScript模型生成器
 type Generator struct {
-	mapper        TypeMapper
+	mapper        dialect.TypeMapper
 	template      *template.Template
 	log           *logger.Logger
-	script        string         // JavaScript处理脚本
-	scriptManager *ScriptManager // 脚本管理器
+	script        string                      // JavaScript处理脚本
+	scriptParams  map[string]string           // 脚本参数值
+	scriptManager *ScriptManager              // 脚本管理器
+	consoleLogs   []ConsoleEntry              // 最近一次生成时脚本的控制台输出
+	jsonShapes    map[string]config.JSONShape // JSON列的结构，键为列名
 }
 
-// NewGenerator 创建一个新的生成器
-func NewGenerator(dbType string, templateStr string, log *logger.Logger) (*Generator, error) {
-	// 创建类型映射器
-	mapper := NewTypeMapper(dbType)
-
+// NewGenerator 创建一个新的生成器，mapper 决定字段的TypeScript类型
+func NewGenerator(mapper dialect.TypeMapper, templateStr string, log *logger.Logger) (*Generator, error) {
 	// 解析模板
 	tmpl, err := template.New("tsmodel").Parse(templateStr)
 	if err != nil {
 		return nil, err
 	}
 
-	// 创建脚本管理器
-	scriptManager := NewScriptManager(log, "scripts/imported")
-
 	return &Generator{
-		mapper:        mapper,
-		template:      tmpl,
-		log:           log,
-		scriptManager: scriptManager,
+		mapper:   mapper,
+		template: tmpl,
+		log:      log,
 	}, nil
 }
 
@@ -57,13 +62,28 @@ func (g *Generator) SetScript(script string) {
 	g.script = script
 }
 
-// SetScriptFromFile 从文件设置JavaScript处理脚本
-func (g *Generator) SetScriptFromFile(filename string) error {
+// SetScriptParams 设置脚本参数值，未设置的参数使用脚本声明的默认值
+func (g *Generator) SetScriptParams(values map[string]string) {
+	g.scriptParams = values
+}
+
+// ConsoleLogs 返回最近一次生成时脚本的控制台输出
+func (g *Generator) ConsoleLogs() []ConsoleEntry {
+	return g.consoleLogs
+}
+
+// SetScriptManager 设置用于按名称加载脚本的脚本管理器
+func (g *Generator) SetScriptManager(scriptManager *ScriptManager) {
+	g.scriptManager = scriptManager
+}
+
+// SetScriptFromLibrary 从资源库按名称设置JavaScript处理脚本
+func (g *Generator) SetScriptFromLibrary(name string) error {
 	if g.scriptManager == nil {
 		return fmt.Errorf("脚本管理器未初始化")
 	}
 
-	script, err := g.scriptManager.LoadScriptFromFile(filename)
+	script, err := g.scriptManager.LoadScript(name)
 	if err != nil {
 		return err
 	}
@@ -81,51 +101,121 @@ func (g *Generator) GetScriptContent() (string, error) {
 }
 
 // Generate 生成TypeScript模型
+// 脚本定义了生命周期钩子时按钩子流程生成，否则在模板渲染后运行整个脚本
 func (g *Generator) Generate(metadata *connector.TableMetadata) (string, error) {
-	// 准备模板数据
-	data := TemplateData{
-		TableName: metadata.Name,
-		Fields:    make([]FieldData, 0, len(metadata.Fields)),
+	g.consoleLogs = nil
+
+	var processor *JavaScriptProcessor
+	hooks := map[string]bool{}
+	if g.script != "" {
+		var err error
+		if processor, err = g.newScriptProcessor(); err != nil {
+			return "", err
+		}
+		if hooks, err = DetectScriptHooks(g.script); err != nil {
+			return "", err
+		}
 	}
 
-	// 转换字段数据
-	for _, field := range metadata.Fields {
-		data.Fields = append(data.Fields, FieldData{
-			Name:    field.Name,
-			TsType:  g.mapper.Map(field.Type),
-			Comment: field.Comment,
-		})
+	if len(hooks) > 0 {
+		code, err := g.generateWithHooks(metadata, processor, hooks)
+		g.consoleLogs = processor.Logs()
+		if err != nil {
+			g.log.Warnf("JavaScript钩子处理失败: %v", err)
+			return "", err
+		}
+		return code, nil
 	}
 
+	data, columns := g.templateData(metadata)
+
 	// 执行模板
 	var buf bytes.Buffer
 	if err := g.template.Execute(&buf, data); err != nil {
 		return "", err
 	}
 
-	tsCode := buf.String()
+	tsCode := g.withJSONShapes(buf.String(), columns)
 
 	// 如果有JavaScript脚本，进行处理
-	if g.script != "" {
-		processor := NewJavaScriptProcessor(g.log)
+	if processor != nil {
 		// 将表结构数据和生成的TypeScript代码传递给处理器
 		processedCode, err := processor.Process(tsCode, g.script, data)
+		g.consoleLogs = processor.Logs()
 		if err != nil {
 			g.log.Warnf("JavaScript处理失败: %v", err)
 			return tsCode, err // 返回错误，让调用者处理
 		}
-		return processedCode, nil
+		// 脚本重新生成了代码时补上JSON结构的类型声明
+		return g.withJSONShapes(processedCode, columns), nil
 	}
 
 	return tsCode, nil
 }
 
+// templateData 根据表元数据准备模板数据，返回数据和字段的列名
+// 脚本的 input 也是这份数据，定义钩子时顶层代码读取的 input 与不定义钩子时相同
+func (g *Generator) templateData(metadata *connector.TableMetadata) (TemplateData, []string) {
+	data := TemplateData{
+		TableName:  metadata.Name,
+		Kind:       string(metadata.Kind),
+		ReadOnly:   metadata.Kind.ReadOnly(),
+		Definition: metadata.Definition,
+		Fields:     make([]FieldData, 0, len(metadata.Fields)),
+	}
+
+	// 转换字段数据，保存了JSON结构的列使用结构的类型
+	columns := make([]string, 0, len(metadata.Fields))
+	for _, field := range metadata.Fields {
+		tsType, ok := g.jsonShapeType(field.Name)
+		if !ok {
+			tsType = g.mapper.Map(field.Type)
+		}
+		data.Fields = append(data.Fields, FieldData{
+			Name:     field.Name,
+			TsType:   tsType,
+			Nullable: field.IsNullable,
+			Comment:  field.Comment,
+		})
+		columns = append(columns, field.Name)
+	}
+
+	// 转换查询的参数
+	for _, param := range metadata.Params {
+		data.Params = append(data.Params, FieldData{
+			Name:     param.Name,
+			TsType:   g.mapper.Map(param.Type),
+			Nullable: param.IsNullable,
+		})
+	}
+
+	return data, columns
+}
+
+// newScriptProcessor 创建脚本处理器并设置脚本参数
+func (g *Generator) newScriptProcessor() (*JavaScriptProcessor, error) {
+	processor := NewJavaScriptProcessor(g.log)
+
+	// 解析脚本声明的参数
+	params, err := ParseScriptParams(g.script)
+	if err != nil {
+		return nil, err
+	}
+	resolved, err := ResolveScriptParams(params, g.scriptParams)
+	if err != nil {
+		return nil, err
+	}
+	processor.SetParams(resolved)
+
+	return processor, nil
+}
+
+// DefaultQueryTemplate 返回查询的默认模板，生成结果接口、参数元组类型和查询语句常量
+func DefaultQueryTemplate() string {
+	return config.DefaultQueryTemplate()
+}
+
 // DefaultTemplate 返回默认的TypeScript模板
 func DefaultTemplate() string {
-	return `export interface {{.TableName}} {
-{{range .Fields}}  /** {{.Comment}} */
-  {{.Name}}: {{.TsType}};
-{{end}}
-}
-`
+	return config.DefaultTemplate()
 }
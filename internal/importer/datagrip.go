@@ -0,0 +1,74 @@
+package importer
+
+import (
+	"encoding/xml"
+	"fmt"
+)
+
+// datagripProject 表示 DataGrip 的 dataSources.xml 和 dataSources.local.xml
+type datagripProject struct {
+	Components []struct {
+		DataSources []datagripDataSource `xml:"data-source"`
+	} `xml:"component"`
+}
+
+// datagripDataSource 表示一个 DataGrip 数据源
+type datagripDataSource struct {
+	Name      string `xml:"name,attr"`
+	UUID      string `xml:"uuid,attr"`
+	DriverRef string `xml:"driver-ref"`
+	JdbcURL   string `xml:"jdbc-url"`
+	UserName  string `xml:"user-name"`
+}
+
+// ParseDataGrip 解析 DataGrip 的 dataSources.xml
+// local 为同目录下 dataSources.local.xml 的内容，其中保存了用户名，可以为空。
+// DataGrip 的密码保存在系统钥匙串中，无法离线读取，因此不会导入
+func ParseDataGrip(data, local []byte) (*Result, error) {
+	var project datagripProject
+	if err := xml.Unmarshal(data, &project); err != nil {
+		return nil, fmt.Errorf("解析 DataGrip 配置失败: %v", err)
+	}
+
+	usernames := make(map[string]string)
+	if len(local) > 0 {
+		var localProject datagripProject
+		if err := xml.Unmarshal(local, &localProject); err == nil {
+			for _, component := range localProject.Components {
+				for _, source := range component.DataSources {
+					usernames[source.UUID] = source.UserName
+				}
+			}
+		}
+	}
+
+	result := &Result{}
+	for _, component := range project.Components {
+		for _, source := range component.DataSources {
+			if !IsConnectionURL(source.JdbcURL) {
+				result.Skipped = append(result.Skipped, Skipped{Name: source.Name, Reason: fmt.Sprintf("不支持的数据库类型: %s", source.DriverRef)})
+				continue
+			}
+
+			conn, err := ParseURL(source.JdbcURL)
+			if err != nil {
+				result.Skipped = append(result.Skipped, Skipped{Name: source.Name, Reason: err.Error()})
+				continue
+			}
+			if source.Name != "" {
+				conn.Name = source.Name
+			}
+			if conn.Username == "" {
+				conn.Username = usernames[source.UUID]
+			}
+
+			candidate := newCandidate(conn, SourceDataGrip, source.UUID)
+			if conn.Password == "" && hasPassword(conn.Type) {
+				candidate.Notes = append(candidate.Notes, "DataGrip 的密码保存在系统钥匙串中，未导入，请手动填写")
+			}
+			result.Candidates = append(result.Candidates, candidate)
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,149 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"go-DBmodeler/internal/config"
+	"sort"
+	"strings"
+)
+
+// dbeaverDataSources 表示 DBeaver 的 data-sources.json
+type dbeaverDataSources struct {
+	Connections map[string]dbeaverConnection `json:"connections"`
+}
+
+// dbeaverConnection 表示 data-sources.json 中的一个连接
+type dbeaverConnection struct {
+	Provider      string `json:"provider"`
+	Driver        string `json:"driver"`
+	Name          string `json:"name"`
+	SavePassword  bool   `json:"save-password"`
+	Configuration struct {
+		Host     string `json:"host"`
+		Port     string `json:"port"`
+		Database string `json:"database"`
+		URL      string `json:"url"`
+		User     string `json:"user"`
+		Password string `json:"password"`
+	} `json:"configuration"`
+}
+
+// dbeaverProvider 将 DBeaver 的 provider 名称映射到数据库类型
+type dbeaverProvider struct {
+	name   string // provider 名称，也是 JDBC URL 的协议名
+	dbType string
+}
+
+// dbeaverProviders 是支持的 provider，按顺序匹配
+var dbeaverProviders = []dbeaverProvider{
+	{"mysql", "MySQL"},
+	{"mariadb", "MySQL"},
+	{"postgresql", "PostgreSQL"},
+	{"sqlite", "SQLite"},
+	{"sqlserver", "SQL Server"},
+}
+
+// ParseDBeaver 解析 DBeaver 的 data-sources.json
+// 新版本 DBeaver 将用户名和密码加密保存在 credentials-config.json 中，这些密码不会导入
+func ParseDBeaver(data []byte) (*Result, error) {
+	var sources dbeaverDataSources
+	if err := json.Unmarshal(data, &sources); err != nil {
+		return nil, fmt.Errorf("解析 DBeaver 配置失败: %v", err)
+	}
+
+	ids := make([]string, 0, len(sources.Connections))
+	for id := range sources.Connections {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	result := &Result{}
+	for _, id := range ids {
+		source := sources.Connections[id]
+		name := source.Name
+		if name == "" {
+			name = id
+		}
+
+		dbType := dbeaverDatabaseType(source)
+		if dbType == "" {
+			result.Skipped = append(result.Skipped, Skipped{Name: name, Reason: fmt.Sprintf("不支持的数据库类型: %s", source.Provider)})
+			continue
+		}
+
+		cfg := source.Configuration
+		conn := config.ConnectionConfig{
+			Name:     name,
+			Type:     dbType,
+			Host:     cfg.Host,
+			Port:     cfg.Port,
+			Username: cfg.User,
+			Password: cfg.Password,
+			Database: cfg.Database,
+		}
+
+		// 只配置了URL的连接从URL中解析主机和数据库
+		if conn.Host == "" && cfg.URL != "" && IsConnectionURL(cfg.URL) {
+			if parsed, err := ParseURL(cfg.URL); err == nil {
+				parsed.Name = name
+				if conn.Username != "" {
+					parsed.Username = conn.Username
+				}
+				if conn.Password != "" {
+					parsed.Password = conn.Password
+				}
+				conn = parsed
+			}
+		}
+		// DBeaver 中 SQLite 的文件路径保存在 database 字段
+		if dbType == "SQLite" && conn.Host == "" {
+			conn.Host = cfg.Database
+			conn.Database = "main"
+		}
+		if conn.Host == "" {
+			result.Skipped = append(result.Skipped, Skipped{Name: name, Reason: "缺少主机或文件路径"})
+			continue
+		}
+
+		candidate := newCandidate(conn, SourceDBeaver, id)
+		if conn.Password == "" && source.SavePassword {
+			candidate.Notes = append(candidate.Notes, "密码保存在 DBeaver 的加密凭据文件中，未导入，请手动填写")
+		}
+		if conn.Username == "" && source.SavePassword {
+			candidate.Notes = append(candidate.Notes, "用户名保存在 DBeaver 的加密凭据文件中，未导入")
+		}
+		result.Candidates = append(result.Candidates, candidate)
+	}
+
+	return result, nil
+}
+
+// dbeaverDatabaseType 根据 provider、JDBC URL 和 driver 判断数据库类型
+// 通用 JDBC 驱动（例如 SQLite 使用 provider "generic"）按 URL 的协议 jdbc:<名称>: 判断，
+// 没有 URL 时按 driver 名称的前缀判断，例如 sqlite_jdbc
+func dbeaverDatabaseType(source dbeaverConnection) string {
+	provider := strings.ToLower(source.Provider)
+	url := strings.ToLower(strings.TrimSpace(source.Configuration.URL))
+	driver := strings.ToLower(source.Driver)
+
+	for _, p := range dbeaverProviders {
+		if provider == p.name {
+			return p.dbType
+		}
+	}
+	for _, p := range dbeaverProviders {
+		if strings.HasPrefix(url, "jdbc:"+p.name+":") {
+			return p.dbType
+		}
+	}
+	if url != "" {
+		return ""
+	}
+	for _, p := range dbeaverProviders {
+		if strings.HasPrefix(driver, p.name) {
+			return p.dbType
+		}
+	}
+	return ""
+}
@@ -0,0 +1,141 @@
+// Package importer 从其他数据库工具的配置文件和连接URL中导入连接配置
+// 所有解析都在本地离线完成，不会连接数据库
+package importer
+
+import (
+	"fmt"
+	"go-DBmodeler/internal/config"
+	"go-DBmodeler/internal/db/dialect"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Source 表示导入来源
+type Source string
+
+const (
+	SourceDBeaver  Source = "DBeaver"
+	SourceDataGrip Source = "DataGrip"
+	SourceURL      Source = "URL"
+	SourceShared   Source = "团队共享文件"
+)
+
+// Candidate 表示一个可导入的连接
+type Candidate struct {
+	Config config.ConnectionConfig
+	Source Source
+	Origin string   // 来源位置，例如文件中的连接ID或环境变量名
+	Notes  []string // 导入时需要提示的信息，例如密码无法导入
+}
+
+// Skipped 表示来源中无法导入的条目
+type Skipped struct {
+	Name   string
+	Reason string
+}
+
+// Result 表示一次解析的结果
+type Result struct {
+	Candidates []Candidate
+	Skipped    []Skipped
+}
+
+// ParseFile 根据文件名和内容自动选择解析器
+// 导出的共享连接文件和 DBeaver 的 data-sources.json 按内容区分，dataSources.xml 按 DataGrip 解析，其他文件按 .env 解析
+func ParseFile(path string) (*Result, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取文件失败: %v", err)
+	}
+
+	name := strings.ToLower(filepath.Base(path))
+	switch {
+	case strings.HasSuffix(name, ".json"):
+		if connections, ok, err := config.ParseSharedConnections(data); ok {
+			if err != nil {
+				return nil, err
+			}
+			return parseShared(connections), nil
+		}
+		return ParseDBeaver(data)
+	case strings.HasSuffix(name, ".xml"):
+		// DataGrip 将用户名保存在同目录的 dataSources.local.xml 中
+		local, _ := os.ReadFile(filepath.Join(filepath.Dir(path), "dataSources.local.xml"))
+		return ParseDataGrip(data, local)
+	default:
+		return ParseEnv(data), nil
+	}
+}
+
+// ParseInput 解析用户输入：连接URL直接解析，否则作为文件路径解析
+func ParseInput(input string) (*Result, error) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return nil, fmt.Errorf("请输入文件路径或连接URL")
+	}
+	if IsConnectionURL(input) {
+		conn, err := ParseURL(input)
+		if err != nil {
+			return nil, err
+		}
+		return &Result{Candidates: []Candidate{newCandidate(conn, SourceURL, "输入的URL")}}, nil
+	}
+	return ParseFile(input)
+}
+
+// DefaultPaths 返回当前系统上存在的 DBeaver 配置文件默认位置
+func DefaultPaths() []string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil
+	}
+
+	candidates := []string{
+		// Linux
+		filepath.Join(home, ".local", "share", "DBeaverData", "workspace6", "General", ".dbeaver", "data-sources.json"),
+		// macOS
+		filepath.Join(home, "Library", "DBeaverData", "workspace6", "General", ".dbeaver", "data-sources.json"),
+	}
+	if appData := os.Getenv("APPDATA"); appData != "" {
+		// Windows
+		candidates = append(candidates, filepath.Join(appData, "DBeaverData", "workspace6", "General", ".dbeaver", "data-sources.json"))
+	}
+
+	var paths []string
+	for _, path := range candidates {
+		if _, err := os.Stat(path); err == nil {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// parseShared 将共享连接文件中的连接转换为候选连接
+func parseShared(connections []config.ConnectionConfig) *Result {
+	result := &Result{}
+	for _, conn := range connections {
+		candidate := Candidate{Config: conn, Source: SourceShared, Origin: conn.Name}
+		if config.IsReference(conn.Password) {
+			candidate.Notes = append(candidate.Notes, "密码从 "+conn.Password+" 读取，覆盖现有连接时保留本地保存的密码")
+		} else if hasPassword(conn.Type) {
+			candidate.Notes = append(candidate.Notes, "共享文件不包含密码，覆盖现有连接时保留本地保存的密码")
+		}
+		result.Candidates = append(result.Candidates, candidate)
+	}
+	return result
+}
+
+// newCandidate 创建候选连接，补全默认端口
+func newCandidate(conn config.ConnectionConfig, source Source, origin string) Candidate {
+	if d, err := dialect.Lookup(conn.Type); err == nil && conn.Port == "" {
+		conn.Port = d.DefaultPort
+	}
+	return Candidate{Config: conn, Source: source, Origin: origin}
+}
+
+// hasPassword 判断数据库类型的连接是否使用密码
+func hasPassword(dbType string) bool {
+	d, err := dialect.Lookup(dbType)
+	return err == nil && d.HasField(dialect.FieldPassword)
+}
@@ -0,0 +1,421 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-DBmodeler/pkg/logger"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tetratelabs/wazero"
+)
+
+const (
+	// DefaultTimeout 是生成请求的默认超时时间
+	DefaultTimeout = 30 * time.Second
+	// HandshakeTimeout 是握手请求的超时时间
+	HandshakeTimeout = 5 * time.Second
+	// maxStderrSize 是保留的标准错误输出的最大字节数
+	maxStderrSize = 64 * 1024
+)
+
+// 插件类型
+const (
+	KindProcess = "process" // 可执行文件，通过标准输入输出通信
+	KindWasm    = "wasm"    // WebAssembly模块，在内置的沙箱中运行
+)
+
+// Plugin 表示插件目录中通过握手的外部生成器
+type Plugin struct {
+	Name        string // 插件名称，即文件名（不含扩展名）
+	Path        string // 可执行文件或 .wasm 模块路径
+	Kind        string // 插件类型：process 或 wasm
+	DisplayName string // 插件在握手时报告的名称
+	Description string // 插件描述
+	Version     string // 插件版本
+}
+
+// Result 表示一次插件运行的结果
+type Result struct {
+	Files  []File
+	Stderr string // 插件写入标准错误的内容
+}
+
+// Error 表示插件运行失败，包含插件的标准错误输出
+type Error struct {
+	Plugin  string
+	Message string
+	Stderr  string
+}
+
+// Error 实现error接口
+func (e *Error) Error() string {
+	if e.Stderr != "" {
+		return fmt.Sprintf("插件 %s 运行失败: %s\n%s", e.Plugin, e.Message, strings.TrimRight(e.Stderr, "\n"))
+	}
+	return fmt.Sprintf("插件 %s 运行失败: %s", e.Plugin, e.Message)
+}
+
+// Manager 管理插件目录中的外部生成器
+//
+// 插件协议：
+//   - 每次调用都会启动插件进程，向标准输入写入一个 JSON 请求，然后关闭标准输入
+//   - 插件向标准输出写入一个 JSON 响应后退出，日志等信息应写入标准错误
+//   - 握手请求的 type 为 "handshake"，插件返回 protocolVersion、name、description、version
+//   - 生成请求的 type 为 "generate"，插件返回 files 列表，出错时返回 error 或以非零状态退出
+//
+// .wasm 模块使用同样的JSON请求和响应，通过内存ABI传递，见 callWasm
+//
+// 握手结果按文件缓存，文件没有变化时 Discover 和 Find 不会再次启动插件，Refresh 清空缓存后重新握手
+type Manager struct {
+	log        *logger.Logger
+	dir        string
+	timeout    time.Duration
+	wasmLimits WasmLimits
+	wasmCache  wazero.CompilationCache
+
+	mu         sync.Mutex
+	handshakes map[string]*handshakeResult // 键为插件文件路径
+}
+
+// handshakeResult 是缓存的一个插件文件的握手结果，文件的修改时间或大小变化时失效
+type handshakeResult struct {
+	modTime time.Time
+	size    int64
+	plugin  *Plugin
+	err     error
+}
+
+// NewManager 创建一个新的插件管理器
+func NewManager(log *logger.Logger, dir string) *Manager {
+	return &Manager{
+		log:        log,
+		dir:        dir,
+		timeout:    DefaultTimeout,
+		wasmLimits: WasmLimits{MemoryPages: DefaultWasmMemoryPages, MaxCalls: DefaultWasmMaxCalls},
+		wasmCache:  wazero.NewCompilationCache(),
+		handshakes: make(map[string]*handshakeResult),
+	}
+}
+
+// SetTimeout 设置生成请求的超时时间
+func (m *Manager) SetTimeout(timeout time.Duration) {
+	if timeout > 0 {
+		m.timeout = timeout
+	}
+}
+
+// SetWasmLimits 设置WASM插件的内存和函数调用次数限制
+func (m *Manager) SetWasmLimits(limits WasmLimits) {
+	if limits.MemoryPages == 0 {
+		limits.MemoryPages = DefaultWasmMemoryPages
+	}
+	m.wasmLimits = limits
+}
+
+// Dir 返回插件目录
+func (m *Manager) Dir() string {
+	return m.dir
+}
+
+// Discover 扫描插件目录并与每个可执行文件和 .wasm 模块握手，握手失败的插件会被跳过并记录警告
+// 上次握手后没有变化的文件使用缓存的结果；握手并行进行且不持有锁，慢的插件不会阻塞其他调用
+func (m *Manager) Discover() ([]*Plugin, error) {
+	if err := os.MkdirAll(m.dir, 0755); err != nil {
+		return nil, fmt.Errorf("创建插件目录失败: %v", err)
+	}
+
+	entries, err := os.ReadDir(m.dir)
+	if err != nil {
+		return nil, fmt.Errorf("读取插件目录失败: %v", err)
+	}
+
+	// 读取文件信息，与缓存比较找出需要握手的文件
+	results := make(map[string]*handshakeResult)
+	var stale []string
+	m.mu.Lock()
+	for _, entry := range entries {
+		path := filepath.Join(m.dir, entry.Name())
+		if !isWasmModule(path) && !isExecutable(path) {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+
+		cached := m.handshakes[path]
+		if cached == nil || !cached.modTime.Equal(info.ModTime()) || cached.size != info.Size() {
+			cached = &handshakeResult{modTime: info.ModTime(), size: info.Size()}
+			stale = append(stale, path)
+		}
+		results[path] = cached
+	}
+	// 删除已经移除的插件的缓存
+	for path := range m.handshakes {
+		if results[path] == nil {
+			delete(m.handshakes, path)
+		}
+	}
+	m.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, path := range stale {
+		wg.Add(1)
+		go func(result *handshakeResult, path string) {
+			defer wg.Done()
+			result.plugin, result.err = m.Handshake(path)
+			if result.err != nil {
+				m.log.Warnf("跳过插件 %s: %v", filepath.Base(path), result.err)
+			}
+		}(results[path], path)
+	}
+	wg.Wait()
+
+	m.mu.Lock()
+	for _, path := range stale {
+		m.handshakes[path] = results[path]
+	}
+	m.mu.Unlock()
+
+	var plugins []*Plugin
+	for _, result := range results {
+		if result.err == nil {
+			plugins = append(plugins, result.plugin)
+		}
+	}
+	// 同名的插件按路径排序，结果不受 map 遍历顺序影响
+	sort.Slice(plugins, func(i, j int) bool {
+		if plugins[i].Name != plugins[j].Name {
+			return plugins[i].Name < plugins[j].Name
+		}
+		return plugins[i].Path < plugins[j].Path
+	})
+	return plugins, nil
+}
+
+// Refresh 清空握手缓存，重新与插件目录中的所有插件握手
+func (m *Manager) Refresh() ([]*Plugin, error) {
+	m.mu.Lock()
+	m.handshakes = make(map[string]*handshakeResult)
+	m.mu.Unlock()
+	return m.Discover()
+}
+
+// Find 按名称查找插件
+func (m *Manager) Find(name string) (*Plugin, error) {
+	plugins, err := m.Discover()
+	if err != nil {
+		return nil, err
+	}
+	for _, plugin := range plugins {
+		if plugin.Name == name {
+			return plugin, nil
+		}
+	}
+	return nil, fmt.Errorf("插件 '%s' 不存在或握手失败，插件目录: %s", name, m.dir)
+}
+
+// Handshake 与插件握手，检查插件支持的协议版本
+func (m *Manager) Handshake(path string) (*Plugin, error) {
+	plugin := &Plugin{
+		Name: pluginName(path),
+		Path: path,
+		Kind: KindProcess,
+	}
+	if isWasmModule(path) {
+		plugin.Kind = KindWasm
+	}
+
+	request := &Request{Type: RequestHandshake, ProtocolVersion: ProtocolVersion}
+	response, _, err := m.call(plugin, request, HandshakeTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	plugin.DisplayName = response.Name
+	plugin.Description = response.Description
+	plugin.Version = response.Version
+	return plugin, nil
+}
+
+// Generate 运行插件生成代码
+func (m *Manager) Generate(plugin *Plugin, request *Request) (*Result, error) {
+	request.Type = RequestGenerate
+	request.ProtocolVersion = ProtocolVersion
+
+	response, stderr, err := m.call(plugin, request, m.timeout)
+	if stderr != "" {
+		m.log.Infof("插件 %s 标准错误输出:\n%s", plugin.Name, stderr)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, file := range response.Files {
+		if err := validateFileName(file.Name); err != nil {
+			return nil, &Error{Plugin: plugin.Name, Message: err.Error(), Stderr: stderr}
+		}
+	}
+
+	return &Result{Files: response.Files, Stderr: stderr}, nil
+}
+
+// call 向插件发送请求并读取响应
+func (m *Manager) call(plugin *Plugin, request *Request, timeout time.Duration) (*Response, string, error) {
+	if plugin.Kind == KindWasm {
+		return m.callWasm(plugin, request, timeout)
+	}
+	return m.callProcess(plugin, request, timeout)
+}
+
+// callProcess 启动插件进程，发送请求并读取响应
+func (m *Manager) callProcess(plugin *Plugin, request *Request, timeout time.Duration) (*Response, string, error) {
+	input, err := json.Marshal(request)
+	if err != nil {
+		return nil, "", fmt.Errorf("JSON序列化错误: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var stdout bytes.Buffer
+	stderr := &limitedBuffer{limit: maxStderrSize}
+	cmd := exec.CommandContext(ctx, plugin.Path)
+	cmd.Dir = filepath.Dir(plugin.Path)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = stderr
+	cmd.WaitDelay = time.Second // 插件的子进程未退出时不会无限等待输出
+
+	runErr := cmd.Run()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return nil, stderr.String(), &Error{Plugin: plugin.Name, Message: fmt.Sprintf("超时（%s）", timeout), Stderr: stderr.String()}
+	}
+	if runErr != nil {
+		return nil, stderr.String(), &Error{Plugin: plugin.Name, Message: runErr.Error(), Stderr: stderr.String()}
+	}
+
+	response, err := decodeResponse(stdout.Bytes())
+	if err != nil {
+		return nil, stderr.String(), &Error{Plugin: plugin.Name, Message: err.Error(), Stderr: stderr.String()}
+	}
+
+	return response, stderr.String(), nil
+}
+
+// decodeResponse 解析插件响应并检查协议版本和插件报告的错误
+func decodeResponse(data []byte) (*Response, error) {
+	var response Response
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, fmt.Errorf("无法解析插件输出: %v", err)
+	}
+	if response.ProtocolVersion != ProtocolVersion {
+		return nil, fmt.Errorf("协议版本不兼容: 插件为 %d，需要 %d", response.ProtocolVersion, ProtocolVersion)
+	}
+	if response.Error != "" {
+		return nil, errors.New(response.Error)
+	}
+	return &response, nil
+}
+
+// WriteFiles 将插件生成的文件写入输出目录，返回写入的文件路径
+func WriteFiles(dir string, files []File) ([]string, error) {
+	paths := make([]string, 0, len(files))
+	for _, file := range files {
+		if err := validateFileName(file.Name); err != nil {
+			return paths, err
+		}
+
+		path := filepath.Join(dir, filepath.FromSlash(file.Name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return paths, fmt.Errorf("创建目录失败: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(file.Content), 0644); err != nil {
+			return paths, fmt.Errorf("写入文件失败: %v", err)
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// validateFileName 检查插件返回的文件名，防止写到输出目录之外
+func validateFileName(name string) error {
+	if name == "" {
+		return fmt.Errorf("文件名不能为空")
+	}
+	if !filepath.IsLocal(filepath.FromSlash(name)) {
+		return fmt.Errorf("无效的文件名: %s", name)
+	}
+	return nil
+}
+
+// pluginName 返回插件名称，即不含扩展名的文件名
+func pluginName(path string) string {
+	base := filepath.Base(path)
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
+
+// isWasmModule 判断文件是否为WASM模块
+func isWasmModule(path string) bool {
+	if strings.ToLower(filepath.Ext(path)) != ".wasm" {
+		return false
+	}
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
+
+// isExecutable 判断文件是否为可执行文件
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".exe", ".bat", ".cmd":
+			return true
+		}
+		return false
+	}
+	return info.Mode()&0111 != 0
+}
+
+// limitedBuffer 只保留前 limit 个字节的缓冲区，超出部分丢弃
+type limitedBuffer struct {
+	buf       bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+// Write 实现io.Writer接口
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if remaining := b.limit - b.buf.Len(); remaining > 0 {
+		if len(p) > remaining {
+			b.buf.Write(p[:remaining])
+			b.truncated = true
+		} else {
+			b.buf.Write(p)
+		}
+	} else if len(p) > 0 {
+		b.truncated = true
+	}
+	return len(p), nil
+}
+
+// String 返回缓冲区内容
+func (b *limitedBuffer) String() string {
+	if b.truncated {
+		return b.buf.String() + "\n...（输出过长，已截断）"
+	}
+	return b.buf.String()
+}
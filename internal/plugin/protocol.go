@@ -0,0 +1,197 @@
+package plugin
+
+import (
+	"go-DBmodeler/internal/db/connector"
+	"go-DBmodeler/internal/db/dialect"
+)
+
+// ProtocolVersion 是当前插件协议版本，插件在握手时必须返回相同的主版本
+const ProtocolVersion = 1
+
+// 请求类型
+const (
+	// RequestHandshake 握手请求，插件返回自身信息和支持的协议版本
+	RequestHandshake = "handshake"
+	// RequestGenerate 生成请求，插件返回生成的文件列表
+	RequestGenerate = "generate"
+)
+
+// 生成请求的输入类型，即 Request.Kind
+const (
+	// InputTable 为 Generate 中的表生成代码，Tables 为数据库中所有表的结构
+	InputTable = "table"
+	// InputQuery 为已保存的查询生成代码，Tables 中只有查询的结果结构，Kind 为 query
+	InputQuery = "query"
+	// InputRoutine 为存储过程和函数生成代码，Routines 为选中的例程，Generate 为它们的签名
+	InputRoutine = "routine"
+)
+
+// Request 表示写入插件标准输入的请求
+type Request struct {
+	Type            string            `json:"type"`
+	ProtocolVersion int               `json:"protocolVersion"`
+	Kind            string            `json:"kind,omitempty"` // 生成请求的输入类型，取值为 Input* 常量之一
+	DatabaseType    string            `json:"databaseType,omitempty"`
+	Database        string            `json:"database,omitempty"`
+	Tables          []Table           `json:"tables,omitempty"`   // 数据库中所有表的完整结构，或查询的结果结构
+	Routines        []Routine         `json:"routines,omitempty"` // 需要生成代码的存储过程和函数
+	Generate        []string          `json:"generate,omitempty"` // 需要生成代码的表名、查询名称或例程签名
+	Params          map[string]string `json:"params,omitempty"`   // 用户传递给插件的参数
+}
+
+// Response 表示插件从标准输出返回的响应
+type Response struct {
+	ProtocolVersion int    `json:"protocolVersion"`
+	Name            string `json:"name,omitempty"`
+	Description     string `json:"description,omitempty"`
+	Version         string `json:"version,omitempty"`
+	Files           []File `json:"files,omitempty"`
+	Error           string `json:"error,omitempty"` // 插件自身报告的错误
+}
+
+// File 表示插件生成的一个文件
+type File struct {
+	Name    string `json:"name"` // 相对于输出目录的路径
+	Content string `json:"content"`
+}
+
+// Table 表示传递给插件的表结构
+type Table struct {
+	Name        string       `json:"name"`
+	Schema      string       `json:"schema,omitempty"`
+	Kind        string       `json:"kind"`                 // 对象类型：table、view、materialized view、foreign table 或 partitioned table
+	Definition  string       `json:"definition,omitempty"` // 视图的定义或查询语句
+	Fields      []Field      `json:"fields"`
+	Params      []Field      `json:"params,omitempty"` // 查询的参数，按位置排列
+	Indexes     []Index      `json:"indexes"`
+	ForeignKeys []ForeignKey `json:"foreignKeys,omitempty"`
+}
+
+// Field 表示传递给插件的字段信息，TsType 为内置类型映射的结果
+type Field struct {
+	Name       string `json:"name"`
+	Type       string `json:"type"`
+	TsType     string `json:"tsType"`
+	Length     int    `json:"length"`
+	IsNullable bool   `json:"isNullable"`
+	IsPrimary  bool   `json:"isPrimary"`
+	IsUnique   bool   `json:"isUnique"`
+	IsIdentity bool   `json:"isIdentity,omitempty"`
+	Default    string `json:"default"`
+	Comment    string `json:"comment"`
+}
+
+// Index 表示传递给插件的索引信息
+type Index struct {
+	Name    string   `json:"name"`
+	Type    string   `json:"type"`
+	Columns []string `json:"columns"`
+}
+
+// ForeignKey 表示传递给插件的外键信息
+type ForeignKey struct {
+	Name       string   `json:"name"`
+	Columns    []string `json:"columns"`
+	RefTable   string   `json:"refTable"`
+	RefColumns []string `json:"refColumns"`
+}
+
+// Routine 表示传递给插件的存储过程或函数
+type Routine struct {
+	Name         string         `json:"name"`
+	Schema       string         `json:"schema,omitempty"`
+	Signature    string         `json:"signature"`
+	Kind         string         `json:"kind"` // function 或 procedure
+	Comment      string         `json:"comment"`
+	Params       []RoutineParam `json:"params"`
+	ReturnType   string         `json:"returnType,omitempty"` // 返回单个值时的数据库类型
+	ReturnTsType string         `json:"returnTsType,omitempty"`
+	ReturnsSet   bool           `json:"returnsSet"`
+	Columns      []RoutineParam `json:"columns,omitempty"` // 返回的列
+	CallSQL      string         `json:"callSQL"`
+	CallArgs     []string       `json:"callArgs"`
+}
+
+// RoutineParam 表示传递给插件的例程参数或返回的列
+type RoutineParam struct {
+	Name   string `json:"name"`
+	Mode   string `json:"mode,omitempty"` // IN、OUT、INOUT 或 VARIADIC，返回的列为空
+	Type   string `json:"type"`
+	TsType string `json:"tsType"`
+}
+
+// NewTable 根据表元数据创建插件使用的表结构
+func NewTable(metadata *connector.TableMetadata, mapper dialect.TypeMapper) Table {
+	table := Table{
+		Name:       metadata.Name,
+		Schema:     metadata.Schema,
+		Kind:       string(metadata.Kind),
+		Definition: metadata.Definition,
+		Fields:     make([]Field, 0, len(metadata.Fields)),
+		Indexes:    make([]Index, 0, len(metadata.Indexes)),
+	}
+	for _, field := range metadata.Fields {
+		table.Fields = append(table.Fields, Field{
+			Name:       field.Name,
+			Type:       field.Type,
+			TsType:     mapper.Map(field.Type),
+			Length:     field.Length,
+			IsNullable: field.IsNullable,
+			IsPrimary:  field.IsPrimary,
+			IsUnique:   field.IsUnique,
+			IsIdentity: field.IsIdentity,
+			Default:    field.Default,
+			Comment:    field.Comment,
+		})
+	}
+	for _, param := range metadata.Params {
+		table.Params = append(table.Params, Field{
+			Name:       param.Name,
+			Type:       param.Type,
+			TsType:     mapper.Map(param.Type),
+			IsNullable: param.IsNullable,
+		})
+	}
+	for _, index := range metadata.Indexes {
+		table.Indexes = append(table.Indexes, Index{
+			Name:    index.Name,
+			Type:    index.Type,
+			Columns: index.Columns,
+		})
+	}
+	for _, key := range metadata.ForeignKeys {
+		table.ForeignKeys = append(table.ForeignKeys, ForeignKey{
+			Name:       key.Name,
+			Columns:    key.Columns,
+			RefTable:   key.RefTable,
+			RefColumns: key.RefColumns,
+		})
+	}
+	return table
+}
+
+// NewRoutine 根据例程创建插件使用的例程结构
+func NewRoutine(routine *connector.Routine, mapper dialect.TypeMapper) Routine {
+	result := Routine{
+		Name:       routine.Name,
+		Schema:     routine.Schema,
+		Signature:  routine.Signature,
+		Kind:       string(routine.Kind),
+		Comment:    routine.Comment,
+		Params:     make([]RoutineParam, 0, len(routine.Params)),
+		ReturnType: routine.ReturnType,
+		ReturnsSet: routine.ReturnsSet,
+		CallSQL:    routine.CallSQL,
+		CallArgs:   routine.CallArgs,
+	}
+	if routine.ReturnType != "" {
+		result.ReturnTsType = mapper.Map(routine.ReturnType)
+	}
+	for _, param := range routine.Params {
+		result.Params = append(result.Params, RoutineParam{Name: param.Name, Mode: param.Mode, Type: param.Type, TsType: mapper.Map(param.Type)})
+	}
+	for _, column := range routine.Columns {
+		result.Columns = append(result.Columns, RoutineParam{Name: column.Name, Type: column.Type, TsType: mapper.Map(column.Type)})
+	}
+	return result
+}
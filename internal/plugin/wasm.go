@@ -0,0 +1,183 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/experimental"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+	"github.com/tetratelabs/wazero/sys"
+)
+
+const (
+	// DefaultWasmMemoryPages 是WASM插件默认可使用的最大内存页数（每页64KiB，共64MiB）
+	DefaultWasmMemoryPages = 1024
+	// DefaultWasmMaxCalls 是WASM插件每次调用默认允许的最大函数调用次数
+	DefaultWasmMaxCalls = 10_000_000
+
+	// wasmHostModule 是宿主提供给插件的导入模块名
+	wasmHostModule = "godbmodeler"
+)
+
+// errCallLimitExceeded 表示插件超过了允许的函数调用次数
+var errCallLimitExceeded = errors.New("函数调用次数超过上限")
+
+// callMeter 记录一次调用中插件的函数调用次数，超过上限时取消调用的上下文
+// 只计算函数调用，不计算指令，函数内部的循环只能由超时终止
+type callMeter struct {
+	limit     uint64
+	used      atomic.Uint64
+	exhausted atomic.Bool
+	cancel    context.CancelFunc
+}
+
+// callMeterKey 是上下文中保存 callMeter 的键
+type callMeterKey struct{}
+
+// callListenerFactory 为每个函数创建计算调用次数的监听器
+// 监听器在编译时创建并随编译缓存复用，因此计数器必须从每次调用的上下文中获取
+var callListenerFactory = experimental.FunctionListenerFactoryFunc(func(api.FunctionDefinition) experimental.FunctionListener {
+	return experimental.FunctionListenerFunc(func(ctx context.Context, _ api.Module, _ api.FunctionDefinition, _ []uint64, _ experimental.StackIterator) {
+		meter, ok := ctx.Value(callMeterKey{}).(*callMeter)
+		if !ok || meter.limit == 0 {
+			return
+		}
+		if meter.used.Add(1) > meter.limit && meter.exhausted.CompareAndSwap(false, true) {
+			meter.cancel()
+		}
+	})
+})
+
+// WasmLimits 表示WASM插件的资源限制
+type WasmLimits struct {
+	MemoryPages uint32 // 最大内存页数，每页64KiB
+	MaxCalls    uint64 // 每次调用允许的最大函数调用次数，0表示不限制；不限制函数内部的循环，循环只能由超时终止
+}
+
+// callWasm 在沙箱中加载WASM插件并处理一个请求
+//
+// ABI（详见插件开发指南）：
+//   - 插件导出 memory、alloc(size i32) i32 和 handle(ptr i32, len i32) i64
+//   - 宿主调用 alloc 在插件内存中分配空间，写入JSON请求，然后调用 handle
+//   - handle 返回 (响应指针 << 32) | 响应长度，响应为与进程插件相同的JSON
+//   - 插件可以导入 godbmodeler.log(ptr i32, len i32) 输出日志，以及 WASI preview1（无文件系统和网络）
+//   - 如果插件导出 _initialize，会在处理请求前调用
+func (m *Manager) callWasm(plugin *Plugin, request *Request, timeout time.Duration) (*Response, string, error) {
+	input, err := json.Marshal(request)
+	if err != nil {
+		return nil, "", fmt.Errorf("JSON序列化错误: %v", err)
+	}
+
+	code, err := os.ReadFile(plugin.Path)
+	if err != nil {
+		return nil, "", &Error{Plugin: plugin.Name, Message: fmt.Sprintf("读取WASM模块失败: %v", err)}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	// 通过函数调用监听器计算调用次数，超过上限时取消上下文终止执行
+	meter := &callMeter{limit: m.wasmLimits.MaxCalls, cancel: cancel}
+	ctx = context.WithValue(ctx, callMeterKey{}, meter)
+	ctx = experimental.WithFunctionListenerFactory(ctx, callListenerFactory)
+
+	config := wazero.NewRuntimeConfigInterpreter().
+		WithMemoryLimitPages(m.wasmLimits.MemoryPages).
+		WithCloseOnContextDone(true).
+		WithCompilationCache(m.wasmCache)
+	runtime := wazero.NewRuntimeWithConfig(ctx, config)
+	defer runtime.Close(context.Background())
+
+	stderr := &limitedBuffer{limit: maxStderrSize}
+	fail := func(message string) (*Response, string, error) {
+		return nil, stderr.String(), &Error{Plugin: plugin.Name, Message: message, Stderr: stderr.String()}
+	}
+	failErr := func(action string, err error) (*Response, string, error) {
+		switch {
+		case meter.exhausted.Load():
+			return fail(fmt.Sprintf("%s: %v（上限 %d 次函数调用）", action, errCallLimitExceeded, meter.limit))
+		case errors.Is(ctx.Err(), context.DeadlineExceeded):
+			return fail(fmt.Sprintf("%s: 超时（%s）", action, timeout))
+		}
+		var exitErr *sys.ExitError
+		if errors.As(err, &exitErr) {
+			return fail(fmt.Sprintf("%s: 插件退出，状态码 %d", action, exitErr.ExitCode()))
+		}
+		return fail(fmt.Sprintf("%s: %v", action, err))
+	}
+
+	// 宿主函数和WASI
+	if _, err := wasi_snapshot_preview1.Instantiate(ctx, runtime); err != nil {
+		return failErr("初始化WASI失败", err)
+	}
+	_, err = runtime.NewHostModuleBuilder(wasmHostModule).
+		NewFunctionBuilder().
+		WithFunc(func(_ context.Context, mod api.Module, ptr, length uint32) {
+			if data, ok := mod.Memory().Read(ptr, length); ok {
+				stderr.Write(data)
+				stderr.Write([]byte("\n"))
+			}
+		}).
+		Export("log").
+		Instantiate(ctx)
+	if err != nil {
+		return failErr("初始化宿主模块失败", err)
+	}
+
+	compiled, err := runtime.CompileModule(ctx, code)
+	if err != nil {
+		return failErr("编译WASM模块失败", err)
+	}
+
+	moduleConfig := wazero.NewModuleConfig().
+		WithName("").
+		WithStartFunctions("_initialize").
+		WithStdout(stderr).
+		WithStderr(stderr).
+		WithSysWalltime().
+		WithSysNanotime()
+	mod, err := runtime.InstantiateModule(ctx, compiled, moduleConfig)
+	if err != nil {
+		return failErr("实例化WASM模块失败", err)
+	}
+
+	alloc := mod.ExportedFunction("alloc")
+	handle := mod.ExportedFunction("handle")
+	if alloc == nil || handle == nil || mod.Memory() == nil {
+		return fail("WASM模块必须导出 memory、alloc 和 handle")
+	}
+
+	// 写入请求
+	results, err := alloc.Call(ctx, uint64(len(input)))
+	if err != nil {
+		return failErr("调用 alloc 失败", err)
+	}
+	ptr := uint32(results[0])
+	if !mod.Memory().Write(ptr, input) {
+		return fail("alloc 返回的内存地址越界")
+	}
+
+	// 处理请求并读取响应
+	results, err = handle.Call(ctx, uint64(ptr), uint64(len(input)))
+	if err != nil {
+		return failErr("调用 handle 失败", err)
+	}
+	outPtr, outLen := uint32(results[0]>>32), uint32(results[0])
+	output, ok := mod.Memory().Read(outPtr, outLen)
+	if !ok {
+		return fail("handle 返回的内存地址越界")
+	}
+
+	response, err := decodeResponse(output)
+	if err != nil {
+		return fail(err.Error())
+	}
+	return response, stderr.String(), nil
+}
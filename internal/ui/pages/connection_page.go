@@ -1,34 +1,183 @@
 package pages
 
 import (
+	"fmt"
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/layout"
 	"fyne.io/fyne/v2/widget"
 	"go-DBmodeler/internal/config"
+	"go-DBmodeler/internal/db/connector"
+	"go-DBmodeler/internal/db/dialect"
+	"go-DBmodeler/internal/ui/widgets"
 	"go-DBmodeler/pkg/logger"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 连接树节点ID的前缀
+const (
+	groupNodePrefix      = "g:"
+	connectionNodePrefix = "c:"
 )
 
 // ConnectionPage 表示连接管理页面
 type ConnectionPage struct {
-	container   *fyne.Container
-	log         *logger.Logger
-	storage     *config.Storage
-	connections []*ConnectionConfig
-	list        *widget.List
-	onRefresh   func() // 刷新回调函数
+	container    *fyne.Container
+	log          *logger.Logger
+	storage      *config.Storage
+	connections  []*ConnectionConfig
+	tree         *widget.Tree
+	detailsPanel *fyne.Container
+	onRefresh    func() // 刷新回调函数
+
+	// 连接树
+	filter   string              // 搜索关键字
+	children map[string][]string // 节点ID到子节点ID的映射，根节点ID为空字符串
 }
 
 // ConnectionConfig 表示数据库连接配置
 type ConnectionConfig struct {
-	Name     string
-	Type     string
-	Host     string
-	Port     string
-	Username string
-	Password string
-	Database string
+	Name        string
+	Type        string
+	Host        string
+	Port        string
+	Username    string
+	Password    string
+	Database    string
+	Group       string
+	Tags        []string
+	Environment string
+
+	// 连接和查询超时（秒），为0时使用默认值
+	ConnectTimeout int
+	QueryTimeout   int
+
+	// GeneratorDefaults 是该连接的生成器默认设置，可以为空
+	GeneratorDefaults *config.GeneratorDefaults
+	// SSH 是通过跳板机连接时的隧道配置，可以为空
+	SSH *config.SSHTunnel
+	// TLS 是连接的TLS设置，为空时不使用TLS
+	TLS *config.TLSOptions
+
+	// Indirect 记录使用间接引用的字段，键为字段名，值为原始引用
+	Indirect map[string]string
+	// ResolveError 记录解析间接引用时的错误，连接时提示给用户
+	ResolveError error
+}
+
+// NewConnectionConfig 根据已解密的存储配置创建连接配置，并解析其中的间接引用
+// 解析后的值只保存在内存中
+func NewConnectionConfig(stored config.ConnectionConfig) *ConnectionConfig {
+	resolved, err := stored.Resolve()
+	return &ConnectionConfig{
+		Name:         resolved.Name,
+		Type:         resolved.Type,
+		Host:         resolved.Host,
+		Port:         resolved.Port,
+		Username:     resolved.Username,
+		Password:     resolved.Password,
+		Database:     resolved.Database,
+		Group:        resolved.Group,
+		Tags:         resolved.Tags,
+		Environment:  resolved.Environment,
+		Indirect:     stored.IndirectFields(),
+		ResolveError: err,
+
+		ConnectTimeout: resolved.ConnectTimeout,
+		QueryTimeout:   resolved.QueryTimeout,
+
+		GeneratorDefaults: resolved.GeneratorDefaults,
+		SSH:               resolved.SSH,
+		TLS:               resolved.TLS,
+	}
+}
+
+// ConnectorConfig 返回创建数据库连接器使用的配置
+func (c *ConnectionConfig) ConnectorConfig() *connector.ConnectionConfig {
+	connConfig := &connector.ConnectionConfig{
+		Type:     c.Type,
+		Host:     c.Host,
+		Port:     c.Port,
+		Username: c.Username,
+		Password: c.Password,
+		Database: c.Database,
+
+		ConnectTimeout: time.Duration(c.ConnectTimeout) * time.Second,
+		QueryTimeout:   time.Duration(c.QueryTimeout) * time.Second,
+	}
+	if tunnel := c.SSH; tunnel != nil {
+		connConfig.SSH = &connector.SSHConfig{
+			Host:                  tunnel.Host,
+			Port:                  tunnel.Port,
+			User:                  tunnel.User,
+			Auth:                  tunnel.Auth,
+			KeyFile:               tunnel.KeyFile,
+			Password:              tunnel.Password,
+			KnownHostsFile:        tunnel.KnownHostsFile,
+			InsecureIgnoreHostKey: tunnel.InsecureIgnoreHostKey,
+		}
+	}
+	if options := c.TLS; options != nil {
+		connConfig.TLS = &connector.TLSConfig{
+			Mode:       options.Mode,
+			CACert:     options.CACert,
+			ClientCert: options.ClientCert,
+			ClientKey:  options.ClientKey,
+			ServerName: options.ServerName,
+		}
+	}
+	return connConfig
+}
+
+// IsProduction 判断连接是否标记为生产环境
+func (c *ConnectionConfig) IsProduction() bool {
+	return c.Environment == config.EnvironmentProd
+}
+
+// displayValue 返回字段在界面上显示的值，间接引用显示原始引用而不是解析结果
+func (c *ConnectionConfig) displayValue(field, value string) string {
+	if ref, ok := c.Indirect[field]; ok {
+		return ref + "（间接引用）"
+	}
+	return value
+}
+
+// matches 判断连接是否匹配搜索关键字
+// 关键字按空格分隔，每个关键字都需要匹配名称、分组、标签、环境、类型、主机或数据库之一
+func (c *ConnectionConfig) matches(filter string) bool {
+	fields := []string{c.Name, c.Group, c.Environment, widgets.EnvironmentLabel(c.Environment), c.Type,
+		c.displayValue("host", c.Host), c.displayValue("database", c.Database)}
+	fields = append(fields, c.Tags...)
+	haystack := strings.ToLower(strings.Join(fields, "\n"))
+
+	for _, term := range strings.Fields(strings.ToLower(filter)) {
+		if !strings.Contains(haystack, strings.TrimPrefix(term, "#")) {
+			return false
+		}
+	}
+	return true
+}
+
+// confirmProduction 对生产环境连接执行操作前要求确认，其他连接直接以 true 调用回调
+func confirmProduction(name, environment, action string, win fyne.Window, callback func(confirm bool)) {
+	if environment != config.EnvironmentProd {
+		callback(true)
+		return
+	}
+
+	confirmDialog := dialog.NewConfirm(
+		"生产环境",
+		fmt.Sprintf("连接 \"%s\" 标记为生产环境，确定要%s吗？", name, action),
+		callback,
+		win,
+	)
+	confirmDialog.SetDismissText("取消")
+	confirmDialog.SetConfirmText("继续")
+	confirmDialog.Show()
 }
 
 // NewConnectionPage 创建一个新的连接管理页面
@@ -69,82 +218,182 @@ func (p *ConnectionPage) loadConnections() {
 	storedConns := p.storage.GetConnections()
 	for _, conn := range storedConns {
 		// 解密密码
-		decryptedConfig, err := config.DecryptConnectionPassword(conn)
+		decryptedConfig, err := p.storage.DecryptConnectionPassword(conn)
 		if err != nil {
 			p.log.Errorf("解密连接密码失败: %v", err)
 			continue
 		}
 
-		p.connections = append(p.connections, &ConnectionConfig{
-			Name:     decryptedConfig.Name,
-			Type:     decryptedConfig.Type,
-			Host:     decryptedConfig.Host,
-			Port:     decryptedConfig.Port,
-			Username: decryptedConfig.Username,
-			Password: decryptedConfig.Password,
-			Database: decryptedConfig.Database,
+		p.connections = append(p.connections, NewConnectionConfig(decryptedConfig))
+	}
+
+	p.rebuildTree()
+}
+
+// rebuildTree 根据分组和搜索关键字重建连接树
+// 分组可以用 "/" 嵌套，未分组的连接显示在根节点下
+func (p *ConnectionPage) rebuildTree() {
+	p.children = make(map[string][]string)
+	known := make(map[string]bool)
+
+	// addGroup 创建分组节点及其所有上级节点，返回分组节点ID
+	addGroup := func(group string) string {
+		parent := ""
+		path := ""
+		for _, part := range strings.Split(group, "/") {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+			if path != "" {
+				path += "/"
+			}
+			path += part
+			id := groupNodePrefix + path
+			if !known[id] {
+				known[id] = true
+				p.children[parent] = append(p.children[parent], id)
+			}
+			parent = id
+		}
+		return parent
+	}
+
+	for _, conn := range p.connections {
+		if !conn.matches(p.filter) {
+			continue
+		}
+		parent := addGroup(conn.Group)
+		p.children[parent] = append(p.children[parent], connectionNodePrefix+conn.Name)
+	}
+
+	// 分组在前，连接在后，各自按名称排序
+	for _, ids := range p.children {
+		sort.Slice(ids, func(i, j int) bool {
+			gi, gj := strings.HasPrefix(ids[i], groupNodePrefix), strings.HasPrefix(ids[j], groupNodePrefix)
+			if gi != gj {
+				return gi
+			}
+			return ids[i] < ids[j]
 		})
 	}
 }
 
+// refreshTree 重新加载连接并刷新连接树
+func (p *ConnectionPage) refreshTree() {
+	p.loadConnections()
+	if p.tree != nil {
+		p.tree.Refresh()
+		p.tree.OpenAllBranches()
+	}
+}
+
+// findConnection 根据名称查找连接
+func (p *ConnectionPage) findConnection(name string) *ConnectionConfig {
+	for _, conn := range p.connections {
+		if conn.Name == name {
+			return conn
+		}
+	}
+	return nil
+}
+
 // buildUI 构建连接管理页面的UI
 func (p *ConnectionPage) buildUI() *fyne.Container {
-	// 创建连接列表
-	p.list = widget.NewList(
-		func() int {
-			return len(p.connections)
+	// 创建连接树，按分组显示连接
+	p.tree = widget.NewTree(
+		func(id widget.TreeNodeID) []widget.TreeNodeID {
+			return p.children[id]
 		},
-		func() fyne.CanvasObject {
-			// 使用Text函数创建标签，可能有助于解决中文显示问题
-			return widget.NewLabel("Connection Name")
+		func(id widget.TreeNodeID) bool {
+			return id == "" || strings.HasPrefix(id, groupNodePrefix)
 		},
-		func(id widget.ListItemID, obj fyne.CanvasObject) {
-			if id < len(p.connections) {
-				obj.(*widget.Label).SetText(p.connections[id].Name)
+		func(branch bool) fyne.CanvasObject {
+			if branch {
+				return widget.NewLabel("Group")
 			}
+			tags := widget.NewLabel("")
+			tags.Importance = widget.LowImportance
+			return container.NewHBox(widgets.NewEnvironmentBadge(""), widget.NewLabel("Connection Name"), tags)
+		},
+		func(id widget.TreeNodeID, branch bool, obj fyne.CanvasObject) {
+			if branch {
+				path := strings.TrimPrefix(id, groupNodePrefix)
+				obj.(*widget.Label).SetText("📁 " + path[strings.LastIndex(path, "/")+1:])
+				return
+			}
+			conn := p.findConnection(strings.TrimPrefix(id, connectionNodePrefix))
+			if conn == nil {
+				return
+			}
+			row := obj.(*fyne.Container)
+			row.Objects[0].(*widgets.EnvironmentBadge).SetEnvironment(conn.Environment)
+			row.Objects[1].(*widget.Label).SetText(conn.Name)
+			tags := make([]string, 0, len(conn.Tags))
+			for _, tag := range conn.Tags {
+				tags = append(tags, "#"+tag)
+			}
+			row.Objects[2].(*widget.Label).SetText(strings.Join(tags, " "))
 		},
 	)
+	p.tree.OpenAllBranches()
+
+	// 创建搜索框
+	searchEntry := widget.NewEntry()
+	searchEntry.SetPlaceHolder("搜索名称、分组、标签、环境或主机")
+	searchEntry.OnChanged = func(text string) {
+		p.filter = text
+		p.rebuildTree()
+		p.tree.Refresh()
+		p.tree.OpenAllBranches()
+	}
 
 	// 创建新建连接按钮（使用中文）
 	newConnectionBtn := widget.NewButton("+ 新建连接", func() {
-		p.showNewConnectionDialog(fyne.CurrentApp().Driver().AllWindows()[0])
+		p.showConnectionDialog(fyne.CurrentApp().Driver().AllWindows()[0], nil)
+	})
+
+	// 创建导入连接按钮
+	importBtn := widget.NewButton("导入连接", func() {
+		p.showImportConnectionsDialog(fyne.CurrentApp().Driver().AllWindows()[0])
+	})
+
+	// 创建导出连接按钮
+	exportBtn := widget.NewButton("导出连接", func() {
+		p.showExportConnectionsDialog(fyne.CurrentApp().Driver().AllWindows()[0])
+	})
+
+	// 创建修改主密码按钮
+	changePasswordBtn := widget.NewButton("修改主密码", func() {
+		showChangeMasterPasswordDialog(p.log, p.storage, fyne.CurrentApp().Driver().AllWindows()[0])
 	})
 
 	// 创建右侧详情面板（使用中文）
-	detailsPanel := container.NewVBox(
+	p.detailsPanel = container.NewVBox(
 		widget.NewLabel("选择左侧连接查看详情"),
 	)
 
-	// 当选择连接时显示详情和删除按钮
-	p.list.OnSelected = func(id widget.ListItemID) {
-		if id < len(p.connections) {
-			conn := p.connections[id]
-
-			// 创建删除按钮
-			deleteBtn := widget.NewButton("删除连接", func() {
-				p.showDeleteConnectionDialog(id, fyne.CurrentApp().Driver().AllWindows()[0])
-			})
-
-			// 更新详情面板
-			detailsPanel.Objects = []fyne.CanvasObject{
-				widget.NewLabel("连接详情:"),
-				widget.NewLabel("名称: " + conn.Name),
-				widget.NewLabel("类型: " + conn.Type),
-				widget.NewLabel("主机: " + conn.Host),
-				widget.NewLabel("端口: " + conn.Port),
-				widget.NewLabel("用户名: " + conn.Username),
-				widget.NewLabel("数据库: " + conn.Database),
-				layout.NewSpacer(),
-				deleteBtn,
-			}
-			detailsPanel.Refresh()
+	// 当选择连接时显示详情，选择分组时展开或折叠
+	p.tree.OnSelected = func(id widget.TreeNodeID) {
+		if strings.HasPrefix(id, groupNodePrefix) {
+			p.tree.ToggleBranch(id)
+			p.tree.Unselect(id)
+			return
+		}
+		if conn := p.findConnection(strings.TrimPrefix(id, connectionNodePrefix)); conn != nil {
+			p.showDetails(conn)
 		}
 	}
 
 	// 创建分割布局
 	split := container.NewHSplit(
-		container.NewBorder(nil, newConnectionBtn, nil, nil, p.list),
-		detailsPanel,
+		container.NewBorder(
+			searchEntry,
+			container.NewVBox(newConnectionBtn, container.NewGridWithColumns(2, importBtn, exportBtn), changePasswordBtn),
+			nil, nil,
+			p.tree,
+		),
+		p.detailsPanel,
 	)
 	split.Offset = 0.3
 
@@ -154,19 +403,87 @@ func (p *ConnectionPage) buildUI() *fyne.Container {
 	return p.container
 }
 
-// showDeleteConnectionDialog 显示删除连接确认对话框
-func (p *ConnectionPage) showDeleteConnectionDialog(id widget.ListItemID, win fyne.Window) {
-	if id >= len(p.connections) {
-		return
+// showDetails 在详情面板中显示连接详情
+func (p *ConnectionPage) showDetails(conn *ConnectionConfig) {
+	win := fyne.CurrentApp().Driver().AllWindows()[0]
+
+	// 创建编辑和删除按钮，生产环境连接需要先确认
+	editBtn := widget.NewButton("编辑连接", func() {
+		confirmProduction(conn.Name, conn.Environment, "编辑此连接", win, func(confirm bool) {
+			if confirm {
+				p.showConnectionDialog(win, conn)
+			}
+		})
+	})
+	deleteBtn := widget.NewButton("删除连接", func() {
+		confirmProduction(conn.Name, conn.Environment, "删除此连接", win, func(confirm bool) {
+			if confirm {
+				p.showDeleteConnectionDialog(conn, win)
+			}
+		})
+	})
+	deleteBtn.Importance = widget.DangerImportance
+
+	environment := "未标记"
+	if conn.Environment != "" {
+		environment = widgets.EnvironmentLabel(conn.Environment) + " (" + conn.Environment + ")"
+	}
+
+	// 更新详情面板
+	p.detailsPanel.Objects = []fyne.CanvasObject{
+		container.NewHBox(widget.NewLabel("连接详情:"), widgets.NewEnvironmentBadge(conn.Environment)),
+		widget.NewLabel("名称: " + conn.Name),
+		widget.NewLabel("类型: " + conn.Type),
+		widget.NewLabel("环境: " + environment),
+		widget.NewLabel("分组: " + conn.Group),
+		widget.NewLabel("标签: " + strings.Join(conn.Tags, ", ")),
+		widget.NewLabel("主机: " + conn.displayValue("host", conn.Host)),
+		widget.NewLabel("端口: " + conn.displayValue("port", conn.Port)),
+		widget.NewLabel("用户名: " + conn.displayValue("username", conn.Username)),
+		widget.NewLabel("数据库: " + conn.displayValue("database", conn.Database)),
+	}
+	if options := conn.TLS; options != nil {
+		p.detailsPanel.Objects = append(p.detailsPanel.Objects, widget.NewLabel("TLS: "+options.Mode))
+	}
+	if tunnel := conn.SSH; tunnel != nil {
+		port := tunnel.Port
+		if port == "" {
+			port = "22"
+		}
+		p.detailsPanel.Objects = append(p.detailsPanel.Objects,
+			widget.NewLabel(fmt.Sprintf("SSH隧道: %s@%s:%s", conn.displayValue("ssh.user", tunnel.User),
+				conn.displayValue("ssh.host", tunnel.Host), conn.displayValue("ssh.port", port))))
+	}
+	if defaults := conn.GeneratorDefaults; defaults != nil {
+		p.detailsPanel.Objects = append(p.detailsPanel.Objects, widget.NewLabel("默认生成器: "+defaults.Generator))
+	}
+	if ref, ok := conn.Indirect["password"]; ok {
+		p.detailsPanel.Objects = append(p.detailsPanel.Objects, widget.NewLabel("密码: "+ref+"（间接引用）"))
+	}
+	if conn.ResolveError != nil {
+		errorLabel := widget.NewLabel("引用解析失败: " + conn.ResolveError.Error())
+		errorLabel.Importance = widget.DangerImportance
+		errorLabel.Wrapping = fyne.TextWrapWord
+		p.detailsPanel.Objects = append(p.detailsPanel.Objects, errorLabel)
 	}
+	p.detailsPanel.Objects = append(p.detailsPanel.Objects, layout.NewSpacer(), container.NewHBox(editBtn, deleteBtn))
+	p.detailsPanel.Refresh()
+}
+
+// clearDetails 清空详情面板
+func (p *ConnectionPage) clearDetails() {
+	p.detailsPanel.Objects = []fyne.CanvasObject{widget.NewLabel("选择左侧连接查看详情")}
+	p.detailsPanel.Refresh()
+}
 
-	conn := p.connections[id]
+// showDeleteConnectionDialog 显示删除连接确认对话框
+func (p *ConnectionPage) showDeleteConnectionDialog(conn *ConnectionConfig, win fyne.Window) {
 	confirmDialog := dialog.NewConfirm(
 		"确认删除",
 		"确定要删除连接 \""+conn.Name+"\" 吗？此操作不可撤销。",
 		func(confirm bool) {
 			if confirm {
-				p.deleteConnection(id, win)
+				p.deleteConnection(conn, win)
 			}
 		},
 		win,
@@ -177,13 +494,7 @@ func (p *ConnectionPage) showDeleteConnectionDialog(id widget.ListItemID, win fy
 }
 
 // deleteConnection 删除指定的连接
-func (p *ConnectionPage) deleteConnection(id widget.ListItemID, win fyne.Window) {
-	if id >= len(p.connections) {
-		return
-	}
-
-	conn := p.connections[id]
-
+func (p *ConnectionPage) deleteConnection(conn *ConnectionConfig, win fyne.Window) {
 	// 从存储中删除连接
 	if err := p.storage.DeleteConnection(conn.Name); err != nil {
 		p.log.Errorf("删除连接失败: %v", err)
@@ -192,9 +503,9 @@ func (p *ConnectionPage) deleteConnection(id widget.ListItemID, win fyne.Window)
 	}
 
 	// 重新加载连接列表
-	p.loadConnections()
-	p.list.Refresh()
-	p.list.UnselectAll()
+	p.tree.UnselectAll()
+	p.refreshTree()
+	p.clearDetails()
 
 	p.log.Infof("删除连接: %s", conn.Name)
 
@@ -205,74 +516,221 @@ func (p *ConnectionPage) deleteConnection(id widget.ListItemID, win fyne.Window)
 	dialog.ShowInformation("删除成功", "数据库连接已删除", win)
 }
 
-// showNewConnectionDialog 显示新建连接对话框
-func (p *ConnectionPage) showNewConnectionDialog(win fyne.Window) {
+// environmentOptions 是连接对话框中环境选择器的选项，第一个选项表示未标记
+func environmentOptions() []string {
+	options := []string{"未标记"}
+	for _, environment := range config.Environments {
+		options = append(options, fmt.Sprintf("%s (%s)", widgets.EnvironmentLabel(environment), environment))
+	}
+	return options
+}
+
+// showConnectionDialog 显示新建或编辑连接对话框，existing 为空时新建连接
+func (p *ConnectionPage) showConnectionDialog(win fyne.Window, existing *ConnectionConfig) {
+	// 编辑时使用存储中的原始配置，保留间接引用而不是解析后的值
+	var stored config.ConnectionConfig
+	if existing != nil {
+		raw, ok := p.storage.FindConnection(existing.Name)
+		if !ok {
+			dialog.ShowError(fmt.Errorf("连接 '%s' 不存在", existing.Name), win)
+			return
+		}
+		decrypted, err := p.storage.DecryptConnectionPassword(raw)
+		if err != nil {
+			p.log.Errorf("解密连接密码失败: %v", err)
+			dialog.ShowError(err, win)
+			return
+		}
+		stored = decrypted
+	}
+
 	// 创建表单项（使用中文）
 	nameEntry := widget.NewEntry()
 	nameEntry.SetPlaceHolder("连接名称")
 
-	dbTypeSelect := widget.NewSelect([]string{"MySQL", "PostgreSQL", "SQLite"}, nil)
+	dbTypeSelect := widget.NewSelect(dialect.Names(), nil)
 	dbTypeSelect.PlaceHolder = "选择数据库类型"
 
-	hostEntry := widget.NewEntry()
-	hostEntry.SetPlaceHolder("主机名/IP地址")
+	// 连接字段的输入框，显示哪些字段由选中的数据库类型决定
+	fieldEntries := map[string]*widget.Entry{
+		dialect.FieldHost:     widget.NewEntry(),
+		dialect.FieldPort:     widget.NewEntry(),
+		dialect.FieldUsername: widget.NewEntry(),
+		dialect.FieldPassword: widget.NewPasswordEntry(),
+		dialect.FieldDatabase: widget.NewEntry(),
+	}
+	fieldsBox := container.NewVBox()
+
+	groupEntry := widget.NewEntry()
+	groupEntry.SetPlaceHolder("分组（可选），用 / 嵌套，例如 支付/欧洲")
+
+	tagsEntry := widget.NewEntry()
+	tagsEntry.SetPlaceHolder("标签（可选），用逗号分隔")
+
+	options := environmentOptions()
+	environmentSelect := widget.NewSelect(options, nil)
+	environmentSelect.SetSelected(options[0])
+
+	connectTimeoutEntry := widget.NewEntry()
+	connectTimeoutEntry.SetPlaceHolder(fmt.Sprintf("连接超时秒数，默认 %d", int(connector.DefaultConnectTimeout.Seconds())))
+	connectTimeoutEntry.Validator = validateTimeout
+
+	queryTimeoutEntry := widget.NewEntry()
+	queryTimeoutEntry.SetPlaceHolder(fmt.Sprintf("查询超时秒数，默认 %d", int(connector.DefaultQueryTimeout.Seconds())))
+	queryTimeoutEntry.Validator = validateTimeout
+
+	if existing != nil {
+		nameEntry.SetText(stored.Name)
+		nameEntry.Disable()
+		fieldEntries[dialect.FieldHost].SetText(stored.Host)
+		fieldEntries[dialect.FieldPort].SetText(stored.Port)
+		fieldEntries[dialect.FieldUsername].SetText(stored.Username)
+		fieldEntries[dialect.FieldPassword].SetText(stored.Password)
+		fieldEntries[dialect.FieldDatabase].SetText(stored.Database)
+		groupEntry.SetText(stored.Group)
+		tagsEntry.SetText(strings.Join(stored.Tags, ", "))
+		if stored.ConnectTimeout > 0 {
+			connectTimeoutEntry.SetText(strconv.Itoa(stored.ConnectTimeout))
+		}
+		if stored.QueryTimeout > 0 {
+			queryTimeoutEntry.SetText(strconv.Itoa(stored.QueryTimeout))
+		}
+		for i, environment := range config.Environments {
+			if environment == stored.Environment {
+				environmentSelect.SetSelected(options[i+1])
+			}
+		}
+	}
+
+	// SSH隧道和TLS设置
+	sshForm := newSSHTunnelForm(stored.SSH, win)
+	tlsForm := newTLSOptionsForm(stored.TLS, win)
 
-	portEntry := widget.NewEntry()
-	portEntry.SetPlaceHolder("端口号")
+	// 切换数据库类型时按注册的字段重新生成连接字段表单，并隐藏不支持的SSH隧道和TLS设置
+	dbTypeSelect.OnChanged = func(name string) {
+		d, err := dialect.Lookup(name)
+		if err != nil {
+			return
+		}
+		items := make([]*widget.FormItem, 0, len(d.Fields))
+		for _, field := range d.Fields {
+			entry := fieldEntries[field.Key]
+			entry.SetPlaceHolder(field.PlaceHolder)
+			items = append(items, widget.NewFormItem(field.Label, entry))
+		}
+		fieldsBox.Objects = []fyne.CanvasObject{widget.NewForm(items...)}
+		fieldsBox.Refresh()
 
-	usernameEntry := widget.NewEntry()
-	usernameEntry.SetPlaceHolder("用户名")
+		sshForm.container.Hidden = !d.SSH
+		tlsForm.container.Hidden = !d.TLS
+		sshForm.container.Refresh()
+		tlsForm.container.Refresh()
+	}
+	if existing != nil {
+		dbTypeSelect.SetSelected(stored.Type)
+	} else if names := dialect.Names(); len(names) > 0 {
+		dbTypeSelect.SetSelected(names[0])
+	}
 
-	passwordEntry := widget.NewPasswordEntry()
-	passwordEntry.SetPlaceHolder("密码")
+	// 说明间接引用的用法
+	referenceHint := widget.NewLabel("主机、端口、用户名、密码、数据库、SSH隧道和TLS设置可以使用 ${env:变量名} 或 ${file:路径} 引用环境变量或密钥文件，连接时解析，不会保存解析后的值。")
+	referenceHint.Wrapping = fyne.TextWrapWord
 
-	databaseEntry := widget.NewEntry()
-	databaseEntry.SetPlaceHolder("数据库名（可选）")
+	var connectionDialog dialog.Dialog
 
-	// 创建表单
+	// 创建表单，连接字段表单位于名称和类型之后
+	typeForm := widget.NewForm(
+		widget.NewFormItem("连接名称", nameEntry),
+		widget.NewFormItem("数据库类型", dbTypeSelect),
+	)
 	form := &widget.Form{
 		Items: []*widget.FormItem{
-			{Text: "连接名称", Widget: nameEntry},
-			{Text: "数据库类型", Widget: dbTypeSelect},
-			{Text: "主机", Widget: hostEntry},
-			{Text: "端口", Widget: portEntry},
-			{Text: "用户名", Widget: usernameEntry},
-			{Text: "密码", Widget: passwordEntry},
-			{Text: "数据库", Widget: databaseEntry},
+			{Text: "环境", Widget: environmentSelect},
+			{Text: "分组", Widget: groupEntry},
+			{Text: "标签", Widget: tagsEntry},
+			{Text: "连接超时", Widget: connectTimeoutEntry},
+			{Text: "查询超时", Widget: queryTimeoutEntry},
 		},
 		OnSubmit: func() {
-			// 创建新连接配置
-			config := config.ConnectionConfig{
+			d, err := dialect.Lookup(dbTypeSelect.Selected)
+			if err != nil {
+				dialog.ShowError(fmt.Errorf("请选择数据库类型"), win)
+				return
+			}
+
+			// 只保留所选数据库类型使用的字段
+			values := make(map[string]string, len(d.Fields))
+			for _, field := range d.Fields {
+				values[field.Key] = fieldEntries[field.Key].Text
+			}
+			if issues := d.Check(values); len(issues) > 0 {
+				dialog.ShowError(fmt.Errorf("%s", strings.Join(issues, "\n")), win)
+				return
+			}
+
+			// 创建连接配置
+			conn := config.ConnectionConfig{
 				Name:     nameEntry.Text,
-				Type:     dbTypeSelect.Selected,
-				Host:     hostEntry.Text,
-				Port:     portEntry.Text,
-				Username: usernameEntry.Text,
-				Password: passwordEntry.Text,
-				Database: databaseEntry.Text,
+				Type:     d.Name,
+				Host:     values[dialect.FieldHost],
+				Port:     values[dialect.FieldPort],
+				Username: values[dialect.FieldUsername],
+				Password: values[dialect.FieldPassword],
+				Database: values[dialect.FieldDatabase],
+				Group:    strings.Trim(strings.TrimSpace(groupEntry.Text), "/"),
+				Tags:     splitTags(tagsEntry.Text),
+
+				GeneratorDefaults: stored.GeneratorDefaults,
+			}
+			if d.SSH {
+				conn.SSH = sshForm.Tunnel()
+			}
+			if d.TLS {
+				conn.TLS = tlsForm.Options()
+			}
+			conn.ConnectTimeout, _ = strconv.Atoi(strings.TrimSpace(connectTimeoutEntry.Text))
+			conn.QueryTimeout, _ = strconv.Atoi(strings.TrimSpace(queryTimeoutEntry.Text))
+			for i, option := range options[1:] {
+				if option == environmentSelect.Selected {
+					conn.Environment = config.Environments[i]
+				}
 			}
 
 			// 保存到存储
-			if err := p.storage.AddConnection(config); err != nil {
+			if existing != nil {
+				err = p.storage.UpdateConnection(conn)
+			} else {
+				err = p.storage.AddConnection(conn)
+			}
+			if err != nil {
 				p.log.Errorf("保存连接失败: %v", err)
 				dialog.ShowError(err, win)
 				return
 			}
 
 			// 重新加载连接列表
-			p.loadConnections()
-			p.list.Refresh()
+			p.refreshTree()
+			if updated := p.findConnection(conn.Name); updated != nil {
+				p.tree.Select(connectionNodePrefix + conn.Name)
+				p.showDetails(updated)
+			}
 
-			p.log.Infof("创建新连接: %s (%s)", config.Name, config.Type)
+			if existing != nil {
+				p.log.Infof("更新连接: %s (%s)", conn.Name, conn.Type)
+			} else {
+				p.log.Infof("创建新连接: %s (%s)", conn.Name, conn.Type)
+			}
 
 			// 调用刷新回调，通知其他页面更新连接列表
 			p.onRefresh()
 
 			// 关闭对话框
-			dialog.ShowInformation("连接创建成功", "数据库连接已保存", win)
+			connectionDialog.Hide()
+			dialog.ShowInformation("保存成功", "数据库连接已保存", win)
 		},
 		OnCancel: func() {
 			// 关闭对话框
+			connectionDialog.Hide()
 		},
 		SubmitText: "保存",
 		CancelText: "取消",
@@ -287,7 +745,12 @@ func (p *ConnectionPage) showNewConnectionDialog(win fyne.Window) {
 
 	// 创建对话框内容
 	content := container.NewVBox(
+		typeForm,
+		fieldsBox,
 		form,
+		sshForm.container,
+		tlsForm.container,
+		referenceHint,
 		container.NewHBox(
 			layout.NewSpacer(),
 			testBtn,
@@ -295,7 +758,38 @@ func (p *ConnectionPage) showNewConnectionDialog(win fyne.Window) {
 	)
 
 	// 显示对话框
-	dialog := dialog.NewCustom("新建数据库连接", "关闭", content, win)
-	dialog.Resize(fyne.NewSize(400, 400))
-	dialog.Show()
+	title := "新建数据库连接"
+	if existing != nil {
+		title = "编辑数据库连接"
+	}
+	connectionDialog = dialog.NewCustom(title, "关闭", container.NewVScroll(content), win)
+	connectionDialog.Resize(fyne.NewSize(520, 640))
+	connectionDialog.Show()
+}
+
+// validateTimeout 校验超时秒数，允许为空表示使用默认值
+func validateTimeout(text string) error {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil
+	}
+	if seconds, err := strconv.Atoi(text); err != nil || seconds <= 0 {
+		return fmt.Errorf("超时时间应为正整数秒")
+	}
+	return nil
+}
+
+// splitTags 将逗号分隔的标签拆分为列表，去掉空白和重复的标签
+func splitTags(text string) []string {
+	var tags []string
+	seen := make(map[string]bool)
+	for _, tag := range strings.FieldsFunc(text, func(r rune) bool { return r == ',' || r == '，' }) {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "#")
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+	}
+	return tags
 }
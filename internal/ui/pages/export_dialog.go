@@ -0,0 +1,125 @@
+package pages
+
+import (
+	"fmt"
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"go-DBmodeler/internal/config"
+	"go-DBmodeler/internal/ui/widgets"
+	"sort"
+)
+
+// 导出时的密码处理选项
+const (
+	exportStripPasswords       = "移除密码"
+	exportPlaceholderPasswords = "替换为环境变量占位符 ${env:<连接名>_PASSWORD}"
+)
+
+// showExportConnectionsDialog 显示导出连接对话框
+// 导出的文件不包含密码，可以提交到代码仓库与团队共享，通过“导入连接”合并
+func (p *ConnectionPage) showExportConnectionsDialog(win fyne.Window) {
+	connections := p.storage.GetConnections()
+	if len(connections) == 0 {
+		dialog.ShowInformation("导出连接", "没有可导出的连接", win)
+		return
+	}
+
+	// 按分组和名称排序
+	sort.SliceStable(connections, func(i, j int) bool {
+		if connections[i].Group != connections[j].Group {
+			return connections[i].Group < connections[j].Group
+		}
+		return connections[i].Name < connections[j].Name
+	})
+
+	checks := make([]*widget.Check, len(connections))
+	checkList := container.NewVBox()
+	for i, conn := range connections {
+		label := fmt.Sprintf("%s (%s)", conn.Name, conn.Type)
+		if conn.Group != "" {
+			label = conn.Group + " / " + label
+		}
+		if conn.Environment != "" {
+			label = "[" + widgets.EnvironmentLabel(conn.Environment) + "] " + label
+		}
+		checks[i] = widget.NewCheck(label, nil)
+		checks[i].SetChecked(true)
+		checkList.Add(checks[i])
+	}
+
+	selectAll := widget.NewCheck("全选", func(checked bool) {
+		for _, check := range checks {
+			check.SetChecked(checked)
+		}
+	})
+	selectAll.SetChecked(true)
+
+	passwordMode := widget.NewRadioGroup([]string{exportStripPasswords, exportPlaceholderPasswords}, nil)
+	passwordMode.SetSelected(exportPlaceholderPasswords)
+
+	var exportDialog dialog.Dialog
+	exportBtn := widget.NewButton("导出...", func() {
+		var names []string
+		for i, check := range checks {
+			if check.Checked {
+				names = append(names, connections[i].Name)
+			}
+		}
+		if len(names) == 0 {
+			dialog.ShowInformation("导出连接", "请选择要导出的连接", win)
+			return
+		}
+
+		mode := config.PasswordStrip
+		if passwordMode.Selected == exportPlaceholderPasswords {
+			mode = config.PasswordPlaceholder
+		}
+		data, err := p.storage.ExportConnections(names, mode)
+		if err != nil {
+			p.log.Errorf("导出连接失败: %v", err)
+			dialog.ShowError(err, win)
+			return
+		}
+
+		saveDialog := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+			if err != nil || writer == nil {
+				return
+			}
+			defer writer.Close()
+
+			if _, err := writer.Write(data); err != nil {
+				p.log.Errorf("保存导出文件失败: %v", err)
+				dialog.ShowError(err, win)
+				return
+			}
+
+			p.log.Infof("导出 %d 个连接到 %s", len(names), writer.URI().Path())
+			exportDialog.Hide()
+			dialog.ShowInformation("导出成功", fmt.Sprintf("已导出 %d 个连接到 %s\n文件中不包含密码，可以提交到代码仓库", len(names), writer.URI().Path()), win)
+		}, win)
+		saveDialog.SetFileName("connections.json")
+		saveDialog.Show()
+	})
+	exportBtn.Importance = widget.HighImportance
+
+	content := container.NewBorder(
+		container.NewVBox(
+			widget.NewLabel("选择要导出的连接，导出的文件可以提交到代码仓库与团队共享："),
+			selectAll,
+		),
+		container.NewVBox(
+			widget.NewSeparator(),
+			widget.NewLabel("密码处理:"),
+			passwordMode,
+			exportBtn,
+		),
+		nil, nil,
+		container.NewVScroll(checkList),
+	)
+
+	exportDialog = dialog.NewCustom("导出连接", "关闭", content, win)
+	exportDialog.Resize(fyne.NewSize(500, 500))
+	exportDialog.Show()
+}
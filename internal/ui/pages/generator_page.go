@@ -1,22 +1,39 @@
 package pages
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/layout"
+	fynestorage "fyne.io/fyne/v2/storage"
 	"fyne.io/fyne/v2/widget"
 	"go-DBmodeler/internal/config"
 	"go-DBmodeler/internal/db/connector"
+	"go-DBmodeler/internal/db/dialect"
 	"go-DBmodeler/internal/db/metadata"
 	"go-DBmodeler/internal/generator"
+	"go-DBmodeler/internal/plugin"
 	"go-DBmodeler/internal/ui/widgets"
 	"go-DBmodeler/pkg/logger"
 	"io"
+	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
 )
 
+// builtinGenerator 是生成器选择器中内置生成器（模板 + 脚本）的选项
+const builtinGenerator = "内置模板 + 脚本"
+
+// allKindsOption 是对象类型筛选器中不筛选的选项
+const allKindsOption = "全部类型"
+
+// pluginOptionPrefix 是生成器选择器中插件选项的前缀
+const pluginOptionPrefix = "插件: "
+
 // GeneratorPage 表示TS模型生成页面
 type GeneratorPage struct {
 	container       *fyne.Container
@@ -25,25 +42,58 @@ type GeneratorPage struct {
 	processor       *metadata.Processor
 	storage         *config.Storage
 	templateManager *generator.TemplateManager
+	pluginManager   *plugin.Manager
 
 	// UI组件
 	connectionSelect *widget.Select
+	environmentBadge *widgets.EnvironmentBadge
 	databaseSelect   *widget.Select
 	tableSelect      *widget.Select
+	kindSelect       *widget.Select // 按对象类型筛选表列表
+	routineSelect    *widget.Select // 存储过程和函数选择器，与表选择器互斥
+	querySelect      *widget.Select // 已保存的查询选择器，与表和例程选择器互斥
+	queryEditBtn     *widget.Button
+	queryDeleteBtn   *widget.Button
+	generatorSelect  *widget.Select
 	scriptEditor     *widget.Entry
 	scriptLoadBtn    *widget.Button
 	generateBtn      *widget.Button
+	jsonShapeBtn     *widget.Button // 为选中表的JSON列推断结构，数据库支持读取样本数据时才可用
+	saveDefaultsBtn  *widget.Button
 	copyBtn          *widget.Button
 	saveBtn          *widget.Button
 	tableView        *widgets.TableView
+	dataPreview      *widgets.DataPreview // 分页预览选中表的数据
+	rightTabs        *container.AppTabs
+	dataTab          *container.TabItem
 	codeContainer    *fyne.Container // 代码显示容器
+	paramsContainer  *fyne.Container // 脚本参数表单容器
+	scriptTabs       *container.AppTabs
+	console          *widgets.ConsolePanel
 
 	// 数据
 	databases       []string
-	tables          []string
+	tables          []connector.TableInfo
 	selectedTable   string
+	routines        []connector.Routine
+	selectedRoutine string // 选中的存储过程或函数的签名
+	selectedQuery   string // 选中的已保存查询的名称
 	generatedCode   string
+	generatedFiles  []plugin.File // 插件生成的文件
 	currentMetadata *connector.TableMetadata
+	previewTable    string     // 已读取排序列的表
+	previewOrderBy  []string   // 预览数据时排序的列，为表的主键
+	pluginsMu       sync.Mutex // 保护 plugins、pluginsLoaded 和 pendingPlugin，插件在后台扫描
+	plugins         []*plugin.Plugin
+	pluginsLoaded   bool   // 是否已经完成插件扫描
+	pendingPlugin   string // 插件扫描完成后要选择的插件选项
+	activeLabel     string // 当前已连接的连接在选择器中的显示名称
+
+	// 脚本参数
+	connectionParams  map[string]string        // 当前连接默认设置中的参数值
+	currentScriptName string                   // 当前脚本名称，用于记住参数值
+	scriptParams      []generator.ScriptParam  // 当前脚本声明的参数
+	paramValues       map[string]func() string // 参数值读取函数
 }
 
 // NewGeneratorPage 创建一个新的TS模型生成页面
@@ -53,6 +103,7 @@ func NewGeneratorPage(log *logger.Logger, connections []*ConnectionConfig, templ
 		connections:     connections,
 		templateManager: templateManager,
 		storage:         storage,
+		pluginManager:   plugin.NewManager(log, storage.GetPluginDir()),
 	}
 
 	// 构建UI并返回容器
@@ -63,14 +114,16 @@ func NewGeneratorPage(log *logger.Logger, connections []*ConnectionConfig, templ
 
 // buildUI 构建TS模型生成页面的UI
 func (p *GeneratorPage) buildUI() *fyne.Container {
-	// 创建连接选择器
-	connectionNames := make([]string, 0, len(p.connections))
+	// 创建连接选择器，显示环境和分组
+	connectionLabels := make([]string, 0, len(p.connections))
 	for _, conn := range p.connections {
-		connectionNames = append(connectionNames, conn.Name)
+		connectionLabels = append(connectionLabels, connectionLabel(conn))
 	}
+	sort.Strings(connectionLabels)
 
-	p.connectionSelect = widget.NewSelect(connectionNames, p.onConnectionSelected)
+	p.connectionSelect = widget.NewSelect(connectionLabels, p.onConnectionSelected)
 	p.connectionSelect.PlaceHolder = "选择数据库连接"
+	p.environmentBadge = widgets.NewEnvironmentBadge("")
 
 	// 创建数据库选择器
 	p.databaseSelect = widget.NewSelect([]string{}, p.onDatabaseSelected)
@@ -82,7 +135,28 @@ func (p *GeneratorPage) buildUI() *fyne.Container {
 	p.tableSelect.PlaceHolder = "选择表"
 	p.tableSelect.Disable()
 
-	// 使用默认模板，不再需要模板选择器
+	// 创建对象类型筛选器，选项为当前数据库中存在的类型
+	p.kindSelect = widget.NewSelect([]string{allKindsOption}, p.onKindSelected)
+	p.kindSelect.Selected = allKindsOption
+	p.kindSelect.Disable()
+
+	// 创建存储过程和函数选择器，数据库支持时才可用
+	p.routineSelect = widget.NewSelect([]string{}, p.onRoutineSelected)
+	p.routineSelect.PlaceHolder = "选择存储过程或函数"
+	p.routineSelect.Disable()
+
+	// 创建已保存查询的选择器和编辑按钮，只列出当前连接的查询和没有指定连接的查询
+	p.querySelect = widget.NewSelect([]string{}, p.onQuerySelected)
+	p.querySelect.PlaceHolder = "选择已保存的查询"
+	queryNewBtn := widget.NewButton("新建", func() { p.showQueryEditor(nil) })
+	p.queryEditBtn = widget.NewButton("编辑", p.onQueryEditClicked)
+	p.queryDeleteBtn = widget.NewButton("删除", p.onQueryDeleteClicked)
+
+	// 创建生成器选择器，可选择内置生成器或插件目录中的外部插件
+	p.generatorSelect = widget.NewSelect([]string{builtinGenerator}, nil)
+	p.generatorSelect.SetSelected(builtinGenerator)
+	refreshPluginsBtn := widget.NewButton("刷新插件", p.refreshPlugins)
+	p.loadPlugins(p.pluginManager.Discover)
 
 	// 创建代码容器 - 使用更大的div块来展示代码
 	p.codeContainer = container.NewVBox()
@@ -104,6 +178,10 @@ func (p *GeneratorPage) buildUI() *fyne.Container {
 		"//    output = result + '\\n}';\n")
 	p.scriptEditor.Wrapping = fyne.TextWrapOff
 	p.scriptEditor.SetMinRowsVisible(10) // 设置最小行数，确保足够的编辑空间
+	p.scriptEditor.OnChanged = p.onScriptChanged
+
+	// 创建脚本参数表单容器
+	p.paramsContainer = container.NewVBox()
 
 	// 创建脚本加载按钮
 	p.scriptLoadBtn = widget.NewButton("加载脚本文件", p.onScriptLoadClicked)
@@ -115,7 +193,7 @@ func (p *GeneratorPage) buildUI() *fyne.Container {
 	}
 
 	// 创建选项卡容器
-	tabs := container.NewAppTabs(
+	p.scriptTabs = container.NewAppTabs(
 		container.NewTabItem("自定义脚本", container.NewBorder(
 			container.NewHBox(
 				widget.NewLabel("JavaScript脚本:"),
@@ -135,7 +213,7 @@ func (p *GeneratorPage) buildUI() *fyne.Container {
 			widget.NewLabel("• 小驼峰命名转换: 将字段名转换为小驼峰命名\n• 添加文件头注释: 自动添加文件头注释\n• 格式化代码: 格式化生成的TypeScript代码\n• 添加类型导入: 自动添加必要的类型导入"),
 		)),
 	)
-	tabs.SetTabLocation(container.TabLocationTop)
+	p.scriptTabs.SetTabLocation(container.TabLocationTop)
 
 	// 创建表视图
 	p.tableView = widgets.NewTableView(&connector.TableMetadata{
@@ -143,16 +221,27 @@ func (p *GeneratorPage) buildUI() *fyne.Container {
 		Fields: []connector.FieldInfo{},
 	})
 
+	// 创建数据预览，读取数据时按主键排序
+	p.dataPreview = widgets.NewDataPreview()
+	p.dataPreview.OnLoad = p.loadPreview
+
 	// 创建按钮
 	p.generateBtn = widget.NewButton("生成TS模型", p.onGenerateClicked)
+	p.jsonShapeBtn = widget.NewButton("推断JSON结构", p.onJSONShapeClicked)
+	p.saveDefaultsBtn = widget.NewButton("设为连接默认", p.onSaveDefaultsClicked)
 	p.copyBtn = widget.NewButton("复制到剪贴板", p.onCopyClicked)
 	p.saveBtn = widget.NewButton("保存为文件", p.onSaveClicked)
 
 	// 禁用按钮
 	p.generateBtn.Disable()
+	p.jsonShapeBtn.Disable()
+	p.saveDefaultsBtn.Disable()
 	p.copyBtn.Disable()
 	p.saveBtn.Disable()
 
+	// 加载已保存的查询，同时设置编辑和删除按钮的状态
+	p.refreshQueries()
+
 	// 创建左侧面板 - 简化版
 	leftPanel := container.NewVBox(
 		widget.NewLabelWithStyle("连接配置", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
@@ -160,7 +249,7 @@ func (p *GeneratorPage) buildUI() *fyne.Container {
 			nil,
 			nil,
 			widget.NewLabel("连接:"),
-			nil,
+			p.environmentBadge,
 			p.connectionSelect,
 		),
 		container.NewBorder(
@@ -174,17 +263,41 @@ func (p *GeneratorPage) buildUI() *fyne.Container {
 			nil,
 			nil,
 			widget.NewLabel("表:"),
-			nil,
+			p.kindSelect,
 			p.tableSelect,
 		),
+		container.NewBorder(
+			nil,
+			nil,
+			widget.NewLabel("例程:"),
+			nil,
+			p.routineSelect,
+		),
+		container.NewBorder(
+			nil,
+			nil,
+			widget.NewLabel("查询:"),
+			container.NewHBox(queryNewBtn, p.queryEditBtn, p.queryDeleteBtn),
+			p.querySelect,
+		),
+		container.NewBorder(
+			nil,
+			nil,
+			widget.NewLabel("生成器:"),
+			refreshPluginsBtn,
+			p.generatorSelect,
+		),
 		widget.NewSeparator(),
 		container.NewHBox(
 			widget.NewLabel(""),
 			p.generateBtn,
+			p.jsonShapeBtn,
+			p.saveDefaultsBtn,
 		),
 		widget.NewSeparator(),
 		widget.NewLabelWithStyle("脚本处理", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
-		tabs,
+		p.scriptTabs,
+		p.paramsContainer,
 	)
 
 	// 创建代码显示区域 - 优化布局，占满窗口
@@ -198,15 +311,31 @@ func (p *GeneratorPage) buildUI() *fyne.Container {
 	// 创建代码容器 - 使用更大的div块来展示代码
 	p.codeContainer = container.NewVBox()
 
-	// 创建右侧面板 - 只显示代码，移除结构选项卡
+	// 创建脚本控制台
+	p.console = widgets.NewConsolePanel()
+
+	// 创建右侧面板 - 显示代码，底部为脚本控制台
 	rightPanel := container.NewBorder(
 		codeHeader,
-		nil,
+		p.console,
 		nil,
 		nil,
 		p.codeContainer, // 直接使用代码容器，不需要额外的滚动容器
 	)
 
+	// 代码、表结构和数据预览分为三个选项卡，切换到数据选项卡时读取第一页
+	p.dataTab = container.NewTabItem("数据", p.dataPreview)
+	p.rightTabs = container.NewAppTabs(
+		container.NewTabItem("代码", rightPanel),
+		container.NewTabItem("表结构", p.tableView),
+		p.dataTab,
+	)
+	p.rightTabs.OnSelected = func(tab *container.TabItem) {
+		if tab == p.dataTab && !p.dataPreview.Loaded() {
+			p.dataPreview.Load()
+		}
+	}
+
 	// 创建分割布局 - 使用固定大小800x600
 	split := container.NewHSplit(
 		container.NewVScroll(
@@ -217,7 +346,7 @@ func (p *GeneratorPage) buildUI() *fyne.Container {
 			nil,
 			nil,
 			nil,
-			rightPanel,
+			p.rightTabs,
 		),
 	)
 	split.Offset = 0.15 // 左侧占15%，右侧占85%，适合固定大小显示
@@ -228,114 +357,643 @@ func (p *GeneratorPage) buildUI() *fyne.Container {
 	return p.container
 }
 
-// onConnectionSelected 处理连接选择事件
-func (p *GeneratorPage) onConnectionSelected(connName string) {
-	// 查找选中的连接配置
-	var selectedConn *ConnectionConfig
-	for _, conn := range p.connections {
-		if conn.Name == connName {
-			selectedConn = conn
-			break
-		}
+// connectionLabel 返回连接在选择器中的显示名称，包含环境和分组
+func connectionLabel(conn *ConnectionConfig) string {
+	label := conn.Name
+	if conn.Group != "" {
+		label = strings.ReplaceAll(conn.Group, "/", " / ") + " / " + label
+	}
+	if conn.Environment != "" {
+		label = "[" + widgets.EnvironmentLabel(conn.Environment) + "] " + label
+	}
+	return label
+}
+
+// onConnectionSelected 处理连接选择事件，生产环境连接需要先确认
+func (p *GeneratorPage) onConnectionSelected(label string) {
+	if label == p.activeLabel {
+		return
 	}
 
+	selectedConn := p.selectedConnection()
 	if selectedConn == nil {
 		return
 	}
 
+	confirmProduction(selectedConn.Name, selectedConn.Environment, "连接", fyne.CurrentApp().Driver().AllWindows()[0], func(confirm bool) {
+		if !confirm {
+			// 恢复之前的选择，直接赋值避免再次触发选择事件
+			p.connectionSelect.Selected = p.activeLabel
+			p.connectionSelect.Refresh()
+			return
+		}
+		p.activeLabel = label
+		p.environmentBadge.SetEnvironment(selectedConn.Environment)
+		p.connect(selectedConn)
+	})
+}
+
+// connect 连接选中的数据库并加载数据库列表
+func (p *GeneratorPage) connect(selectedConn *ConnectionConfig) {
+	// 间接引用无法解析时不能连接
+	if selectedConn.ResolveError != nil {
+		p.log.Errorf("解析连接引用失败: %v", selectedConn.ResolveError)
+		dialog.ShowError(selectedConn.ResolveError, fyne.CurrentApp().Driver().AllWindows()[0])
+		return
+	}
+
 	// 关闭之前的连接
 	if p.processor != nil {
 		p.processor.Close()
+		p.processor = nil
 	}
 
-	// 创建连接器
-	conn, err := connector.NewConnector(&connector.ConnectionConfig{
-		Type:     selectedConn.Type,
-		Host:     selectedConn.Host,
-		Port:     selectedConn.Port,
-		Username: selectedConn.Username,
-		Password: selectedConn.Password,
-		Database: selectedConn.Database,
-	})
+	// 清空数据库和表选择器
+	p.databases = []string{}
+	p.databaseSelect.Options = []string{}
+	p.databaseSelect.Selected = ""
+	p.databaseSelect.Disable()
+	p.databaseSelect.Refresh()
+	p.tables = nil
+	p.tableSelect.Options = []string{}
+	p.tableSelect.Disable()
+	p.tableSelect.Refresh()
+	p.kindSelect.Options = []string{allKindsOption}
+	p.kindSelect.Selected = allKindsOption
+	p.kindSelect.Disable()
+	p.kindSelect.Refresh()
+	p.routines = nil
+	p.routineSelect.Options = []string{}
+	p.routineSelect.Disable()
+	p.routineSelect.Refresh()
+
+	// 禁用生成按钮
+	p.generateBtn.Disable()
+	p.jsonShapeBtn.Disable()
+	p.saveDefaultsBtn.Disable()
+	p.dataPreview.Reset(false)
+	p.previewTable = ""
 
+	// 创建连接器
+	conn, err := dialect.NewConnector(selectedConn.ConnectorConfig())
 	if err != nil {
 		p.log.Errorf("创建连接器失败: %v", err)
 		dialog.ShowError(err, fyne.CurrentApp().Driver().AllWindows()[0])
 		return
 	}
 
-	// 连接数据库
-	_, err = conn.Connect()
-	if err != nil {
-		p.log.Errorf("连接数据库失败: %v", err)
-		dialog.ShowError(err, fyne.CurrentApp().Driver().AllWindows()[0])
-		return
+	// 在后台连接数据库并获取数据库列表，用户可以取消
+	var databases []string
+	p.runCancellable("连接数据库", func(ctx context.Context) error {
+		if _, err := conn.Connect(ctx); err != nil {
+			return err
+		}
+		var err error
+		databases, err = conn.GetDatabases(ctx)
+		if err != nil {
+			conn.Close()
+		}
+		return err
+	}, func() {
+		// 创建元数据处理器
+		p.processor = metadata.NewProcessor(conn)
+
+		// 更新数据库选择器
+		p.databases = databases
+		p.databaseSelect.Options = databases
+		p.databaseSelect.Enable()
+		p.databaseSelect.Refresh()
+		p.saveDefaultsBtn.Enable()
+
+		// 只列出该连接的查询
+		p.refreshQueries()
+
+		// 应用连接的生成器默认设置
+		p.applyGeneratorDefaults(selectedConn)
+	}, func() {
+		// 连接失败或取消时清空选择，以便重新选择同一连接重试
+		p.activeLabel = ""
+		p.connectionSelect.Selected = ""
+		p.connectionSelect.Refresh()
+		p.environmentBadge.SetEnvironment("")
+	})
+}
+
+// runCancellable 在后台执行可能耗时的数据库操作，期间显示可以取消的进度对话框
+// 成功时调用 onSuccess；失败时显示错误，用户取消时只记录日志，两种情况都会调用 onFailure（可以为空）
+func (p *GeneratorPage) runCancellable(action string, task func(ctx context.Context) error, onSuccess, onFailure func()) {
+	w := fyne.CurrentApp().Driver().AllWindows()[0]
+	ctx, cancel := context.WithCancel(context.Background())
+
+	progress := widget.NewProgressBarInfinite()
+	content := container.NewVBox(widget.NewLabel("正在"+action+"..."), progress)
+	progressDialog := dialog.NewCustom("请稍候", "取消", content, w)
+	progressDialog.SetOnClosed(cancel)
+	progressDialog.Show()
+
+	go func() {
+		err := task(ctx)
+		progress.Stop()
+		progressDialog.Hide()
+
+		switch {
+		case err == nil:
+			onSuccess()
+			return
+		case errors.Is(err, context.Canceled):
+			p.log.Infof("已取消%s", action)
+		default:
+			p.log.Errorf("%s失败: %v", action, err)
+			dialog.ShowError(err, w)
+		}
+		if onFailure != nil {
+			onFailure()
+		}
+	}()
+}
+
+// applyGeneratorDefaults 应用连接保存的生成器、脚本、数据库和参数默认设置
+func (p *GeneratorPage) applyGeneratorDefaults(conn *ConnectionConfig) {
+	p.connectionParams = nil
+	defaults := conn.GeneratorDefaults
+	option := ""
+	if defaults != nil && strings.HasPrefix(defaults.Generator, "plugin:") {
+		option = pluginOptionPrefix + strings.TrimPrefix(defaults.Generator, "plugin:")
 	}
 
-	// 创建元数据处理器
-	p.processor = metadata.NewProcessor(conn)
+	// 扫描未完成时由 setPlugins 选择插件；检查和记录在同一次加锁中进行，不会错过扫描完成的时刻
+	p.pluginsMu.Lock()
+	loaded := p.pluginsLoaded
+	options := p.generatorSelect.Options
+	p.pendingPlugin = ""
+	if !loaded {
+		p.pendingPlugin = option
+	}
+	p.pluginsMu.Unlock()
 
-	// 获取数据库列表
-	databases, err := p.processor.GetDatabases()
-	if err != nil {
-		p.log.Errorf("获取数据库列表失败: %v", err)
-		dialog.ShowError(err, fyne.CurrentApp().Driver().AllWindows()[0])
+	if defaults == nil {
 		return
 	}
+	p.connectionParams = defaults.Params
 
-	// 更新数据库选择器
-	p.databases = databases
-	p.databaseSelect.Options = databases
-	p.databaseSelect.Enable()
-	p.databaseSelect.Refresh()
+	switch {
+	case option != "":
+		if !loaded {
+			break
+		}
+		for _, existing := range options {
+			if existing == option {
+				p.generatorSelect.SetSelected(option)
+				break
+			}
+		}
+		if p.generatorSelect.Selected != option {
+			p.log.Warnf("连接 %s 的默认插件不存在: %s", conn.Name, defaults.Generator)
+		}
+	case strings.HasPrefix(defaults.Generator, "script:"):
+		p.generatorSelect.SetSelected(builtinGenerator)
+		if err := p.setLibraryScript(strings.TrimPrefix(defaults.Generator, "script:")); err != nil {
+			p.log.Warnf("加载连接 %s 的默认脚本失败: %v", conn.Name, err)
+		}
+	case defaults.Generator != "":
+		p.generatorSelect.SetSelected(builtinGenerator)
+	}
 
-	// 清空表选择器
-	p.tables = []string{}
-	p.tableSelect.Options = []string{}
-	p.tableSelect.Disable()
-	p.tableSelect.Refresh()
+	// 参数表单已经存在时使用连接的默认参数重建
+	if len(p.scriptParams) > 0 {
+		p.buildParamsForm(nil)
+	}
 
-	// 禁用生成按钮
-	p.generateBtn.Disable()
+	if defaults.Database != "" {
+		for _, database := range p.databases {
+			if database == defaults.Database {
+				p.databaseSelect.SetSelected(database)
+				break
+			}
+		}
+	}
 }
 
-// onDatabaseSelected 处理数据库选择事件
-func (p *GeneratorPage) onDatabaseSelected(dbName string) {
-	if p.processor == nil {
+// onSaveDefaultsClicked 将当前的生成器、脚本、数据库和参数保存为连接的默认设置
+func (p *GeneratorPage) onSaveDefaultsClicked() {
+	w := fyne.CurrentApp().Driver().AllWindows()[0]
+	conn := p.selectedConnection()
+	if conn == nil {
 		return
 	}
 
-	// 获取表列表
-	tables, err := p.processor.GetTables(dbName)
-	if err != nil {
-		p.log.Errorf("获取表列表失败: %v", err)
-		dialog.ShowError(err, fyne.CurrentApp().Driver().AllWindows()[0])
+	defaults := &config.GeneratorDefaults{Generator: "builtin"}
+	if conn.GeneratorDefaults != nil {
+		// 保留界面上无法设置的字段
+		defaults.Template = conn.GeneratorDefaults.Template
+		defaults.OutputDir = conn.GeneratorDefaults.OutputDir
+	}
+	if selected := p.selectedPlugin(); selected != nil {
+		defaults.Generator = "plugin:" + selected.Name
+	} else if p.currentScriptName != "" && p.storage.Library().Exists(config.AssetScript, p.currentScriptName) {
+		defaults.Generator = "script:" + p.currentScriptName
+	}
+	defaults.Database = p.databaseSelect.Selected
+	if values := p.collectParamValues(); len(values) > 0 {
+		defaults.Params = values
+	}
+
+	if err := p.storage.SetGeneratorDefaults(conn.Name, defaults); err != nil {
+		p.log.Errorf("保存连接默认设置失败: %v", err)
+		dialog.ShowError(err, w)
 		return
 	}
+	conn.GeneratorDefaults = defaults
+	p.connectionParams = defaults.Params
 
-	// 更新表选择器
-	p.tables = tables
-	p.tableSelect.Options = tables
-	p.tableSelect.Enable()
-	p.tableSelect.Refresh()
+	p.log.Infof("已保存连接 %s 的默认生成器: %s", conn.Name, defaults.Generator)
+	dialog.ShowInformation("保存成功", fmt.Sprintf("已将当前设置保存为连接 %s 的默认设置", conn.Name), w)
+}
+
+// outputLocation 返回连接默认输出目录，用作保存对话框的初始位置，未设置或不存在时返回 nil
+func (p *GeneratorPage) outputLocation() fyne.ListableURI {
+	conn := p.selectedConnection()
+	if conn == nil || conn.GeneratorDefaults == nil || conn.GeneratorDefaults.OutputDir == "" {
+		return nil
+	}
+	location, err := fynestorage.ListerForURI(fynestorage.NewFileURI(conn.GeneratorDefaults.OutputDir))
+	if err != nil {
+		return nil
+	}
+	return location
+}
+
+// onDatabaseSelected 处理数据库选择事件
+func (p *GeneratorPage) onDatabaseSelected(dbName string) {
+	if p.processor == nil || dbName == "" {
+		return
+	}
 
-	// 清空选择
+	// 清空表和例程选择
 	p.tableSelect.SetSelected("")
+	p.tableSelect.Disable()
 	p.selectedTable = ""
+	p.routineSelect.SetSelected("")
+	p.routineSelect.Disable()
+	p.selectedRoutine = ""
 	p.generateBtn.Disable()
+	p.jsonShapeBtn.Disable()
+
+	// 在后台获取表列表和例程列表，用户可以取消
+	processor := p.processor
+	var tables []connector.TableInfo
+	var routines []connector.Routine
+	p.runCancellable("获取表列表", func(ctx context.Context) error {
+		var err error
+		tables, err = processor.GetTables(ctx, dbName)
+		if err != nil || !processor.SupportsRoutines() {
+			return err
+		}
+
+		// 读取例程失败（例如没有权限）不影响表的使用
+		routines, err = processor.GetRoutines(ctx, dbName)
+		if err != nil && ctx.Err() == nil {
+			p.log.Warnf("获取存储过程和函数失败: %v", err)
+			routines = nil
+			return nil
+		}
+		return err
+	}, func() {
+		p.tables = tables
+		p.routines = routines
+
+		// 更新例程选择器，没有例程时保持禁用
+		labels := make([]string, 0, len(routines))
+		for _, routine := range routines {
+			labels = append(labels, routine.Signature)
+		}
+		p.routineSelect.Options = labels
+		if len(labels) > 0 {
+			p.routineSelect.Enable()
+		}
+		p.routineSelect.Refresh()
+
+		// 筛选器只列出当前数据库中存在的对象类型
+		present := make(map[connector.ObjectKind]bool)
+		for _, table := range tables {
+			present[table.Kind] = true
+		}
+		options := []string{allKindsOption}
+		for _, kind := range connector.ObjectKinds {
+			if present[kind] {
+				options = append(options, widgets.ObjectKindLabel(kind))
+			}
+		}
+		p.kindSelect.Options = options
+		p.kindSelect.Enable()
+
+		// 更新表选择器
+		p.kindSelect.SetSelected(allKindsOption)
+		p.tableSelect.Enable()
+		p.filterTables()
+	}, nil)
+}
+
+// onKindSelected 处理对象类型筛选事件
+func (p *GeneratorPage) onKindSelected(string) {
+	p.filterTables()
 }
 
-// onTableSelected 处理表选择事件
+// filterTables 按选择的对象类型更新表选择器，选中的表被筛选掉时清空选择
+func (p *GeneratorPage) filterTables() {
+	names := make([]string, 0, len(p.tables))
+	for _, table := range p.tables {
+		if p.kindSelect.Selected == allKindsOption || p.kindSelect.Selected == widgets.ObjectKindLabel(table.Kind) {
+			names = append(names, table.Name)
+		}
+	}
+	p.tableSelect.Options = names
+	p.tableSelect.Refresh()
+
+	for _, name := range names {
+		if name == p.selectedTable {
+			return
+		}
+	}
+	p.tableSelect.ClearSelected()
+}
+
+// onTableSelected 处理表选择事件，选择表时清空例程和查询选择
+// 数据预览切换到新选择的表，正在显示数据选项卡时立即读取
 func (p *GeneratorPage) onTableSelected(tableName string) {
 	p.selectedTable = tableName
 	if tableName != "" {
+		p.routineSelect.ClearSelected()
+		p.querySelect.ClearSelected()
+	}
+	p.updateGenerateButton()
+
+	p.dataPreview.Reset(tableName != "" && p.processor != nil && p.processor.SupportsPreview())
+	if tableName != "" && p.rightTabs.Selected() == p.dataTab {
+		p.dataPreview.Load()
+	}
+}
+
+// loadPreview 在后台读取选中表的一页数据，第一次读取某个表时先读取主键作为排序列
+func (p *GeneratorPage) loadPreview(request connector.PreviewRequest) {
+	if p.processor == nil || p.selectedTable == "" || p.databaseSelect.Selected == "" {
+		return
+	}
+
+	processor := p.processor
+	database, table := p.databaseSelect.Selected, p.selectedTable
+	orderBy, known := p.previewOrderBy, p.previewTable == database+"."+table
+	var page *connector.PreviewPage
+	p.runCancellable("读取数据", func(ctx context.Context) error {
+		if !known {
+			metadata, err := processor.GetTableMetadata(ctx, database, table)
+			if err != nil {
+				return err
+			}
+			orderBy = nil
+			for _, field := range metadata.Fields {
+				if field.IsPrimary {
+					orderBy = append(orderBy, field.Name)
+				}
+			}
+		}
+		request.OrderBy = orderBy
+
+		var err error
+		page, err = processor.PreviewRows(ctx, database, table, request)
+		return err
+	}, func() {
+		p.previewTable, p.previewOrderBy = database+"."+table, orderBy
+		p.dataPreview.SetPage(page, request.Offset)
+	}, nil)
+}
+
+// onRoutineSelected 处理存储过程和函数选择事件，选择例程时清空表和查询选择
+func (p *GeneratorPage) onRoutineSelected(signature string) {
+	p.selectedRoutine = signature
+	if signature != "" {
+		p.tableSelect.ClearSelected()
+		p.querySelect.ClearSelected()
+	}
+	p.updateGenerateButton()
+}
+
+// onQuerySelected 处理已保存查询的选择事件，选择查询时清空表和例程选择
+func (p *GeneratorPage) onQuerySelected(name string) {
+	p.selectedQuery = name
+	if name != "" {
+		p.tableSelect.ClearSelected()
+		p.routineSelect.ClearSelected()
+		p.queryEditBtn.Enable()
+		p.queryDeleteBtn.Enable()
+	} else {
+		p.queryEditBtn.Disable()
+		p.queryDeleteBtn.Disable()
+	}
+	p.updateGenerateButton()
+}
+
+// refreshQueries 按当前连接更新查询选择器，选中的查询不存在时清空选择
+func (p *GeneratorPage) refreshQueries() {
+	connectionName := ""
+	if conn := p.selectedConnection(); conn != nil {
+		connectionName = conn.Name
+	}
+
+	names := make([]string, 0)
+	for _, query := range p.storage.GetQueries() {
+		if query.Connection == "" || query.Connection == connectionName {
+			names = append(names, query.Name)
+		}
+	}
+	sort.Strings(names)
+	p.querySelect.Options = names
+	p.querySelect.Refresh()
+
+	for _, name := range names {
+		if name == p.selectedQuery {
+			return
+		}
+	}
+	p.querySelect.ClearSelected()
+	p.onQuerySelected("")
+}
+
+// onQueryEditClicked 编辑选中的查询
+func (p *GeneratorPage) onQueryEditClicked() {
+	if query, ok := p.storage.FindQuery(p.selectedQuery); ok {
+		p.showQueryEditor(&query)
+	}
+}
+
+// onQueryDeleteClicked 确认后删除选中的查询
+func (p *GeneratorPage) onQueryDeleteClicked() {
+	name := p.selectedQuery
+	if name == "" {
+		return
+	}
+	w := fyne.CurrentApp().Driver().AllWindows()[0]
+	dialog.ShowConfirm("确认删除", fmt.Sprintf("确定要删除查询 '%s' 吗？", name), func(ok bool) {
+		if !ok {
+			return
+		}
+		if err := p.storage.DeleteQuery(name); err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		p.refreshQueries()
+	}, w)
+}
+
+// showQueryEditor 显示查询编辑对话框，existing 为空时新建查询
+// 保存的查询记录当前的连接和数据库
+func (p *GeneratorPage) showQueryEditor(existing *config.SavedQuery) {
+	w := fyne.CurrentApp().Driver().AllWindows()[0]
+
+	nameEntry := widget.NewEntry()
+	nameEntry.SetPlaceHolder("结果接口名称，例如 UserOrders")
+	sqlEntry := widget.NewMultiLineEntry()
+	sqlEntry.SetPlaceHolder("SELECT ... WHERE id = ?\n\nPostgreSQL 使用 $1、$2，SQL Server 使用 @名称 作为参数")
+	sqlEntry.Wrapping = fyne.TextWrapOff
+	sqlEntry.SetMinRowsVisible(12)
+
+	title := "新建查询"
+	if existing != nil {
+		title = "编辑查询"
+		nameEntry.SetText(existing.Name)
+		sqlEntry.SetText(existing.SQL)
+	}
+
+	form := container.NewBorder(
+		container.NewVBox(widget.NewLabel("名称:"), nameEntry, widget.NewLabel("SQL:")),
+		nil,
+		nil,
+		nil,
+		sqlEntry,
+	)
+
+	editor := dialog.NewCustomConfirm(title, "保存", "取消", form, func(ok bool) {
+		if !ok {
+			return
+		}
+
+		query := config.SavedQuery{
+			Name:     strings.TrimSpace(nameEntry.Text),
+			SQL:      sqlEntry.Text,
+			Database: p.databaseSelect.Selected,
+		}
+		if existing != nil && query.Database == "" {
+			query.Database = existing.Database
+		}
+		if conn := p.selectedConnection(); conn != nil {
+			query.Connection = conn.Name
+		} else if existing != nil {
+			query.Connection = existing.Connection
+		}
+		if err := p.storage.SaveQuery(query); err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+
+		// 修改了名称时删除原来的查询
+		if existing != nil && existing.Name != query.Name {
+			if err := p.storage.DeleteQuery(existing.Name); err != nil {
+				p.log.Warnf("删除查询 %s 失败: %v", existing.Name, err)
+			}
+		}
+
+		p.refreshQueries()
+		p.querySelect.SetSelected(query.Name)
+	}, w)
+	editor.Resize(fyne.NewSize(640, 480))
+	editor.Show()
+}
+
+// updateGenerateButton 选择了表、例程或查询时启用生成按钮，选择了表且数据库支持读取样本数据时启用推断JSON结构按钮
+func (p *GeneratorPage) updateGenerateButton() {
+	if p.selectedTable != "" || p.selectedRoutine != "" || p.selectedQuery != "" {
 		p.generateBtn.Enable()
 	} else {
 		p.generateBtn.Disable()
 	}
+	if p.selectedTable != "" && p.processor != nil && p.processor.SupportsSampling() {
+		p.jsonShapeBtn.Enable()
+	} else {
+		p.jsonShapeBtn.Disable()
+	}
+}
+
+// refreshPlugins 重新与插件目录中的插件握手并更新生成器选择器
+func (p *GeneratorPage) refreshPlugins() {
+	p.loadPlugins(p.pluginManager.Refresh)
+}
+
+// loadPlugins 在后台调用 discover 扫描插件，完成后更新生成器选择器
+// 握手需要启动每个插件，因此不在界面线程中进行
+func (p *GeneratorPage) loadPlugins(discover func() ([]*plugin.Plugin, error)) {
+	go func() {
+		plugins, err := discover()
+		if err != nil {
+			p.log.Warnf("加载插件失败: %v", err)
+		}
+		p.setPlugins(plugins)
+	}()
+}
+
+// setPlugins 更新可选择的插件，之前选择的插件不存在时恢复为内置生成器
+func (p *GeneratorPage) setPlugins(plugins []*plugin.Plugin) {
+	options := []string{builtinGenerator}
+	for _, plugin := range plugins {
+		options = append(options, pluginOptionPrefix+plugin.Name)
+	}
+
+	// 选择插件会触发选择器的回调，因此在解锁后进行
+	p.pluginsMu.Lock()
+	p.plugins = plugins
+	p.pluginsLoaded = true
+	selected := p.generatorSelect.Selected
+	if p.pendingPlugin != "" {
+		// 插件加载完成前选择的连接默认使用该插件
+		selected, p.pendingPlugin = p.pendingPlugin, ""
+	}
+	p.generatorSelect.Options = options
+	p.pluginsMu.Unlock()
+	p.generatorSelect.Refresh()
+
+	for _, option := range options {
+		if option == selected {
+			p.generatorSelect.SetSelected(option)
+			return
+		}
+	}
+	p.generatorSelect.SetSelected(builtinGenerator)
+}
+
+// selectedPlugin 返回当前选择的插件，选择内置生成器时返回 nil
+func (p *GeneratorPage) selectedPlugin() *plugin.Plugin {
+	name := strings.TrimPrefix(p.generatorSelect.Selected, pluginOptionPrefix)
+	if name == p.generatorSelect.Selected {
+		return nil
+	}
+	p.pluginsMu.Lock()
+	defer p.pluginsMu.Unlock()
+	for _, plugin := range p.plugins {
+		if plugin.Name == name {
+			return plugin
+		}
+	}
+	return nil
 }
 
-// 删除不再需要的函数
+// selectedConnection 返回当前选择的连接配置
+func (p *GeneratorPage) selectedConnection() *ConnectionConfig {
+	for _, conn := range p.connections {
+		if connectionLabel(conn) == p.connectionSelect.Selected {
+			return conn
+		}
+	}
+	return nil
+}
 
 // onGenerateClicked 处理生成按钮点击事件
 func (p *GeneratorPage) onGenerateClicked() {
@@ -344,53 +1002,278 @@ func (p *GeneratorPage) onGenerateClicked() {
 
 // generateCode 生成TypeScript代码
 func (p *GeneratorPage) generateCode() {
+	if p.selectedRoutine != "" {
+		p.generateRoutine()
+		return
+	}
+	if p.selectedQuery != "" {
+		p.generateQuery()
+		return
+	}
 	if p.processor == nil || p.selectedTable == "" || p.databaseSelect.Selected == "" {
 		return
 	}
 
-	// 获取表元数据
-	metadata, err := p.processor.GetTableMetadata(p.databaseSelect.Selected, p.selectedTable)
-	if err != nil {
-		p.log.Errorf("获取表元数据失败: %v", err)
-		dialog.ShowError(err, fyne.CurrentApp().Driver().AllWindows()[0])
+	// 在后台获取表元数据，用户可以取消
+	processor := p.processor
+	database, table := p.databaseSelect.Selected, p.selectedTable
+	var metadata *connector.TableMetadata
+	p.runCancellable("获取表结构", func(ctx context.Context) error {
+		var err error
+		metadata, err = processor.GetTableMetadata(ctx, database, table)
+		return err
+	}, func() {
+		p.generateFromMetadata(metadata)
+	}, nil)
+}
+
+// generateQuery 分析选中的查询的结果列和参数，然后使用查询模板生成代码
+func (p *GeneratorPage) generateQuery() {
+	w := fyne.CurrentApp().Driver().AllWindows()[0]
+	if p.processor == nil || p.databaseSelect.Selected == "" {
+		dialog.ShowError(fmt.Errorf("请先选择数据库"), w)
+		return
+	}
+	if !p.processor.SupportsQueries() {
+		dialog.ShowError(fmt.Errorf("%s 不支持分析查询", p.selectedConnection().Type), w)
+		return
+	}
+	query, ok := p.storage.FindQuery(p.selectedQuery)
+	if !ok {
 		return
 	}
 
+	// 在后台分析查询，用户可以取消
+	processor, database := p.processor, p.databaseSelect.Selected
+	var metadata *connector.TableMetadata
+	p.runCancellable("分析查询", func(ctx context.Context) error {
+		var err error
+		metadata, err = processor.DescribeQuery(ctx, database, query.Name, query.SQL)
+		return err
+	}, func() {
+		p.generateFromMetadata(metadata)
+	}, nil)
+}
+
+// generateFromMetadata 使用表元数据生成代码
+func (p *GeneratorPage) generateFromMetadata(metadata *connector.TableMetadata) {
 	// 保存元数据用于表视图
 	p.currentMetadata = metadata
 
 	// 更新表视图
 	p.tableView.SetMetadata(metadata)
 
-	// 始终使用默认模板
+	// 选择了插件时由插件生成代码
+	if selected := p.selectedPlugin(); selected != nil {
+		if metadata.Kind == connector.KindQuery {
+			p.generateQueryWithPlugin(selected, metadata)
+		} else {
+			p.generateWithPlugin(selected)
+		}
+		return
+	}
+	p.generatedFiles = nil
+
+	// 表使用默认模板，查询使用查询模板
 	templateStr := generator.DefaultTemplate()
+	if metadata.Kind == connector.KindQuery {
+		templateStr = generator.DefaultQueryTemplate()
+	}
 
-	// 创建生成器
-	gen, err := generator.NewGenerator(p.connectionSelect.Selected, templateStr, p.log)
+	// 按连接的数据库类型创建生成器
+	mapper, err := dialect.NewTypeMapper(p.selectedConnection().Type)
+	if err != nil {
+		p.log.Errorf("创建类型映射器失败: %v", err)
+		dialog.ShowError(err, fyne.CurrentApp().Driver().AllWindows()[0])
+		return
+	}
+	gen, err := generator.NewGenerator(mapper, templateStr, p.log)
 	if err != nil {
 		p.log.Errorf("创建生成器失败: %v", err)
 		dialog.ShowError(err, fyne.CurrentApp().Driver().AllWindows()[0])
 		return
 	}
 
+	// 使用为表的JSON列保存的结构
+	if metadata.Kind != connector.KindQuery {
+		gen.SetJSONShapes(p.storage.GetJSONShapes(p.selectedConnection().Name, p.databaseSelect.Selected, metadata.Name))
+	}
+
 	// 设置JavaScript脚本（每次生成时都重新设置，确保实时更新）
 	if p.scriptEditor.Text != "" {
 		gen.SetScript(p.scriptEditor.Text)
+
+		// 设置脚本参数，并记住本次使用的参数值
+		values := p.collectParamValues()
+		gen.SetScriptParams(values)
+		if p.currentScriptName != "" && len(values) > 0 {
+			if err := p.storage.SetScriptParamValues(p.currentScriptName, values); err != nil {
+				p.log.Warnf("保存脚本参数失败: %v", err)
+			}
+		}
 	}
 
 	// 生成代码
 	code, err := gen.Generate(metadata)
+	if p.scriptEditor.Text != "" {
+		// 在控制台显示本次脚本运行的输出和错误
+		p.console.ShowRun(gen.ConsoleLogs(), err)
+	}
 	if err != nil {
 		p.log.Errorf("生成代码失败: %v", err)
-		// 检查是否是JavaScript脚本执行错误
-		if strings.Contains(err.Error(), "JavaScript执行错误") {
-			dialog.ShowError(fmt.Errorf("JavaScript脚本执行失败: %v\n请检查脚本语法是否正确", err), fyne.CurrentApp().Driver().AllWindows()[0])
-		} else {
-			dialog.ShowError(err, fyne.CurrentApp().Driver().AllWindows()[0])
+		// 脚本错误显示在控制台中，并在编辑器中标记出错行
+		var scriptErr *generator.ScriptError
+		if errors.As(err, &scriptErr) {
+			p.scriptTabs.SelectIndex(0)
+			widgets.HighlightLine(p.scriptEditor, scriptErr.Line)
+			return
+		}
+		dialog.ShowError(err, fyne.CurrentApp().Driver().AllWindows()[0])
+		return
+	}
+
+	p.showGeneratedCode(code)
+}
+
+// generateRoutine 使用默认的例程模板为选中的存储过程或函数生成参数、结果接口和调用函数
+// 例程不运行脚本；选择了插件时由插件生成
+func (p *GeneratorPage) generateRoutine() {
+	w := fyne.CurrentApp().Driver().AllWindows()[0]
+
+	var routine *connector.Routine
+	for i := range p.routines {
+		if p.routines[i].Signature == p.selectedRoutine {
+			routine = &p.routines[i]
+			break
+		}
+	}
+	if routine == nil {
+		return
+	}
+
+	dbType := p.selectedConnection().Type
+	mapper, err := dialect.NewTypeMapper(dbType)
+	if err != nil {
+		p.log.Errorf("创建类型映射器失败: %v", err)
+		dialog.ShowError(err, w)
+		return
+	}
+
+	// 选择了插件时在后台运行插件，请求中只有选中的例程
+	if selected := p.selectedPlugin(); selected != nil {
+		request := &plugin.Request{
+			Kind:         plugin.InputRoutine,
+			DatabaseType: dbType,
+			Database:     p.databaseSelect.Selected,
+			Routines:     []plugin.Routine{plugin.NewRoutine(routine, mapper)},
+			Generate:     []string{routine.Signature},
 		}
+		go p.runPlugin(selected, request)
+		return
+	}
+
+	gen, err := generator.NewGenerator(mapper, generator.DefaultRoutineTemplate(), p.log)
+	if err != nil {
+		p.log.Errorf("创建生成器失败: %v", err)
+		dialog.ShowError(err, w)
+		return
+	}
+
+	code, err := gen.GenerateRoutine(routine)
+	if err != nil {
+		p.log.Errorf("生成代码失败: %v", err)
+		dialog.ShowError(err, w)
 		return
 	}
 
+	p.generatedFiles = []plugin.File{{Name: routine.Name + ".ts", Content: code}}
+	p.showGeneratedCode(code)
+}
+
+// generateWithPlugin 使用外部插件生成代码
+// 请求中包含当前数据库所有表的结构，插件只需为选中的表生成代码
+func (p *GeneratorPage) generateWithPlugin(selected *plugin.Plugin) {
+	database := p.databaseSelect.Selected
+
+	dbType := p.selectedConnection().Type
+	mapper, err := dialect.NewTypeMapper(dbType)
+	if err != nil {
+		p.log.Errorf("创建类型映射器失败: %v", err)
+		dialog.ShowError(err, fyne.CurrentApp().Driver().AllWindows()[0])
+		return
+	}
+
+	request := &plugin.Request{
+		Kind:         plugin.InputTable,
+		DatabaseType: dbType,
+		Database:     database,
+		Tables:       make([]plugin.Table, 0, len(p.tables)),
+		Generate:     []string{p.selectedTable},
+	}
+
+	// 在后台获取所有表的元数据，用户可以取消
+	processor, tables := p.processor, connector.TableNames(p.tables)
+	p.runCancellable("获取所有表结构", func(ctx context.Context) error {
+		for _, table := range tables {
+			metadata, err := processor.GetTableMetadata(ctx, database, table)
+			if err != nil {
+				return fmt.Errorf("获取表 %s 的元数据失败: %w", table, err)
+			}
+			request.Tables = append(request.Tables, plugin.NewTable(metadata, mapper))
+		}
+		return nil
+	}, func() {
+		p.runPlugin(selected, request)
+	}, nil)
+}
+
+// generateQueryWithPlugin 使用外部插件为查询生成代码，请求中只有查询的结果结构和参数
+func (p *GeneratorPage) generateQueryWithPlugin(selected *plugin.Plugin, metadata *connector.TableMetadata) {
+	dbType := p.selectedConnection().Type
+	mapper, err := dialect.NewTypeMapper(dbType)
+	if err != nil {
+		p.log.Errorf("创建类型映射器失败: %v", err)
+		dialog.ShowError(err, fyne.CurrentApp().Driver().AllWindows()[0])
+		return
+	}
+
+	p.runPlugin(selected, &plugin.Request{
+		Kind:         plugin.InputQuery,
+		DatabaseType: dbType,
+		Database:     p.databaseSelect.Selected,
+		Tables:       []plugin.Table{plugin.NewTable(metadata, mapper)},
+		Generate:     []string{metadata.Name},
+	})
+}
+
+// runPlugin 运行插件并显示生成的代码
+func (p *GeneratorPage) runPlugin(selected *plugin.Plugin, request *plugin.Request) {
+	result, err := p.pluginManager.Generate(selected, request)
+	if err != nil {
+		p.log.Errorf("插件生成代码失败: %v", err)
+		p.console.ShowStderr("", err)
+		return
+	}
+	p.console.ShowStderr(result.Stderr, nil)
+
+	// 多个文件合并显示，每个文件前标注文件名
+	var code strings.Builder
+	for i, file := range result.Files {
+		if len(result.Files) > 1 {
+			if i > 0 {
+				code.WriteString("\n")
+			}
+			fmt.Fprintf(&code, "// ===== %s =====\n", file.Name)
+		}
+		code.WriteString(file.Content)
+	}
+
+	p.generatedFiles = result.Files
+	p.showGeneratedCode(code.String())
+}
+
+// showGeneratedCode 显示生成的代码并启用复制和保存按钮
+func (p *GeneratorPage) showGeneratedCode(code string) {
 	// 保存生成的代码
 	p.generatedCode = code
 
@@ -402,6 +1285,7 @@ func (p *GeneratorPage) generateCode() {
 	highlightedCode := highlighter.HighlightTypeScript(code)
 	p.codeContainer.Add(highlightedCode)
 	p.codeContainer.Refresh()
+	p.rightTabs.SelectIndex(0)
 
 	// 启用复制和保存按钮
 	p.copyBtn.Enable()
@@ -446,7 +1330,9 @@ func (p *GeneratorPage) onScriptLoadClicked() {
 		}
 
 		// 设置脚本编辑器内容
+		p.resetScriptParams(strings.TrimSuffix(reader.URI().Name(), reader.URI().Extension()))
 		p.scriptEditor.SetText(string(content))
+		p.onScriptChanged(p.scriptEditor.Text)
 		p.log.Infof("已加载脚本文件: %s", reader.URI().Path())
 		dialog.ShowInformation("成功", "脚本文件加载成功", w)
 	}, w)
@@ -458,8 +1344,14 @@ func (p *GeneratorPage) onSaveClicked() {
 		return
 	}
 
-	// 创建保存对话框
+	// 插件生成了多个文件时选择目录保存全部文件
 	w := fyne.CurrentApp().Driver().AllWindows()[0]
+	if len(p.generatedFiles) > 1 {
+		p.saveGeneratedFiles(w)
+		return
+	}
+
+	// 创建保存对话框
 	saveDialog := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
 		if err != nil {
 			dialog.ShowError(err, w)
@@ -483,33 +1375,201 @@ func (p *GeneratorPage) onSaveClicked() {
 	}, w)
 
 	// 设置默认文件名
-	saveDialog.SetFileName(p.selectedTable + ".ts")
+	switch {
+	case len(p.generatedFiles) == 1:
+		saveDialog.SetFileName(filepath.Base(filepath.FromSlash(p.generatedFiles[0].Name)))
+	case p.currentMetadata != nil && p.currentMetadata.Kind == connector.KindQuery:
+		saveDialog.SetFileName(p.currentMetadata.Name + ".ts")
+	default:
+		saveDialog.SetFileName(p.selectedTable + ".ts")
+	}
 
-	// 注意：在某些Fyne版本中，SetLocation需要ListableURI，而不是普通URI
-	// 这里我们只设置文件名，不设置位置
+	// 使用连接默认设置中的输出目录作为初始位置
+	if location := p.outputLocation(); location != nil {
+		saveDialog.SetLocation(location)
+	}
 
 	// 显示对话框
 	saveDialog.Show()
 }
 
+// saveGeneratedFiles 选择目录并保存插件生成的所有文件
+func (p *GeneratorPage) saveGeneratedFiles(w fyne.Window) {
+	folderDialog := dialog.NewFolderOpen(func(uri fyne.ListableURI, err error) {
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		if uri == nil {
+			return
+		}
+
+		paths, err := plugin.WriteFiles(uri.Path(), p.generatedFiles)
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+
+		dialog.ShowInformation("保存成功", fmt.Sprintf("已保存 %d 个文件到 %s", len(paths), uri.Path()), w)
+	}, w)
+	if location := p.outputLocation(); location != nil {
+		folderDialog.SetLocation(location)
+	}
+	folderDialog.Show()
+}
+
 // loadCommonScript 加载常用脚本
 func (p *GeneratorPage) loadCommonScript(scriptName string) {
 	if scriptName == "" {
 		return
 	}
 
-	// 从存储中获取脚本内容
-	scripts := p.storage.GetScripts()
-	scriptContent, exists := scripts[scriptName]
-	if !exists {
-		p.log.Errorf("脚本不存在: %s", scriptName)
-		dialog.ShowError(fmt.Errorf("脚本 '%s' 不存在", scriptName), fyne.CurrentApp().Driver().AllWindows()[0])
+	if err := p.setLibraryScript(scriptName); err != nil {
+		p.log.Errorf("%v", err)
+		dialog.ShowError(err, fyne.CurrentApp().Driver().AllWindows()[0])
 		return
 	}
 
+	p.log.Infof("已加载常用脚本: %s", scriptName)
+	dialog.ShowInformation("成功", fmt.Sprintf("已加载脚本: %s", scriptName), fyne.CurrentApp().Driver().AllWindows()[0])
+}
+
+// setLibraryScript 将资源库中的脚本加载到脚本编辑器
+func (p *GeneratorPage) setLibraryScript(scriptName string) error {
+	// 从存储中获取脚本内容
+	scriptContent, err := p.storage.GetScript(scriptName)
+	if err != nil {
+		return fmt.Errorf("脚本 '%s' 不存在", scriptName)
+	}
+
 	// 设置脚本编辑器内容
+	p.resetScriptParams(scriptName)
 	p.scriptEditor.SetText(scriptContent)
+	p.onScriptChanged(p.scriptEditor.Text)
+	return nil
+}
 
-	p.log.Infof("已加载常用脚本: %s", scriptName)
-	dialog.ShowInformation("成功", fmt.Sprintf("已加载脚本: %s", scriptName), fyne.CurrentApp().Driver().AllWindows()[0])
+// onScriptChanged 处理脚本内容变化事件，参数声明变化时重建参数表单
+func (p *GeneratorPage) onScriptChanged(script string) {
+	params, err := generator.ParseScriptParams(script)
+	if err != nil {
+		// 编辑过程中声明可能暂时不完整，保留现有表单
+		return
+	}
+
+	if sameScriptParams(params, p.scriptParams) {
+		return
+	}
+
+	// 保留已输入的值，以便修改声明时不丢失
+	previous := p.collectParamValues()
+	p.scriptParams = params
+	p.buildParamsForm(previous)
+}
+
+// resetScriptParams 切换脚本时清空参数表单，使新脚本加载其上次保存的参数值
+func (p *GeneratorPage) resetScriptParams(scriptName string) {
+	p.currentScriptName = scriptName
+	p.scriptParams = nil
+	p.paramValues = nil
+}
+
+// buildParamsForm 根据脚本参数声明构建参数表单
+func (p *GeneratorPage) buildParamsForm(previous map[string]string) {
+	p.paramValues = make(map[string]func() string)
+	p.paramsContainer.Objects = nil
+
+	if len(p.scriptParams) == 0 {
+		p.paramsContainer.Refresh()
+		return
+	}
+
+	// 参数初始值优先级：本次已输入的值 > 连接的默认参数 > 上次保存的值 > 默认值
+	stored := map[string]string{}
+	if p.currentScriptName != "" {
+		stored = p.storage.GetScriptParamValues(p.currentScriptName)
+	}
+	initial := func(param generator.ScriptParam) string {
+		if value, ok := previous[param.Name]; ok {
+			return value
+		}
+		if value, ok := p.connectionParams[param.Name]; ok {
+			if _, err := param.Convert(value); err == nil {
+				return value
+			}
+		}
+		if value, ok := stored[param.Name]; ok {
+			if _, err := param.Convert(value); err == nil {
+				return value
+			}
+		}
+		return param.Default
+	}
+
+	form := widget.NewForm()
+	for _, param := range p.scriptParams {
+		label := param.Name
+		if param.Label != "" {
+			label = param.Label
+		}
+
+		value := initial(param)
+		switch param.Type {
+		case generator.ParamTypeBool:
+			check := widget.NewCheck("", nil)
+			check.SetChecked(value == "true")
+			p.paramValues[param.Name] = func() string {
+				return fmt.Sprintf("%t", check.Checked)
+			}
+			form.Append(label, check)
+		case generator.ParamTypeEnum:
+			sel := widget.NewSelect(param.Options, nil)
+			sel.SetSelected(value)
+			p.paramValues[param.Name] = func() string {
+				return sel.Selected
+			}
+			form.Append(label, sel)
+		default:
+			entry := widget.NewEntry()
+			entry.SetText(value)
+			if param.Type == generator.ParamTypeNumber {
+				current := param
+				entry.Validator = func(text string) error {
+					_, err := current.Convert(text)
+					return err
+				}
+			}
+			p.paramValues[param.Name] = func() string {
+				return entry.Text
+			}
+			form.Append(label, entry)
+		}
+	}
+
+	p.paramsContainer.Add(widget.NewLabelWithStyle("脚本参数", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}))
+	p.paramsContainer.Add(form)
+	p.paramsContainer.Refresh()
+}
+
+// collectParamValues 收集参数表单中的当前值
+func (p *GeneratorPage) collectParamValues() map[string]string {
+	values := make(map[string]string, len(p.paramValues))
+	for name, get := range p.paramValues {
+		values[name] = get()
+	}
+	return values
+}
+
+// sameScriptParams 判断两组参数声明是否相同
+func sameScriptParams(a, b []generator.ScriptParam) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Name != b[i].Name || a[i].Type != b[i].Type || a[i].Default != b[i].Default ||
+			a[i].Label != b[i].Label || strings.Join(a[i].Options, "|") != strings.Join(b[i].Options, "|") {
+			return false
+		}
+	}
+	return true
 }
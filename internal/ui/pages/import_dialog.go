@@ -0,0 +1,188 @@
+package pages
+
+import (
+	"fmt"
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"go-DBmodeler/internal/config"
+	"go-DBmodeler/internal/importer"
+	"strings"
+)
+
+// 同名连接的处理方式选项
+var conflictOptions = []string{"以新名称导入", "覆盖现有连接", "跳过"}
+
+// conflictResolutions 与 conflictOptions 一一对应
+var conflictResolutions = map[string]config.ConflictResolution{
+	"以新名称导入": config.ConflictRename,
+	"覆盖现有连接": config.ConflictOverwrite,
+	"跳过":     config.ConflictSkip,
+}
+
+// showImportConnectionsDialog 显示导入连接对话框
+// 支持导出的共享连接文件、DBeaver 的 data-sources.json、DataGrip 的 dataSources.xml、.env 文件和直接输入的连接URL
+func (p *ConnectionPage) showImportConnectionsDialog(win fyne.Window) {
+	pathEntry := widget.NewEntry()
+	pathEntry.SetPlaceHolder("connections.json / data-sources.json / dataSources.xml / .env 文件路径，或连接URL")
+	if paths := importer.DefaultPaths(); len(paths) > 0 {
+		pathEntry.SetText(paths[0])
+	}
+
+	browseBtn := widget.NewButton("浏览...", func() {
+		fileDialog := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+			if err != nil || reader == nil {
+				return
+			}
+			defer reader.Close()
+			pathEntry.SetText(reader.URI().Path())
+		}, win)
+		fileDialog.Show()
+	})
+
+	// 预览区域
+	previewBox := container.NewVBox(widget.NewLabel("输入文件路径或连接URL后点击“解析”预览可导入的连接"))
+	var checks []*widget.Check
+	var conflictSelects []*widget.Select
+	var candidates []importer.Candidate
+
+	importBtn := widget.NewButton("导入选中的连接", nil)
+	importBtn.Importance = widget.HighImportance
+	importBtn.Disable()
+
+	parseBtn := widget.NewButton("解析", func() {
+		result, err := importer.ParseInput(pathEntry.Text)
+		if err != nil {
+			p.log.Errorf("解析导入来源失败: %v", err)
+			dialog.ShowError(err, win)
+			return
+		}
+
+		candidates = result.Candidates
+		checks = make([]*widget.Check, len(candidates))
+		conflictSelects = make([]*widget.Select, len(candidates))
+		previewBox.Objects = nil
+
+		for i, candidate := range candidates {
+			conn := candidate.Config
+			title := fmt.Sprintf("%s (%s)", conn.Name, conn.Type)
+			if conn.Group != "" {
+				title = conn.Group + " / " + title
+			}
+			checks[i] = widget.NewCheck(title, nil)
+			checks[i].SetChecked(true)
+			row := []fyne.CanvasObject{checks[i]}
+
+			// 存在同名连接时选择处理方式，设置完全相同的连接默认不导入
+			if _, ok := p.storage.FindConnection(conn.Name); ok {
+				if p.storage.HasSameConnection(conn) {
+					checks[i].SetText(title + " — 与现有连接相同")
+					checks[i].SetChecked(false)
+				} else {
+					newName := p.storage.AvailableConnectionName(conn.Name)
+					conflictSelects[i] = widget.NewSelect(conflictOptions, nil)
+					conflictSelects[i].SetSelected(conflictOptions[0])
+					row = append(row, container.NewBorder(nil, nil,
+						widget.NewLabel(fmt.Sprintf("已存在同名连接（新名称: %s）:", newName)), nil, conflictSelects[i]))
+				}
+			}
+
+			details := fmt.Sprintf("%s · %s", candidate.Source, describeEndpoint(conn.Host, conn.Port, conn.Database))
+			if conn.Username != "" {
+				details += " · 用户 " + conn.Username
+			}
+			detailLines := []string{details}
+			for _, note := range candidate.Notes {
+				detailLines = append(detailLines, "⚠ "+note)
+			}
+			detailLabel := widget.NewLabel(strings.Join(detailLines, "\n"))
+			detailLabel.Wrapping = fyne.TextWrapWord
+
+			row = append(row, detailLabel)
+			previewBox.Add(container.NewVBox(row...))
+		}
+
+		if len(result.Skipped) > 0 {
+			skipped := make([]string, 0, len(result.Skipped))
+			for _, item := range result.Skipped {
+				skipped = append(skipped, fmt.Sprintf("%s: %s", item.Name, item.Reason))
+			}
+			skippedLabel := widget.NewLabel("以下条目无法导入：\n" + strings.Join(skipped, "\n"))
+			skippedLabel.Wrapping = fyne.TextWrapWord
+			previewBox.Add(widget.NewSeparator())
+			previewBox.Add(skippedLabel)
+		}
+
+		if len(candidates) == 0 {
+			previewBox.Add(widget.NewLabel("没有找到可导入的连接"))
+			importBtn.Disable()
+		} else {
+			importBtn.Enable()
+		}
+		previewBox.Refresh()
+	})
+
+	content := container.NewBorder(
+		container.NewVBox(
+			container.NewBorder(nil, nil, nil, container.NewHBox(browseBtn, parseBtn), pathEntry),
+			widget.NewSeparator(),
+		),
+		importBtn, nil, nil,
+		container.NewVScroll(previewBox),
+	)
+
+	importDialog := dialog.NewCustom("导入连接", "关闭", content, win)
+
+	importBtn.OnTapped = func() {
+		var imported []string
+		var failed []string
+		for i, candidate := range candidates {
+			if !checks[i].Checked {
+				continue
+			}
+			resolution := config.ConflictRename
+			if conflictSelects[i] != nil {
+				resolution = conflictResolutions[conflictSelects[i].Selected]
+			}
+
+			name, err := p.storage.MergeConnection(candidate.Config, resolution)
+			if err != nil {
+				p.log.Errorf("导入连接 %s 失败: %v", candidate.Config.Name, err)
+				failed = append(failed, fmt.Sprintf("%s: %v", candidate.Config.Name, err))
+				continue
+			}
+			if name == "" {
+				continue
+			}
+			p.log.Infof("从 %s 导入连接: %s", candidate.Source, name)
+			imported = append(imported, name)
+		}
+
+		// 重新加载连接列表
+		p.refreshTree()
+		p.onRefresh()
+
+		importDialog.Hide()
+		message := fmt.Sprintf("已导入 %d 个连接", len(imported))
+		if len(failed) > 0 {
+			message += "\n\n导入失败：\n" + strings.Join(failed, "\n")
+		}
+		dialog.ShowInformation("导入完成", message, win)
+	}
+
+	importDialog.Resize(fyne.NewSize(650, 500))
+	importDialog.Show()
+}
+
+// describeEndpoint 返回连接地址的简短描述
+func describeEndpoint(host, port, database string) string {
+	endpoint := host
+	if port != "" {
+		endpoint += ":" + port
+	}
+	if database != "" {
+		endpoint += "/" + database
+	}
+	return endpoint
+}
@@ -0,0 +1,188 @@
+package pages
+
+import (
+	"context"
+	"fmt"
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"go-DBmodeler/internal/config"
+	"go-DBmodeler/internal/db/connector"
+	"go-DBmodeler/internal/generator"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// onJSONShapeClicked 读取选中表的结构后显示JSON结构推断对话框
+func (p *GeneratorPage) onJSONShapeClicked() {
+	w := fyne.CurrentApp().Driver().AllWindows()[0]
+	if p.processor == nil || p.selectedTable == "" || p.databaseSelect.Selected == "" {
+		dialog.ShowError(fmt.Errorf("请先选择表"), w)
+		return
+	}
+	if !p.processor.SupportsSampling() {
+		dialog.ShowError(fmt.Errorf("%s 不支持读取样本数据", p.selectedConnection().Type), w)
+		return
+	}
+
+	processor := p.processor
+	database, table := p.databaseSelect.Selected, p.selectedTable
+	var metadata *connector.TableMetadata
+	p.runCancellable("获取表结构", func(ctx context.Context) error {
+		var err error
+		metadata, err = processor.GetTableMetadata(ctx, database, table)
+		return err
+	}, func() {
+		p.showJSONShapeDialog(database, metadata)
+	}, nil)
+}
+
+// showJSONShapeDialog 显示JSON结构推断对话框
+// 读取所选列的样本数据推断结构，编辑后按连接、数据库、表和列保存，之后生成该表时使用保存的结构
+func (p *GeneratorPage) showJSONShapeDialog(database string, metadata *connector.TableMetadata) {
+	w := fyne.CurrentApp().Driver().AllWindows()[0]
+	connectionName := p.selectedConnection().Name
+	table := metadata.Name
+
+	// 类型名称包含 json 的列排在前面
+	fields := append([]connector.FieldInfo(nil), metadata.Fields...)
+	sort.SliceStable(fields, func(i, j int) bool {
+		return isJSONColumnType(fields[i].Type) && !isJSONColumnType(fields[j].Type)
+	})
+	columns := make(map[string]string, len(fields))
+	labels := make([]string, 0, len(fields))
+	for _, field := range fields {
+		label := fmt.Sprintf("%s (%s)", field.Name, field.Type)
+		columns[label] = field.Name
+		labels = append(labels, label)
+	}
+
+	sizeEntry := widget.NewEntry()
+	sizeEntry.SetText(strconv.Itoa(connector.DefaultSampleSize))
+	typeNameEntry := widget.NewEntry()
+	declarationsEntry := widget.NewMultiLineEntry()
+	declarationsEntry.SetPlaceHolder("点击“采样推断”读取样本数据生成类型声明，可以直接编辑")
+	declarationsEntry.Wrapping = fyne.TextWrapOff
+	declarationsEntry.SetMinRowsVisible(16)
+	status := widget.NewLabel("")
+
+	var saveBtn, deleteBtn, sampleBtn *widget.Button
+	column := ""
+
+	// 选择列时加载已保存的结构
+	columnSelect := widget.NewSelect(labels, func(label string) {
+		column = columns[label]
+		shapes := p.storage.GetJSONShapes(connectionName, database, table)
+		if shape, ok := shapes[column]; ok {
+			typeNameEntry.SetText(shape.TypeName)
+			declarationsEntry.SetText(shape.Declarations)
+			if shape.SampleSize > 0 {
+				sizeEntry.SetText(strconv.Itoa(shape.SampleSize))
+			}
+			status.SetText("已加载保存的结构")
+			deleteBtn.Enable()
+		} else {
+			typeNameEntry.SetText(generator.JSONShapeTypeName(table, column))
+			declarationsEntry.SetText("")
+			status.SetText("")
+			deleteBtn.Disable()
+		}
+		sampleBtn.Enable()
+		saveBtn.Enable()
+	})
+	columnSelect.PlaceHolder = "选择JSON列"
+
+	sampleBtn = widget.NewButton("采样推断", func() {
+		limit, err := strconv.Atoi(strings.TrimSpace(sizeEntry.Text))
+		if err != nil || limit <= 0 {
+			dialog.ShowError(fmt.Errorf("样本行数应为正整数"), w)
+			return
+		}
+		typeName := strings.TrimSpace(typeNameEntry.Text)
+		if typeName == "" {
+			typeName = generator.JSONShapeTypeName(table, column)
+			typeNameEntry.SetText(typeName)
+		}
+
+		processor, sampleColumn := p.processor, column
+		var samples []string
+		p.runCancellable("读取样本数据", func(ctx context.Context) error {
+			var err error
+			samples, err = processor.SampleColumn(ctx, database, table, sampleColumn, limit)
+			return err
+		}, func() {
+			shape, invalid, err := generator.InferShape(samples)
+			if err != nil {
+				dialog.ShowError(err, w)
+				return
+			}
+			declarationsEntry.SetText(shape.Declarations(typeName))
+			if invalid > 0 {
+				status.SetText(fmt.Sprintf("读取了 %d 行，其中 %d 行不是有效的JSON，已忽略", len(samples), invalid))
+			} else {
+				status.SetText(fmt.Sprintf("读取了 %d 行", len(samples)))
+			}
+		}, nil)
+	})
+
+	saveBtn = widget.NewButton("保存", func() {
+		limit, _ := strconv.Atoi(strings.TrimSpace(sizeEntry.Text))
+		shape := config.JSONShape{
+			Connection:   connectionName,
+			Database:     database,
+			Table:        table,
+			Column:       column,
+			TypeName:     strings.TrimSpace(typeNameEntry.Text),
+			Declarations: declarationsEntry.Text,
+			SampleSize:   limit,
+		}
+		if strings.TrimSpace(shape.Declarations) == "" {
+			dialog.ShowError(fmt.Errorf("类型声明为空，请先采样推断"), w)
+			return
+		}
+		if err := p.storage.SaveJSONShape(shape); err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		status.SetText("已保存，生成该表时使用此结构")
+		deleteBtn.Enable()
+	})
+
+	deleteBtn = widget.NewButton("删除", func() {
+		if err := p.storage.DeleteJSONShape(connectionName, database, table, column); err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		declarationsEntry.SetText("")
+		status.SetText("已删除，生成该表时使用类型映射")
+		deleteBtn.Disable()
+	})
+
+	sampleBtn.Disable()
+	saveBtn.Disable()
+	deleteBtn.Disable()
+
+	content := container.NewBorder(
+		container.NewVBox(
+			container.NewBorder(nil, nil, widget.NewLabel("列:"), nil, columnSelect),
+			container.NewBorder(nil, nil, widget.NewLabel("样本行数:"), sampleBtn, sizeEntry),
+			container.NewBorder(nil, nil, widget.NewLabel("类型名称:"), nil, typeNameEntry),
+			widget.NewLabel("类型声明:"),
+		),
+		container.NewBorder(nil, nil, nil, container.NewHBox(saveBtn, deleteBtn), status),
+		nil,
+		nil,
+		declarationsEntry,
+	)
+
+	shapeDialog := dialog.NewCustom("推断JSON结构 - "+table, "关闭", content, w)
+	shapeDialog.Resize(fyne.NewSize(720, 560))
+	shapeDialog.Show()
+}
+
+// isJSONColumnType 判断列类型是否为JSON类型
+func isJSONColumnType(columnType string) bool {
+	return strings.Contains(strings.ToLower(columnType), "json")
+}
@@ -1,6 +1,7 @@
 package pages
 
 import (
+	"errors"
 	"fmt"
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
@@ -9,9 +10,14 @@ import (
 	"fyne.io/fyne/v2/storage"
 	"fyne.io/fyne/v2/widget"
 	"go-DBmodeler/internal/config"
+	"go-DBmodeler/internal/db/connector"
+	"go-DBmodeler/internal/db/dialect"
+	"go-DBmodeler/internal/generator"
+	"go-DBmodeler/internal/ui/widgets"
 	"go-DBmodeler/pkg/logger"
 	"os"
 	"path/filepath"
+	"sort"
 )
 
 // ScriptManagerPage 表示脚本管理页面
@@ -26,7 +32,11 @@ type ScriptManagerPage struct {
 	editBtn     *widget.Button
 	deleteBtn   *widget.Button
 	importBtn   *widget.Button
+	runBtn      *widget.Button
+	historyBtn  *widget.Button
 	previewArea *widget.Entry
+	metaLabel   *widget.Label
+	console     *widgets.ConsolePanel
 
 	// 数据
 	scripts        map[string]string
@@ -73,14 +83,19 @@ func (p *ScriptManagerPage) buildUI() *fyne.Container {
 			p.showScriptPreview(p.selectedScript)
 			p.editBtn.Enable()
 			p.deleteBtn.Enable()
+			p.runBtn.Enable()
+			p.historyBtn.Enable()
 		}
 	}
 
 	p.scriptList.OnUnselected = func(id widget.ListItemID) {
 		p.selectedScript = ""
 		p.previewArea.SetText("")
+		p.metaLabel.SetText("")
 		p.editBtn.Disable()
 		p.deleteBtn.Disable()
+		p.runBtn.Disable()
+		p.historyBtn.Disable()
 	}
 
 	// 创建按钮
@@ -90,12 +105,18 @@ func (p *ScriptManagerPage) buildUI() *fyne.Container {
 	p.deleteBtn = widget.NewButton("删除脚本", p.onDeleteClicked)
 	p.deleteBtn.Disable()
 	p.importBtn = widget.NewButton("导入脚本", p.onImportClicked)
+	p.runBtn = widget.NewButton("试运行", p.onRunClicked)
+	p.runBtn.Disable()
+	p.historyBtn = widget.NewButton("历史版本", p.onHistoryClicked)
+	p.historyBtn.Disable()
 
 	// 创建预览区域
 	p.previewArea = widget.NewMultiLineEntry()
 	p.previewArea.SetPlaceHolder("选择脚本查看预览...")
 	p.previewArea.Disable()
 	p.previewArea.Wrapping = fyne.TextWrapOff
+	p.metaLabel = widget.NewLabel("")
+	p.metaLabel.Wrapping = fyne.TextWrapWord
 
 	// 创建按钮容器
 	buttonContainer := container.NewHBox(
@@ -103,6 +124,8 @@ func (p *ScriptManagerPage) buildUI() *fyne.Container {
 		p.editBtn,
 		p.deleteBtn,
 		p.importBtn,
+		p.runBtn,
+		p.historyBtn,
 		layout.NewSpacer(),
 	)
 
@@ -115,10 +138,16 @@ func (p *ScriptManagerPage) buildUI() *fyne.Container {
 		p.scriptList,
 	)
 
+	// 创建脚本控制台
+	p.console = widgets.NewConsolePanel()
+
 	// 创建右侧预览面板
 	rightPanel := container.NewBorder(
-		widget.NewLabelWithStyle("👀 脚本预览", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
-		nil,
+		container.NewVBox(
+			widget.NewLabelWithStyle("👀 脚本预览", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+			p.metaLabel,
+		),
+		p.console,
 		nil,
 		nil,
 		container.NewVScroll(p.previewArea),
@@ -140,13 +169,38 @@ func (p *ScriptManagerPage) updateScriptNames() {
 	for name := range p.scripts {
 		p.scriptNames = append(p.scriptNames, name)
 	}
+	sort.Strings(p.scriptNames)
 }
 
-// showScriptPreview 显示脚本预览
+// showScriptPreview 显示脚本预览和元数据
 func (p *ScriptManagerPage) showScriptPreview(scriptName string) {
 	if content, exists := p.scripts[scriptName]; exists {
 		p.previewArea.SetText(content)
 	}
+
+	asset, err := p.storage.Library().Get(config.AssetScript, scriptName)
+	if err != nil {
+		p.metaLabel.SetText("")
+		return
+	}
+	p.metaLabel.SetText(formatAssetMeta(&asset.AssetMeta))
+}
+
+// formatAssetMeta 将资源元数据格式化为一行说明
+func formatAssetMeta(meta *config.AssetMeta) string {
+	valueOr := func(value string) string {
+		if value == "" {
+			return "-"
+		}
+		return value
+	}
+	text := fmt.Sprintf("作者: %s  |  目标语言: %s  |  版本: %s  |  修订: %d  |  更新时间: %s",
+		valueOr(meta.Author), valueOr(meta.Language), valueOr(meta.Version),
+		meta.CurrentRevision(), meta.UpdatedAt.Format("2006-01-02 15:04"))
+	if meta.Description != "" {
+		text = meta.Description + "\n" + text
+	}
+	return text
 }
 
 // onAddClicked 处理新增按钮点击事件
@@ -173,10 +227,13 @@ func (p *ScriptManagerPage) onDeleteClicked() {
 	// 显示确认对话框
 	w := fyne.CurrentApp().Driver().AllWindows()[0]
 	dialog.ShowConfirm("确认删除",
-		fmt.Sprintf("确定要删除脚本 '%s' 吗？此操作不可恢复。", p.selectedScript),
+		fmt.Sprintf("确定要删除脚本 '%s' 吗？所有历史版本都会被删除，此操作不可恢复。", p.selectedScript),
 		func(confirmed bool) {
 			if confirmed {
-				p.storage.DeleteScript(p.selectedScript)
+				if err := p.storage.DeleteScript(p.selectedScript); err != nil {
+					dialog.ShowError(err, w)
+					return
+				}
 				p.scripts = p.storage.GetScripts()
 				p.updateScriptNames()
 				p.scriptList.UnselectAll()
@@ -186,6 +243,35 @@ func (p *ScriptManagerPage) onDeleteClicked() {
 		}, w)
 }
 
+// onRunClicked 处理试运行按钮点击事件，使用示例表结构运行选中的脚本
+func (p *ScriptManagerPage) onRunClicked() {
+	if p.selectedScript == "" {
+		return
+	}
+
+	p.runScript(p.selectedScript, p.scripts[p.selectedScript], p.console)
+}
+
+// runScript 使用示例表结构运行脚本，并在控制台显示输出、结果和错误
+func (p *ScriptManagerPage) runScript(name, script string, console *widgets.ConsolePanel) error {
+	// 示例表结构使用MySQL的字段类型
+	gen, err := generator.NewGenerator(dialect.NewMySQLMapper(), generator.DefaultTemplate(), p.log)
+	if err != nil {
+		console.ShowRun(nil, err)
+		return err
+	}
+
+	gen.SetScript(script)
+	gen.SetScriptParams(p.storage.GetScriptParamValues(name))
+
+	code, err := gen.Generate(sampleTableMetadata())
+	console.ShowRun(gen.ConsoleLogs(), err)
+	if err == nil {
+		console.ShowOutput(code)
+	}
+	return err
+}
+
 // onImportClicked 处理导入按钮点击事件
 func (p *ScriptManagerPage) onImportClicked() {
 	w := fyne.CurrentApp().Driver().AllWindows()[0]
@@ -215,7 +301,7 @@ func (p *ScriptManagerPage) onImportClicked() {
 		}
 
 		// 显示导入确认对话框
-		p.showImportDialog(fileName, string(content))
+		p.showImportDialog(fileName, string(content), reader.URI().Path())
 	}, w)
 
 	// 设置文件过滤器
@@ -224,8 +310,8 @@ func (p *ScriptManagerPage) onImportClicked() {
 	fileDialog.Show()
 }
 
-// showImportDialog 显示导入确认对话框
-func (p *ScriptManagerPage) showImportDialog(name, content string) {
+// showImportDialog 显示导入确认对话框，同名脚本已存在时导入内容作为新版本保存
+func (p *ScriptManagerPage) showImportDialog(name, content, source string) {
 	w := fyne.CurrentApp().Driver().AllWindows()[0]
 
 	// 创建名称输入框
@@ -256,16 +342,15 @@ func (p *ScriptManagerPage) showImportDialog(name, content string) {
 					return
 				}
 
-				// 保存脚本到导入目录
-				importDir := filepath.Join(p.storage.GetConfigDir(), "scripts", "imported")
-				if err := os.MkdirAll(importDir, 0755); err != nil {
-					dialog.ShowError(fmt.Errorf("创建导入目录失败: %v", err), w)
-					return
+				// 保存脚本到资源库
+				library := p.storage.Library()
+				asset := config.Asset{AssetMeta: config.AssetMeta{Name: scriptName, Kind: config.AssetScript, Language: "TypeScript"}}
+				if existing, err := library.Get(config.AssetScript, scriptName); err == nil {
+					asset = *existing
 				}
-
-				filePath := filepath.Join(importDir, scriptName+".js")
-				if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
-					dialog.ShowError(fmt.Errorf("保存脚本文件失败: %v", err), w)
+				asset.Content = content
+				if err := library.Save(asset, "导入自 "+source); err != nil {
+					dialog.ShowError(fmt.Errorf("保存脚本失败: %v", err), w)
 					return
 				}
 
@@ -275,7 +360,7 @@ func (p *ScriptManagerPage) showImportDialog(name, content string) {
 				p.scriptList.Refresh()
 
 				p.log.Infof("已导入脚本: %s", scriptName)
-				dialog.ShowInformation("成功", fmt.Sprintf("脚本 '%s' 导入成功！\n文件已保存到: %s", scriptName, filePath), w)
+				dialog.ShowInformation("成功", fmt.Sprintf("脚本 '%s' 导入成功！\n已保存到资源库: %s", scriptName, library.Dir()), w)
 			}
 		}, w)
 
@@ -292,6 +377,31 @@ func (p *ScriptManagerPage) showScriptDialog(name, content string, isNew bool) {
 	nameEntry.SetText(name)
 	nameEntry.SetPlaceHolder("输入脚本名称")
 
+	// 创建元数据输入框
+	meta := config.AssetMeta{Kind: config.AssetScript, Language: "TypeScript"}
+	if !isNew {
+		if asset, err := p.storage.Library().Get(config.AssetScript, name); err == nil {
+			meta = asset.AssetMeta
+		}
+	}
+	descriptionEntry := widget.NewEntry()
+	descriptionEntry.SetText(meta.Description)
+	descriptionEntry.SetPlaceHolder("脚本用途说明")
+	authorEntry := widget.NewEntry()
+	authorEntry.SetText(meta.Author)
+	languageSelect := widget.NewSelectEntry([]string{"TypeScript", "JavaScript"})
+	languageSelect.SetText(meta.Language)
+	versionEntry := widget.NewEntry()
+	versionEntry.SetText(meta.Version)
+	versionEntry.SetPlaceHolder("例如 1.0.0")
+	noteEntry := widget.NewEntry()
+	noteEntry.SetPlaceHolder("本次修改说明（可选）")
+	metaRow := container.NewGridWithColumns(3,
+		container.NewBorder(nil, nil, widget.NewLabel("作者"), nil, authorEntry),
+		container.NewBorder(nil, nil, widget.NewLabel("目标语言"), nil, languageSelect),
+		container.NewBorder(nil, nil, widget.NewLabel("版本"), nil, versionEntry),
+	)
+
 	// 创建内容编辑器
 	contentEntry := widget.NewMultiLineEntry()
 	contentEntry.SetText(content)
@@ -346,17 +456,28 @@ console.log("生成的接口代码:", result);`
 		dialog.ShowInformation("复制成功", "脚本内容已复制到剪贴板", w)
 	})
 
+	// 创建试运行按钮，出错时在编辑器中标记出错行
+	dialogConsole := widgets.NewConsolePanel()
+	runBtn := widget.NewButton("试运行", func() {
+		err := p.runScript(nameEntry.Text, contentEntry.Text, dialogConsole)
+		var scriptErr *generator.ScriptError
+		if errors.As(err, &scriptErr) {
+			widgets.HighlightLine(contentEntry, scriptErr.Line)
+		}
+	})
+
 	// 创建按钮容器
 	buttonContainer := container.NewHBox(
 		copyExampleBtn,
 		copyContentBtn,
+		runBtn,
 		layout.NewSpacer(),
 	)
 
 	// 创建内容容器
 	contentContainer := container.NewBorder(
 		nil,
-		buttonContainer,
+		container.NewVBox(buttonContainer, dialogConsole),
 		nil,
 		nil,
 		contentEntry,
@@ -365,7 +486,10 @@ console.log("生成的接口代码:", result);`
 	// 创建表单
 	form := widget.NewForm(
 		widget.NewFormItem("脚本名称", nameEntry),
+		widget.NewFormItem("描述", descriptionEntry),
+		widget.NewFormItem("元数据", metaRow),
 		widget.NewFormItem("脚本内容", contentContainer),
+		widget.NewFormItem("修改说明", noteEntry),
 	)
 
 	// 创建对话框
@@ -385,21 +509,130 @@ console.log("生成的接口代码:", result);`
 					return
 				}
 
-				// 保存脚本
-				p.storage.SetScript(newName, newContent)
+				// 保存脚本，内容变化时资源库会创建新版本
+				meta.Name = newName
+				meta.Description = descriptionEntry.Text
+				meta.Author = authorEntry.Text
+				meta.Language = languageSelect.Text
+				meta.Version = versionEntry.Text
+				asset := config.Asset{AssetMeta: meta, Content: newContent}
+				if err := p.storage.Library().Save(asset, noteEntry.Text); err != nil {
+					dialog.ShowError(fmt.Errorf("保存脚本失败: %v", err), w)
+					return
+				}
 				p.scripts = p.storage.GetScripts()
 				p.updateScriptNames()
 				p.scriptList.Refresh()
 
+				if newName == p.selectedScript {
+					p.showScriptPreview(newName)
+				}
 				p.log.Infof("已保存脚本: %s", newName)
 				dialog.ShowInformation("成功", "脚本保存成功", w)
 			}
 		}, w)
 
-	dialog.Resize(fyne.NewSize(700, 550))
+	dialog.Resize(fyne.NewSize(900, 750))
 	dialog.Show()
 }
 
+// onHistoryClicked 处理历史版本按钮点击事件
+func (p *ScriptManagerPage) onHistoryClicked() {
+	if p.selectedScript == "" {
+		return
+	}
+
+	p.showHistoryDialog(p.selectedScript)
+}
+
+// showHistoryDialog 显示脚本的历史版本，可以预览任意版本并恢复
+func (p *ScriptManagerPage) showHistoryDialog(name string) {
+	w := fyne.CurrentApp().Driver().AllWindows()[0]
+	library := p.storage.Library()
+
+	revisions, err := library.History(config.AssetScript, name)
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("读取历史版本失败: %v", err), w)
+		return
+	}
+
+	// 最新版本显示在最前面
+	sorted := make([]config.Revision, len(revisions))
+	for i, revision := range revisions {
+		sorted[len(revisions)-1-i] = revision
+	}
+
+	preview := widget.NewMultiLineEntry()
+	preview.Wrapping = fyne.TextWrapOff
+	preview.Disable()
+
+	selected := -1
+	restoreBtn := widget.NewButton("恢复此版本", nil)
+	restoreBtn.Disable()
+
+	revisionList := widget.NewList(
+		func() int {
+			return len(sorted)
+		},
+		func() fyne.CanvasObject {
+			return widget.NewLabel("#0000  2006-01-02 15:04:05  v0.0.0")
+		},
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			revision := sorted[id]
+			text := fmt.Sprintf("#%d  %s", revision.Number, revision.CreatedAt.Format("2006-01-02 15:04:05"))
+			if revision.Version != "" {
+				text += "  v" + revision.Version
+			}
+			if revision.Note != "" {
+				text += "  " + revision.Note
+			}
+			obj.(*widget.Label).SetText(text)
+		},
+	)
+	revisionList.OnSelected = func(id widget.ListItemID) {
+		selected = sorted[id].Number
+		content, err := library.RevisionContent(config.AssetScript, name, selected)
+		if err != nil {
+			preview.SetText(err.Error())
+			restoreBtn.Disable()
+			return
+		}
+		preview.SetText(content)
+		if id == 0 {
+			restoreBtn.Disable() // 已是最新版本
+		} else {
+			restoreBtn.Enable()
+		}
+	}
+
+	split := container.NewHSplit(revisionList, container.NewVScroll(preview))
+	split.Offset = 0.4
+	content := container.NewBorder(nil, container.NewHBox(layout.NewSpacer(), restoreBtn), nil, nil, split)
+
+	historyDialog := dialog.NewCustom(fmt.Sprintf("历史版本 - %s", name), "关闭", content, w)
+	restoreBtn.OnTapped = func() {
+		dialog.ShowConfirm("确认恢复",
+			fmt.Sprintf("确定要将脚本 '%s' 恢复到版本 %d 吗？恢复会创建一个新版本，不会删除任何历史。", name, selected),
+			func(confirmed bool) {
+				if !confirmed {
+					return
+				}
+				if err := library.Restore(config.AssetScript, name, selected); err != nil {
+					dialog.ShowError(fmt.Errorf("恢复失败: %v", err), w)
+					return
+				}
+
+				p.scripts = p.storage.GetScripts()
+				p.showScriptPreview(name)
+				historyDialog.Hide()
+				p.log.Infof("已将脚本 %s 恢复到版本 %d", name, selected)
+			}, w)
+	}
+
+	historyDialog.Resize(fyne.NewSize(900, 600))
+	historyDialog.Show()
+}
+
 // getScriptExamples 获取脚本示例
 func (p *ScriptManagerPage) getScriptExamples() string {
 	return `// 🎯 脚本编写指南：
@@ -450,3 +683,18 @@ for (const field of input.Fields) {
 }
 output += '}';`
 }
+
+// sampleTableMetadata 返回试运行脚本时使用的示例表结构
+func sampleTableMetadata() *connector.TableMetadata {
+	return &connector.TableMetadata{
+		Name: "user_account",
+		Fields: []connector.FieldInfo{
+			{Name: "id", Type: "bigint", IsPrimary: true, Comment: "用户ID"},
+			{Name: "user_name", Type: "varchar", Length: 64, IsUnique: true, Comment: "用户名"},
+			{Name: "email", Type: "varchar", Length: 128, IsNullable: true, Comment: "邮箱"},
+			{Name: "is_active", Type: "tinyint(1)", Default: "1", Comment: "是否启用"},
+			{Name: "profile", Type: "json", IsNullable: true, Comment: "扩展信息"},
+			{Name: "created_at", Type: "datetime", Default: "CURRENT_TIMESTAMP", Comment: "创建时间"},
+		},
+	}
+}
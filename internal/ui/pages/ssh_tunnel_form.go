@@ -0,0 +1,129 @@
+package pages
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+	"go-DBmodeler/internal/config"
+)
+
+// sshAuthOptions 是SSH认证方式选择器的选项
+var sshAuthOptions = []string{"私钥文件", "SSH Agent", "密码"}
+
+// sshAuthMethods 将SSH认证方式选项映射为配置中的取值
+var sshAuthMethods = map[string]string{
+	"私钥文件":      config.SSHAuthKey,
+	"SSH Agent": config.SSHAuthAgent,
+	"密码":        config.SSHAuthPassword,
+}
+
+// sshTunnelForm 是连接对话框中的SSH隧道设置
+type sshTunnelForm struct {
+	enabled    *widget.Check
+	host       *widget.Entry
+	port       *widget.Entry
+	user       *widget.Entry
+	auth       *widget.Select
+	keyFile    *widget.Entry
+	password   *widget.Entry
+	knownHosts *widget.Entry
+	insecure   *widget.Check
+	container  *fyne.Container
+}
+
+// newSSHTunnelForm 创建SSH隧道设置表单，tunnel 为空时隧道默认不启用
+func newSSHTunnelForm(tunnel *config.SSHTunnel, win fyne.Window) *sshTunnelForm {
+	f := &sshTunnelForm{
+		host:       widget.NewEntry(),
+		port:       widget.NewEntry(),
+		user:       widget.NewEntry(),
+		keyFile:    widget.NewEntry(),
+		password:   widget.NewPasswordEntry(),
+		knownHosts: widget.NewEntry(),
+		insecure:   widget.NewCheck("跳过主机密钥检查（不安全）", nil),
+	}
+	f.host.SetPlaceHolder("跳板机主机名/IP地址")
+	f.port.SetPlaceHolder("22")
+	f.user.SetPlaceHolder("跳板机用户名")
+	f.keyFile.SetPlaceHolder("~/.ssh/id_ed25519")
+	f.password.SetPlaceHolder("登录密码或私钥密码（可选）")
+	f.knownHosts.SetPlaceHolder("~/.ssh/known_hosts")
+
+	// 只有私钥认证需要私钥文件
+	f.auth = widget.NewSelect(sshAuthOptions, func(option string) {
+		if sshAuthMethods[option] == config.SSHAuthKey {
+			f.keyFile.Enable()
+		} else {
+			f.keyFile.Disable()
+		}
+	})
+	f.auth.SetSelected(sshAuthOptions[0])
+
+	// 主机密钥检查关闭时不需要 known_hosts 文件
+	f.insecure.OnChanged = func(checked bool) {
+		if checked {
+			f.knownHosts.Disable()
+		} else {
+			f.knownHosts.Enable()
+		}
+	}
+
+	settings := widget.NewForm(
+		widget.NewFormItem("跳板机", f.host),
+		widget.NewFormItem("SSH端口", f.port),
+		widget.NewFormItem("SSH用户", f.user),
+		widget.NewFormItem("认证方式", f.auth),
+		widget.NewFormItem("私钥文件", fileField(f.keyFile, win)),
+		widget.NewFormItem("SSH密码", f.password),
+		widget.NewFormItem("known_hosts", f.knownHosts),
+		widget.NewFormItem("", f.insecure),
+	)
+	settings.Hide()
+
+	f.enabled = widget.NewCheck("通过SSH隧道连接", func(checked bool) {
+		if checked {
+			settings.Show()
+		} else {
+			settings.Hide()
+		}
+	})
+
+	if tunnel != nil {
+		f.host.SetText(tunnel.Host)
+		f.port.SetText(tunnel.Port)
+		f.user.SetText(tunnel.User)
+		f.keyFile.SetText(tunnel.KeyFile)
+		f.password.SetText(tunnel.Password)
+		f.knownHosts.SetText(tunnel.KnownHostsFile)
+		f.insecure.SetChecked(tunnel.InsecureIgnoreHostKey)
+		for option, method := range sshAuthMethods {
+			if method == tunnel.Auth {
+				f.auth.SetSelected(option)
+			}
+		}
+		f.enabled.SetChecked(true)
+	}
+
+	f.container = container.NewVBox(f.enabled, settings)
+	return f
+}
+
+// Tunnel 返回表单中的SSH隧道配置，未启用隧道时返回空
+func (f *sshTunnelForm) Tunnel() *config.SSHTunnel {
+	if !f.enabled.Checked {
+		return nil
+	}
+	tunnel := &config.SSHTunnel{
+		Host:                  f.host.Text,
+		Port:                  f.port.Text,
+		User:                  f.user.Text,
+		Auth:                  sshAuthMethods[f.auth.Selected],
+		Password:              f.password.Text,
+		KnownHostsFile:        f.knownHosts.Text,
+		InsecureIgnoreHostKey: f.insecure.Checked,
+	}
+	if tunnel.Auth == config.SSHAuthKey {
+		tunnel.KeyFile = f.keyFile.Text
+	}
+	return tunnel
+}
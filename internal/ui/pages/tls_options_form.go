@@ -0,0 +1,108 @@
+package pages
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"go-DBmodeler/internal/config"
+)
+
+// tlsModeOptions 是TLS模式选择器的选项，顺序与 config.TLSModes 相同
+var tlsModeOptions = []string{
+	"不使用TLS (disable)",
+	"加密但不校验证书 (require)",
+	"校验CA (verify-ca)",
+	"校验CA和主机名 (verify-full)",
+}
+
+// tlsOptionsForm 是连接对话框中的TLS设置
+type tlsOptionsForm struct {
+	mode       *widget.Select
+	caCert     *widget.Entry
+	clientCert *widget.Entry
+	clientKey  *widget.Entry
+	serverName *widget.Entry
+	container  *fyne.Container
+}
+
+// newTLSOptionsForm 创建TLS设置表单，options 为空时不使用TLS
+func newTLSOptionsForm(options *config.TLSOptions, win fyne.Window) *tlsOptionsForm {
+	f := &tlsOptionsForm{
+		caCert:     widget.NewEntry(),
+		clientCert: widget.NewEntry(),
+		clientKey:  widget.NewEntry(),
+		serverName: widget.NewEntry(),
+	}
+	f.caCert.SetPlaceHolder("CA证书文件（可选，默认使用系统根证书）")
+	f.clientCert.SetPlaceHolder("客户端证书文件（可选）")
+	f.clientKey.SetPlaceHolder("客户端私钥文件（可选）")
+	f.serverName.SetPlaceHolder("证书中的服务器名称（可选，默认为主机）")
+
+	settings := widget.NewForm(
+		widget.NewFormItem("CA证书", fileField(f.caCert, win)),
+		widget.NewFormItem("客户端证书", fileField(f.clientCert, win)),
+		widget.NewFormItem("客户端私钥", fileField(f.clientKey, win)),
+		widget.NewFormItem("服务器名称", f.serverName),
+	)
+	settings.Hide()
+
+	// 不使用TLS时隐藏证书设置
+	f.mode = widget.NewSelect(tlsModeOptions, func(option string) {
+		if option == tlsModeOptions[0] {
+			settings.Hide()
+		} else {
+			settings.Show()
+		}
+	})
+	f.mode.SetSelected(tlsModeOptions[0])
+
+	if options != nil {
+		f.caCert.SetText(options.CACert)
+		f.clientCert.SetText(options.ClientCert)
+		f.clientKey.SetText(options.ClientKey)
+		f.serverName.SetText(options.ServerName)
+		for i, mode := range config.TLSModes {
+			if mode == options.Mode {
+				f.mode.SetSelected(tlsModeOptions[i])
+			}
+		}
+	}
+
+	f.container = container.NewVBox(
+		widget.NewForm(widget.NewFormItem("TLS", f.mode)),
+		settings,
+	)
+	return f
+}
+
+// fileField 返回带有浏览按钮的文件路径输入框
+func fileField(entry *widget.Entry, win fyne.Window) fyne.CanvasObject {
+	browseBtn := widget.NewButton("浏览...", func() {
+		dialog.ShowFileOpen(func(reader fyne.URIReadCloser, err error) {
+			if err != nil || reader == nil {
+				return
+			}
+			reader.Close()
+			entry.SetText(reader.URI().Path())
+		}, win)
+	})
+	return container.NewBorder(nil, nil, nil, browseBtn, entry)
+}
+
+// Options 返回表单中的TLS设置，不使用TLS时返回空
+func (f *tlsOptionsForm) Options() *config.TLSOptions {
+	for i, option := range tlsModeOptions {
+		if option != f.mode.Selected || config.TLSModes[i] == config.TLSModeDisable {
+			continue
+		}
+		return &config.TLSOptions{
+			Mode:       config.TLSModes[i],
+			CACert:     f.caCert.Text,
+			ClientCert: f.clientCert.Text,
+			ClientKey:  f.clientKey.Text,
+			ServerName: f.serverName.Text,
+		}
+	}
+	return nil
+}
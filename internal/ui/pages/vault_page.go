@@ -0,0 +1,128 @@
+package pages
+
+import (
+	"errors"
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"go-DBmodeler/internal/config"
+	"go-DBmodeler/pkg/logger"
+)
+
+// minMasterPasswordLength 是主密码的最小长度
+const minMasterPasswordLength = 8
+
+// NewVaultPage 创建保险库解锁页面
+// 首次使用时设置主密码，之后每次启动输入主密码解锁，解锁成功后调用 onUnlocked
+func NewVaultPage(log *logger.Logger, storage *config.Storage, onUnlocked func()) *fyne.Container {
+	passwordEntry := widget.NewPasswordEntry()
+	passwordEntry.SetPlaceHolder("主密码")
+
+	errorLabel := widget.NewLabel("")
+	errorLabel.Importance = widget.DangerImportance
+	errorLabel.Wrapping = fyne.TextWrapWord
+
+	// 已设置主密码时显示解锁界面
+	if storage.Vault().IsInitialized() {
+		unlock := func() {
+			if err := storage.UnlockVault(passwordEntry.Text); err != nil {
+				log.Warnf("解锁保险库失败: %v", err)
+				errorLabel.SetText(err.Error())
+				passwordEntry.SetText("")
+				return
+			}
+			log.Info("保险库已解锁")
+			onUnlocked()
+		}
+		passwordEntry.OnSubmitted = func(string) { unlock() }
+
+		form := container.NewVBox(
+			widget.NewLabelWithStyle("解锁凭据保险库", fyne.TextAlignCenter, fyne.TextStyle{Bold: true}),
+			widget.NewLabel("请输入主密码以访问已保存的数据库连接密码"),
+			passwordEntry,
+			errorLabel,
+			widget.NewButton("解锁", unlock),
+		)
+		return container.NewCenter(container.NewGridWrap(fyne.NewSize(400, 220), form))
+	}
+
+	// 首次使用时设置主密码
+	confirmEntry := widget.NewPasswordEntry()
+	confirmEntry.SetPlaceHolder("确认主密码")
+
+	create := func() {
+		if err := validateNewMasterPassword(passwordEntry.Text, confirmEntry.Text); err != nil {
+			errorLabel.SetText(err.Error())
+			return
+		}
+		if err := storage.InitializeVault(passwordEntry.Text); err != nil {
+			log.Errorf("创建保险库失败: %v", err)
+			errorLabel.SetText(err.Error())
+			return
+		}
+		log.Info("已设置主密码")
+		onUnlocked()
+	}
+	confirmEntry.OnSubmitted = func(string) { create() }
+
+	description := "连接密码将使用主密码加密保存，每次启动时需要输入主密码。主密码无法找回，请妥善保管。"
+	if storage.HasLegacyPasswords() {
+		description += "\n\n已保存的连接密码将自动迁移到保险库中。"
+	}
+	descriptionLabel := widget.NewLabel(description)
+	descriptionLabel.Wrapping = fyne.TextWrapWord
+
+	form := container.NewVBox(
+		widget.NewLabelWithStyle("设置主密码", fyne.TextAlignCenter, fyne.TextStyle{Bold: true}),
+		descriptionLabel,
+		passwordEntry,
+		confirmEntry,
+		errorLabel,
+		widget.NewButton("设置主密码", create),
+	)
+	return container.NewCenter(container.NewGridWrap(fyne.NewSize(420, 360), form))
+}
+
+// showChangeMasterPasswordDialog 显示修改主密码对话框
+func showChangeMasterPasswordDialog(log *logger.Logger, storage *config.Storage, win fyne.Window) {
+	oldEntry := widget.NewPasswordEntry()
+	newEntry := widget.NewPasswordEntry()
+	confirmEntry := widget.NewPasswordEntry()
+
+	items := []*widget.FormItem{
+		widget.NewFormItem("当前主密码", oldEntry),
+		widget.NewFormItem("新主密码", newEntry),
+		widget.NewFormItem("确认新主密码", confirmEntry),
+	}
+
+	formDialog := dialog.NewForm("修改主密码", "修改", "取消", items, func(ok bool) {
+		if !ok {
+			return
+		}
+		if err := validateNewMasterPassword(newEntry.Text, confirmEntry.Text); err != nil {
+			dialog.ShowError(err, win)
+			return
+		}
+		if err := storage.ChangeMasterPassword(oldEntry.Text, newEntry.Text); err != nil {
+			log.Errorf("修改主密码失败: %v", err)
+			dialog.ShowError(err, win)
+			return
+		}
+		log.Info("主密码已修改")
+		dialog.ShowInformation("修改成功", "主密码已修改，下次启动时请使用新主密码", win)
+	}, win)
+	formDialog.Resize(fyne.NewSize(400, 250))
+	formDialog.Show()
+}
+
+// validateNewMasterPassword 检查新主密码的长度和两次输入是否一致
+func validateNewMasterPassword(password, confirm string) error {
+	if len([]rune(password)) < minMasterPasswordLength {
+		return errors.New("主密码至少需要8个字符")
+	}
+	if password != confirm {
+		return errors.New("两次输入的主密码不一致")
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package widgets
+
+import (
+	"fmt"
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+	"go-DBmodeler/internal/generator"
+	"strings"
+)
+
+// ConsolePanel 表示脚本控制台面板，显示每次运行的输出和错误
+type ConsolePanel struct {
+	widget.BaseWidget
+	container *fyne.Container
+	list      *widget.List
+	status    *widget.Label
+	lines     []consoleLine
+}
+
+// consoleLine 表示控制台中的一行
+type consoleLine struct {
+	text  string
+	level string
+}
+
+// NewConsolePanel 创建一个新的控制台面板
+func NewConsolePanel() *ConsolePanel {
+	panel := &ConsolePanel{}
+	panel.ExtendBaseWidget(panel)
+	panel.container = panel.buildUI()
+	return panel
+}
+
+// CreateRenderer 创建渲染器
+func (c *ConsolePanel) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(c.container)
+}
+
+// buildUI 构建UI
+func (c *ConsolePanel) buildUI() *fyne.Container {
+	c.list = widget.NewList(
+		func() int {
+			return len(c.lines)
+		},
+		func() fyne.CanvasObject {
+			label := widget.NewLabel("console output")
+			label.TextStyle = fyne.TextStyle{Monospace: true}
+			return label
+		},
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			if id >= len(c.lines) {
+				return
+			}
+			label := obj.(*widget.Label)
+			line := c.lines[id]
+			label.TextStyle = fyne.TextStyle{Monospace: true, Bold: line.level == "error"}
+			label.SetText(line.text)
+		},
+	)
+
+	c.status = widget.NewLabel("")
+	clearBtn := widget.NewButton("清空", c.Clear)
+
+	header := container.NewHBox(
+		widget.NewLabelWithStyle("🖥 控制台", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		c.status,
+		layout.NewSpacer(),
+		clearBtn,
+	)
+
+	body := container.NewBorder(header, nil, nil, nil, c.list)
+	return container.NewGridWrap(fyne.NewSize(800, 160), body)
+}
+
+// Clear 清空控制台
+func (c *ConsolePanel) Clear() {
+	c.lines = nil
+	c.status.SetText("")
+	c.list.Refresh()
+}
+
+// ShowRun 显示一次脚本运行的输出，运行失败时在输出后追加错误和调用栈
+func (c *ConsolePanel) ShowRun(entries []generator.ConsoleEntry, runErr error) {
+	c.lines = nil
+	for _, entry := range entries {
+		c.appendText(entry.Level, fmt.Sprintf("[%s] %-5s %s", entry.Time.Format("15:04:05.000"), strings.ToUpper(entry.Level), entry.Message))
+	}
+
+	if runErr == nil {
+		c.status.SetText(fmt.Sprintf("运行成功，%d 条输出", len(entries)))
+	} else {
+		c.status.SetText("运行失败")
+		c.appendText("error", runErr.Error())
+		if scriptErr, ok := runErr.(*generator.ScriptError); ok && scriptErr.Stack != "" {
+			c.appendText("error", scriptErr.Stack)
+		}
+	}
+
+	c.list.Refresh()
+	if len(c.lines) > 0 {
+		c.list.ScrollToBottom()
+	}
+}
+
+// ShowStderr 显示外部进程（如生成器插件）一次运行的标准错误输出和错误
+func (c *ConsolePanel) ShowStderr(stderr string, runErr error) {
+	c.lines = nil
+	if stderr = strings.TrimRight(stderr, "\n"); stderr != "" {
+		c.appendText("log", stderr)
+	}
+
+	if runErr == nil {
+		c.status.SetText("运行成功")
+	} else {
+		c.status.SetText("运行失败")
+		c.appendText("error", runErr.Error())
+	}
+
+	c.list.Refresh()
+	if len(c.lines) > 0 {
+		c.list.ScrollToBottom()
+	}
+}
+
+// ShowOutput 在当前输出之后追加脚本生成的结果
+func (c *ConsolePanel) ShowOutput(output string) {
+	c.appendText("output", "---------- 输出结果 ----------")
+	c.appendText("output", strings.TrimRight(output, "\n"))
+	c.list.Refresh()
+}
+
+// appendText 追加文本，多行文本拆分为多行显示
+func (c *ConsolePanel) appendText(level, text string) {
+	for _, line := range strings.Split(text, "\n") {
+		c.lines = append(c.lines, consoleLine{text: line, level: level})
+	}
+}
+
+// HighlightLine 将编辑器光标移动到指定行并选中该行，用于标记出错位置
+// line 从1开始，超出范围时不做任何处理
+func HighlightLine(entry *widget.Entry, line int) {
+	lines := strings.Split(entry.Text, "\n")
+	if line < 1 || line > len(lines) {
+		return
+	}
+
+	if canvas := fyne.CurrentApp().Driver().CanvasForObject(entry); canvas != nil {
+		canvas.Focus(entry)
+	}
+
+	// 先定位到行首，再模拟 Shift+End 选中整行
+	entry.CursorRow = line - 1
+	entry.CursorColumn = 0
+	entry.Refresh()
+	entry.KeyDown(&fyne.KeyEvent{Name: desktop.KeyShiftLeft})
+	entry.TypedKey(&fyne.KeyEvent{Name: fyne.KeyEnd})
+	entry.KeyUp(&fyne.KeyEvent{Name: desktop.KeyShiftLeft})
+}
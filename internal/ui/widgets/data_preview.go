@@ -0,0 +1,276 @@
+package widgets
+
+import (
+	"encoding/hex"
+	"fmt"
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+	"go-DBmodeler/internal/db/connector"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// noFilterOption 是筛选列选择器中不筛选的选项
+const noFilterOption = "不筛选"
+
+// maxPreviewCellLength 是单元格中显示的最大字符数，更长的值截断显示
+const maxPreviewCellLength = 200
+
+// DataPreview 表示表数据预览，分页显示表中的数据
+// 数据由 OnLoad 在后台读取，读取完成后调用 SetPage 显示
+type DataPreview struct {
+	widget.BaseWidget
+	container *fyne.Container
+	table     *widget.Table
+	status    *widget.Label
+
+	limitEntry   *widget.Entry
+	filterSelect *widget.Select
+	filterEntry  *widget.Entry
+	loadBtn      *widget.Button
+	prevBtn      *widget.Button
+	nextBtn      *widget.Button
+
+	// OnLoad 在需要读取一页数据时调用，由调用方补充排序的列
+	OnLoad func(request connector.PreviewRequest)
+
+	page   *connector.PreviewPage
+	offset int
+}
+
+// NewDataPreview 创建一个新的数据预览
+func NewDataPreview() *DataPreview {
+	preview := &DataPreview{}
+	preview.ExtendBaseWidget(preview)
+	preview.container = preview.buildUI()
+	preview.Reset(false)
+	return preview
+}
+
+// CreateRenderer 创建渲染器
+func (d *DataPreview) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(d.container)
+}
+
+// buildUI 构建UI
+func (d *DataPreview) buildUI() *fyne.Container {
+	d.table = widget.NewTableWithHeaders(
+		func() (int, int) {
+			if d.page == nil {
+				return 0, 0
+			}
+			return len(d.page.Rows), len(d.page.Columns)
+		},
+		func() fyne.CanvasObject {
+			label := widget.NewLabel("preview cell")
+			label.Truncation = fyne.TextTruncateEllipsis
+			return label
+		},
+		func(id widget.TableCellID, obj fyne.CanvasObject) {
+			label := obj.(*widget.Label)
+			if d.page == nil || id.Row >= len(d.page.Rows) || id.Col >= len(d.page.Columns) {
+				label.SetText("")
+				return
+			}
+			value := d.page.Rows[id.Row][id.Col]
+			// NULL 使用斜体和浅色显示，与字符串 "NULL" 区分
+			if value == nil {
+				label.TextStyle = fyne.TextStyle{Italic: true}
+				label.Importance = widget.LowImportance
+			} else {
+				label.TextStyle = fyne.TextStyle{Monospace: isBinaryValue(value)}
+				label.Importance = widget.MediumImportance
+			}
+			label.SetText(formatPreviewValue(value))
+		},
+	)
+	d.table.CreateHeader = func() fyne.CanvasObject {
+		return widget.NewLabelWithStyle("header", fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+	}
+	d.table.UpdateHeader = func(id widget.TableCellID, obj fyne.CanvasObject) {
+		label := obj.(*widget.Label)
+		switch {
+		case d.page == nil:
+			label.SetText("")
+		case id.Row < 0 && id.Col >= 0 && id.Col < len(d.page.Columns):
+			// 列标题显示列名和类型
+			text := d.page.Columns[id.Col]
+			if d.page.Types[id.Col] != "" {
+				text += " (" + strings.ToLower(d.page.Types[id.Col]) + ")"
+			}
+			label.SetText(text)
+		case id.Col < 0 && id.Row >= 0:
+			// 行标题显示行号
+			label.SetText(strconv.Itoa(d.offset + id.Row + 1))
+		default:
+			label.SetText("")
+		}
+	}
+
+	d.limitEntry = widget.NewEntry()
+	d.limitEntry.SetText(strconv.Itoa(connector.DefaultPreviewLimit))
+	d.filterSelect = widget.NewSelect([]string{noFilterOption}, nil)
+	d.filterEntry = widget.NewEntry()
+	d.filterEntry.SetPlaceHolder("包含的内容")
+	d.filterEntry.OnSubmitted = func(string) { d.load(0) }
+	d.loadBtn = widget.NewButton("查询", func() { d.load(0) })
+	d.prevBtn = widget.NewButton("上一页", func() { d.load(d.offset - d.limit()) })
+	d.nextBtn = widget.NewButton("下一页", func() { d.load(d.offset + d.limit()) })
+	d.status = widget.NewLabel("")
+
+	toolbar := container.NewHBox(
+		widget.NewLabel("每页:"),
+		container.NewGridWrap(fyne.NewSize(80, d.limitEntry.MinSize().Height), d.limitEntry),
+		widget.NewLabel("筛选:"),
+		d.filterSelect,
+		container.NewGridWrap(fyne.NewSize(200, d.filterEntry.MinSize().Height), d.filterEntry),
+		d.loadBtn,
+		layout.NewSpacer(),
+		d.status,
+		d.prevBtn,
+		d.nextBtn,
+	)
+
+	return container.NewBorder(toolbar, nil, nil, nil, d.table)
+}
+
+// Reset 切换到另一个表时清空数据和筛选条件，ready 为 false 时不能读取数据
+func (d *DataPreview) Reset(ready bool) {
+	d.page = nil
+	d.offset = 0
+	d.filterSelect.Options = []string{noFilterOption}
+	d.filterSelect.SetSelected(noFilterOption)
+	d.filterEntry.SetText("")
+	d.status.SetText("")
+	d.prevBtn.Disable()
+	d.nextBtn.Disable()
+	if ready {
+		d.loadBtn.Enable()
+	} else {
+		d.loadBtn.Disable()
+	}
+	d.table.Refresh()
+}
+
+// Loaded 判断是否已经显示了数据
+func (d *DataPreview) Loaded() bool {
+	return d.page != nil
+}
+
+// Load 读取第一页数据，不能读取数据时不做任何事
+func (d *DataPreview) Load() {
+	if !d.loadBtn.Disabled() {
+		d.load(0)
+	}
+}
+
+// SetPage 显示读取到的一页数据，offset 为这一页跳过的行数
+func (d *DataPreview) SetPage(page *connector.PreviewPage, offset int) {
+	d.page = page
+	d.offset = offset
+
+	// 读取第一页后才知道可以筛选的列
+	if len(d.filterSelect.Options) == 1 {
+		d.filterSelect.Options = append([]string{noFilterOption}, page.Columns...)
+		d.filterSelect.Refresh()
+	}
+
+	// 按列名和第一页的内容估算列宽
+	for col, name := range page.Columns {
+		width := utf8.RuneCountInString(name) + utf8.RuneCountInString(page.Types[col]) + 3
+		for _, row := range page.Rows {
+			if length := utf8.RuneCountInString(formatPreviewValue(row[col])); length > width {
+				width = length
+			}
+		}
+		if width > 40 {
+			width = 40
+		}
+		d.table.SetColumnWidth(col, float32(width)*8+16)
+	}
+
+	if len(page.Rows) == 0 {
+		d.status.SetText("没有数据")
+	} else {
+		d.status.SetText(fmt.Sprintf("第 %d - %d 行", offset+1, offset+len(page.Rows)))
+	}
+	if offset > 0 {
+		d.prevBtn.Enable()
+	} else {
+		d.prevBtn.Disable()
+	}
+	if page.HasMore {
+		d.nextBtn.Enable()
+	} else {
+		d.nextBtn.Disable()
+	}
+	d.table.ScrollToTop()
+	d.table.Refresh()
+}
+
+// limit 返回每页行数，输入无效时使用默认值
+func (d *DataPreview) limit() int {
+	limit, err := strconv.Atoi(strings.TrimSpace(d.limitEntry.Text))
+	if err != nil || limit <= 0 {
+		return connector.DefaultPreviewLimit
+	}
+	return limit
+}
+
+// load 请求读取从 offset 开始的一页数据
+func (d *DataPreview) load(offset int) {
+	if d.OnLoad == nil {
+		return
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	request := connector.PreviewRequest{
+		Limit:  d.limit(),
+		Offset: offset,
+	}
+	if column := d.filterSelect.Selected; column != "" && column != noFilterOption {
+		request.FilterColumn = column
+		request.FilterValue = d.filterEntry.Text
+	}
+	d.OnLoad(request)
+}
+
+// isBinaryValue 判断预览的值是否为二进制数据
+func isBinaryValue(value interface{}) bool {
+	_, ok := value.([]byte)
+	return ok
+}
+
+// formatPreviewValue 返回预览的值在单元格中显示的文本
+// NULL 显示为 NULL，二进制数据显示为十六进制，过长的值截断显示
+func formatPreviewValue(value interface{}) string {
+	var text string
+	switch v := value.(type) {
+	case nil:
+		return "NULL"
+	case []byte:
+		// 只转换会显示出来的部分
+		if len(v) > maxPreviewCellLength/2 {
+			return "0x" + strings.ToUpper(hex.EncodeToString(v[:maxPreviewCellLength/2])) + "…"
+		}
+		text = "0x" + strings.ToUpper(hex.EncodeToString(v))
+	case time.Time:
+		text = v.Format("2006-01-02 15:04:05.999999999 -07:00")
+	case string:
+		text = v
+	default:
+		text = fmt.Sprint(v)
+	}
+
+	// 单元格只显示一行
+	text = strings.ReplaceAll(strings.ReplaceAll(text, "\r", " "), "\n", " ")
+	if utf8.RuneCountInString(text) > maxPreviewCellLength {
+		text = string([]rune(text)[:maxPreviewCellLength]) + "…"
+	}
+	return text
+}
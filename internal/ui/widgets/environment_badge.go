@@ -0,0 +1,77 @@
+package widgets
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"go-DBmodeler/internal/config"
+	"image/color"
+)
+
+// 各环境的显示颜色
+var environmentColors = map[string]color.Color{
+	config.EnvironmentDev:  color.NRGBA{R: 0x2e, G: 0xa0, B: 0x43, A: 0xff}, // 绿色
+	config.EnvironmentTest: color.NRGBA{R: 0xd9, G: 0x8e, B: 0x04, A: 0xff}, // 橙色
+	config.EnvironmentProd: color.NRGBA{R: 0xd7, G: 0x3a, B: 0x49, A: 0xff}, // 红色
+}
+
+// 各环境的显示名称
+var environmentLabels = map[string]string{
+	config.EnvironmentDev:  "开发",
+	config.EnvironmentTest: "测试",
+	config.EnvironmentProd: "生产",
+}
+
+// EnvironmentColor 返回环境的显示颜色，未标记时返回透明
+func EnvironmentColor(environment string) color.Color {
+	if c, ok := environmentColors[environment]; ok {
+		return c
+	}
+	return color.Transparent
+}
+
+// EnvironmentLabel 返回环境的显示名称，未标记时返回空字符串
+func EnvironmentLabel(environment string) string {
+	if label, ok := environmentLabels[environment]; ok {
+		return label
+	}
+	return environment
+}
+
+// EnvironmentBadge 表示带颜色的环境标记
+type EnvironmentBadge struct {
+	*fyne.Container
+	background *canvas.Rectangle
+	text       *canvas.Text
+}
+
+// NewEnvironmentBadge 创建一个新的环境标记
+func NewEnvironmentBadge(environment string) *EnvironmentBadge {
+	background := canvas.NewRectangle(color.Transparent)
+	background.CornerRadius = 4
+	text := canvas.NewText("", color.White)
+	text.TextStyle = fyne.TextStyle{Bold: true}
+	text.TextSize = theme.CaptionTextSize()
+	text.Alignment = fyne.TextAlignCenter
+
+	badge := &EnvironmentBadge{
+		Container:  container.NewStack(background, container.NewPadded(text)),
+		background: background,
+		text:       text,
+	}
+	badge.SetEnvironment(environment)
+	return badge
+}
+
+// SetEnvironment 更新显示的环境，未标记时隐藏
+func (b *EnvironmentBadge) SetEnvironment(environment string) {
+	if environment == "" {
+		b.Hide()
+		return
+	}
+	b.background.FillColor = EnvironmentColor(environment)
+	b.text.Text = EnvironmentLabel(environment)
+	b.Show()
+	b.Refresh()
+}
@@ -7,6 +7,24 @@ import (
 	"go-DBmodeler/internal/db/connector"
 )
 
+// objectKindLabels 是对象类型在界面中显示的名称
+var objectKindLabels = map[connector.ObjectKind]string{
+	connector.KindTable:            "表",
+	connector.KindView:             "视图",
+	connector.KindMaterializedView: "物化视图",
+	connector.KindForeignTable:     "外部表",
+	connector.KindPartitionedTable: "分区表",
+	connector.KindQuery:            "查询",
+}
+
+// ObjectKindLabel 返回对象类型在界面中显示的名称
+func ObjectKindLabel(kind connector.ObjectKind) string {
+	if label, ok := objectKindLabels[kind]; ok {
+		return label
+	}
+	return string(kind)
+}
+
 // TableView 表示表结构视图
 type TableView struct {
 	widget.BaseWidget
@@ -20,7 +38,7 @@ func NewTableView(metadata *connector.TableMetadata) *TableView {
 		metadata: metadata,
 	}
 	view.ExtendBaseWidget(view)
-	view.container = view.buildUI()
+	view.container = container.NewStack(view.buildUI())
 	return view
 }
 
@@ -68,11 +86,37 @@ func (v *TableView) buildUI() *fyne.Container {
 	// 创建表格
 	table := container.NewVBox(append([]fyne.CanvasObject{header}, rows...)...)
 
+	// 查询显示参数，数据库无法确定类型的参数显示为未知
+	for _, param := range v.metadata.Params {
+		paramType := param.Type
+		if paramType == "" {
+			paramType = "未知"
+		}
+		table.Add(widget.NewLabel("参数 " + param.Name + ": " + paramType))
+	}
+
+	// 视图和查询显示定义，默认折叠
+	if v.metadata.Definition != "" {
+		definition := widget.NewLabelWithStyle(v.metadata.Definition, fyne.TextAlignLeading, fyne.TextStyle{Monospace: true})
+		definition.Wrapping = fyne.TextWrapWord
+		definitionTitle := "视图定义"
+		if v.metadata.Kind == connector.KindQuery {
+			definitionTitle = "查询语句"
+		}
+		table.Add(widget.NewAccordion(widget.NewAccordionItem(definitionTitle, definition)))
+	}
+
 	// 创建滚动容器
 	scroll := container.NewScroll(table)
 
+	// 表名后标注对象类型
+	title := "表名: " + v.metadata.Name
+	if v.metadata.Kind != "" {
+		title += "（" + ObjectKindLabel(v.metadata.Kind) + "）"
+	}
+
 	return container.NewBorder(
-		widget.NewLabel("表名: "+v.metadata.Name),
+		widget.NewLabel(title),
 		nil,
 		nil,
 		nil,
@@ -83,12 +127,10 @@ func (v *TableView) buildUI() *fyne.Container {
 // SetMetadata 设置表元数据
 func (v *TableView) SetMetadata(metadata *connector.TableMetadata) {
 	v.metadata = metadata
-	// 重新构建UI
-	newContainer := v.buildUI()
-
-	// 更新容器引用
-	v.container = newContainer
+	// 重新构建UI，替换容器中的内容，渲染器始终使用同一个容器
+	v.container.Objects = []fyne.CanvasObject{v.buildUI()}
 
 	// 强制刷新整个widget
+	v.container.Refresh()
 	v.Refresh()
 }
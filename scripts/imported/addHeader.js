@@ -1,13 +1,15 @@
 // Header comment script
+// @param note string "Do not edit this file manually" Header note
+// @param showDate bool true Show generation time
 // input is already parsed JSON object
 
 // Generate header comment
+const dateLine = params.showDate ? ` * Generated at: ${new Date().toLocaleString()}\n` : '';
 const header = `/**
  * ${input.tableName} model
- * Generated at: ${new Date().toLocaleString()}
- * Table: ${input.tableName}
+${dateLine} * Table: ${input.tableName}
  * Field count: ${input.fields.length}
- * Do not edit this file manually
+ * ${params.note}
  */
 
 `;
@@ -1,4 +1,6 @@
 // Type import script
+// @param importPath string ./types Module to import types from
+// @param commented bool true Emit imports as comments
 // input is already parsed JSON object
 
 // Get current generated code
@@ -17,9 +19,10 @@ for (const field of input.fields) {
 
 // If there are types to import, add import statements
 if (importTypes.size > 0) {
+    const prefix = params.commented ? '// ' : '';
     let imports = '// Auto-generated import statements\n';
     for (const type of importTypes) {
-        imports += `// import { ${type} } from './types'\n`;
+        imports += `${prefix}import { ${type} } from '${params.importPath}'\n`;
     }
     imports += '\n';
     